@@ -0,0 +1,70 @@
+package main
+
+import (
+    "encoding/csv"
+    "io"
+    "log"
+    "os"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+// Импорт очков достижений для армори из CSV-выгрузки Achievement.dbc (id,name,points):
+// go run ./cmd/import-achievements achievements.csv
+func main() {
+    if len(os.Args) < 2 {
+        log.Fatal("Usage: import-achievements <file.csv>")
+    }
+    
+    file, err := os.Open(os.Args[1])
+    if err != nil {
+        log.Fatal("Failed to open file:", err)
+    }
+    defer file.Close()
+    
+    var achievements []database.Achievement
+    reader := csv.NewReader(file)
+    for line := 1; ; line++ {
+        record, err := reader.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            log.Fatalf("Line %d: %v", line, err)
+        }
+        if len(record) < 3 {
+            log.Fatalf("Line %d: expected id,name,points", line)
+        }
+        
+        id, err := strconv.Atoi(record[0])
+        if err != nil {
+            if line == 1 {
+                continue // заголовок
+            }
+            log.Fatalf("Line %d: invalid id %q", line, record[0])
+        }
+        points, err := strconv.Atoi(record[2])
+        if err != nil {
+            log.Fatalf("Line %d: invalid points %q", line, record[2])
+        }
+        achievements = append(achievements, database.Achievement{ID: id, Name: record[1], Points: points})
+    }
+    
+    // Загрузка конфигурации
+    if err := config.Load(); err != nil {
+        log.Fatal("Failed to load config:", err)
+    }
+    
+    // Подключение к базе данных
+    if err := database.Connect(); err != nil {
+        log.Fatal("Failed to connect to database:", err)
+    }
+    defer database.Close()
+    
+    if err := database.ImportAchievements(achievements); err != nil {
+        log.Fatal("Import failed:", err)
+    }
+    
+    log.Printf("✅ Imported %d achievements", len(achievements))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+    "log"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+func main() {
+    // Загрузка конфигурации
+    if err := config.Load(); err != nil {
+        log.Fatal("Failed to load config:", err)
+    }
+    
+    // Подключение к базе данных
+    if err := database.Connect(); err != nil {
+        log.Fatal("Failed to connect to database:", err)
+    }
+    defer database.Close()
+    
+    if err := database.Migrate(); err != nil {
+        log.Fatal("Migration failed:", err)
+    }
+    
+    log.Println("✅ Database is up to date")
+}
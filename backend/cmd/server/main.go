@@ -1,13 +1,17 @@
 package main
 
 import (
+    "context"
     "log"
+    "net"
     "net/http"
+    "strings"
     "time"
     "wow-registration/internal/config"
     "wow-registration/internal/database"
     "wow-registration/internal/handlers"
-    "wow-registration/internal/middleware"
+    mw "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
     "github.com/labstack/echo/v4"
     "github.com/labstack/echo/v4/middleware"
 )
@@ -22,18 +26,41 @@ func main() {
     if err := database.Connect(); err != nil {
         log.Fatal("Failed to connect to database:", err)
     }
-    defer database.DB.Close()
+    defer database.Close()
+    
+    // Фоновые задачи
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    
+    // Каналы уведомлений поднимаются первыми, чтобы задачи ниже могли ими пользоваться
+    services.StartNotifiers(ctx)
+    if config.AppConfig.Monitoring.EnableHealthChecks {
+        go services.StartRealmProber(ctx)
+    }
+    go services.StartStatsSampler(ctx)
+    go services.StartLadderUpdater(ctx)
+    go services.StartCharacterToolSweeper(ctx)
+    go services.StartBonusDelivery(ctx)
+    go services.StartVoteRewardDelivery(ctx)
+    go services.StartReferralChecker(ctx)
+    go services.StartShopDelivery(ctx)
+    go services.StartMaintenanceWatcher(ctx)
+    go services.StartWebhookDelivery(ctx)
+    go services.StartAuditRetention(ctx)
     
     // Создание Echo инстанса
     e := echo.New()
+    e.IPExtractor = ipExtractor(config.AppConfig.Security.TrustedProxies)
     
     // Middleware
+    e.Use(middleware.RequestID())
     e.Use(middleware.Logger())
     e.Use(middleware.Recover())
     e.Use(middleware.Gzip())
     e.Use(middleware.CORS())
     e.Use(middleware.Secure())
     e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(100)))
+    e.Use(mw.LoadSession)
     
     // Статические файлы
     e.Static("/static", "./frontend/static")
@@ -50,9 +77,57 @@ func main() {
         api.POST("/register", handlers.RegisterHandler)
         api.POST("/validate", handlers.RegisterHTMXHandler)
         api.POST("/login", handlers.LoginHandler)
+        api.POST("/logout", handlers.LogoutHandler)
         api.POST("/password/reset", handlers.ResetPasswordHandler)
+        api.POST("/password/reset/complete", handlers.PasswordResetCompleteHandler)
         api.GET("/status", handlers.StatusHandler)
         api.GET("/stats/realtime", handlers.RealTimeStatsHandler)
+        api.GET("/stats/history", handlers.StatsHistoryHandler)
+        api.GET("/realms", handlers.RealmsHandler)
+        api.GET("/realms/:id/status", handlers.RealmStatusHandler)
+        api.GET("/realms/:id/players", handlers.OnlinePlayersAPIHandler)
+        api.GET("/players", handlers.OnlinePlayersAPIHandler)
+        api.GET("/armory/:realm/:name", handlers.ArmoryAPIHandler)
+        api.GET("/guilds", handlers.GuildsAPIHandler)
+        api.GET("/realms/:id/guilds", handlers.GuildsAPIHandler)
+        api.GET("/guild/:realm/:name", handlers.GuildAPIHandler)
+        api.GET("/pvp/arena", handlers.ArenaLadderAPIHandler)
+        api.GET("/pvp/honor", handlers.HonorLadderAPIHandler)
+        api.GET("/arena/:realm/team/:id", handlers.ArenaTeamAPIHandler)
+        api.GET("/leaderboards", handlers.LeaderboardsAPIHandler)
+        api.GET("/leaderboards/:board", handlers.LeaderboardAPIHandler)
+        api.GET("/account", handlers.AccountAPIHandler, mw.RequireAuth)
+        api.POST("/account/password", handlers.ChangePasswordHandler, mw.RequireAuth)
+        api.POST("/account/email", handlers.ChangeEmailHandler, mw.RequireAuth)
+        api.GET("/character-tools", handlers.CharacterToolsAPIHandler)
+        api.POST("/account/character-tool", handlers.CharacterToolHandler, mw.RequireAuth)
+        api.GET("/account/vote", handlers.VoteStatusAPIHandler, mw.RequireAuth)
+        api.GET("/account/referrals", handlers.ReferralsAPIHandler, mw.RequireAuth)
+        api.GET("/account/points", handlers.PointsHistoryAPIHandler, mw.RequireAuth)
+        api.POST("/admin/points", handlers.AdminPointsHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/shop/products", handlers.ShopProductsAPIHandler)
+        api.GET("/account/shop/orders", handlers.ShopOrdersAPIHandler, mw.RequireAuth)
+        api.POST("/account/shop/buy", handlers.ShopPurchaseHandler, mw.RequireAuth)
+        api.POST("/admin/shop/products", handlers.AdminShopSaveHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/accounts", handlers.AdminAccountsAPIHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/accounts/:id", handlers.AdminAccountAPIHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/accounts/:id/:action", handlers.AdminAccountActionHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/audit", handlers.AdminAuditAPIHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/audit/export", handlers.AdminAuditExportHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/audit/verify", handlers.AdminAuditVerifyHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/bans", handlers.AdminBansAPIHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/bans/:kind", handlers.AdminBanHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/bans/:kind/lift", handlers.AdminUnbanHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/webhooks", handlers.AdminWebhookSaveHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/webhooks/:id/delete", handlers.AdminWebhookDeleteHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/webhooks/:id/secret", handlers.AdminWebhookSecretHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/admin/webhooks/deliveries", handlers.AdminWebhookDeliveriesAPIHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.POST("/admin/webhooks/deliveries/:id/redeliver", handlers.AdminWebhookRedeliverHandler, mw.RequireAuth, mw.RequireAdmin)
+        api.GET("/account/donations", handlers.DonationsAPIHandler, mw.RequireAuth)
+        api.POST("/donations/webhook/:provider", handlers.DonationWebhookHandler)
+        api.Match([]string{http.MethodGet, http.MethodPost}, "/vote/postback/:site", handlers.VotePostbackHandler)
+        api.POST("/account/armory/privacy", handlers.ArmoryPrivacyHandler, mw.RequireAuth)
+        api.POST("/account/guild/settings", handlers.GuildSettingsHandler, mw.RequireAuth)
     }
     
     // Web роуты
@@ -61,6 +136,45 @@ func main() {
     e.GET("/status", handlers.StatusPageHandler)
     e.GET("/rules", handlers.RulesPageHandler)
     e.GET("/players", handlers.OnlinePlayersHandler)
+    e.GET("/realm/:id/players", handlers.RealmPlayersPageHandler)
+    e.GET("/armory/:realm/:name", handlers.ArmoryHandler)
+    e.GET("/guilds", handlers.GuildsPageHandler)
+    e.GET("/guild/:realm/:name", handlers.GuildHandler)
+    e.GET("/pvp", handlers.PvPPageHandler)
+    e.GET("/arena/:realm/team/:id", handlers.ArenaTeamHandler)
+    e.GET("/leaderboards", handlers.LeaderboardsPageHandler)
+    e.GET("/login", handlers.LoginPageHandler)
+    e.GET("/account/email/confirm", handlers.EmailConfirmHandler)
+    e.GET("/account/email/verify", handlers.EmailVerifyHandler)
+    e.GET("/password/reset", handlers.PasswordResetPageHandler)
+    
+    // Личный кабинет
+    account := e.Group("/account", mw.RequireAuth)
+    {
+        account.GET("", handlers.AccountHandler)
+        account.GET("/armory", handlers.AccountArmoryHandler)
+        account.GET("/guilds", handlers.AccountGuildsHandler)
+        account.GET("/tools", handlers.AccountToolsHandler)
+        account.GET("/vote", handlers.AccountVoteHandler)
+        account.GET("/vote/:site", handlers.VoteRedirectHandler)
+        account.GET("/referrals", handlers.AccountReferralsHandler)
+        account.GET("/points", handlers.AccountPointsHandler)
+        account.GET("/shop", handlers.AccountShopHandler)
+        account.GET("/donations", handlers.AccountDonationsHandler)
+        account.GET("/donate/:provider", handlers.DonationRedirectHandler)
+    }
+    
+    // Администрирование
+    admin := e.Group("/admin", mw.RequireAuth, mw.RequireAdmin)
+    {
+        admin.GET("", handlers.AdminIndexHandler)
+        admin.GET("/accounts", handlers.AdminAccountsHandler)
+        admin.GET("/accounts/:id", handlers.AdminAccountHandler)
+        admin.GET("/bans", handlers.AdminBansHandler)
+        admin.GET("/audit", handlers.AdminAuditHandler)
+        admin.GET("/shop", handlers.AdminShopHandler)
+        admin.GET("/webhooks", handlers.AdminWebhooksHandler)
+    }
     
     // HTMX эндпоинты
     htmx := e.Group("/htmx")
@@ -68,7 +182,14 @@ func main() {
         htmx.POST("/validate/username", handlers.ValidateUsernameHandler)
         htmx.POST("/validate/email", handlers.ValidateEmailHandler)
         htmx.GET("/online-players", handlers.OnlinePlayersHTMXHandler)
+        htmx.GET("/players-table", handlers.PlayersTableHTMXHandler)
+        htmx.GET("/guilds-table", handlers.GuildsTableHTMXHandler)
+        htmx.GET("/arena-ladder", handlers.ArenaLadderHTMXHandler)
+        htmx.GET("/leaderboard", handlers.LeaderboardHTMXHandler)
+        htmx.GET("/honor-ladder", handlers.HonorLadderHTMXHandler)
         htmx.GET("/server-stats", handlers.ServerStatsHTMXHandler)
+        htmx.GET("/realm-status", handlers.RealmStatusHTMXHandler)
+        htmx.GET("/stats-charts", handlers.StatsChartsHTMXHandler)
     }
     
     // Запуск сервера
@@ -87,3 +208,35 @@ func main() {
         log.Fatal("Failed to start server:", err)
     }
 }
+
+// ipExtractor — откуда брать IP клиента. По нему считаются кулдауны голосования
+// и история входов, поэтому X-Forwarded-For принимается только от прокси из
+// TRUSTED_PROXIES; без них берется адрес TCP-соединения, X-Real-IP не читается никогда
+func ipExtractor(proxies []string) echo.IPExtractor {
+    var options []echo.TrustOption
+    for _, proxy := range proxies {
+        proxy = strings.TrimSpace(proxy)
+        if proxy == "" {
+            continue
+        }
+        _, network, err := net.ParseCIDR(proxy)
+        if err != nil {
+            // Одиночный адрес — сеть из одного хоста
+            ip := net.ParseIP(proxy)
+            if ip == nil {
+                log.Fatalf("TRUSTED_PROXIES: invalid address %q", proxy)
+            }
+            if ip4 := ip.To4(); ip4 != nil {
+                ip = ip4
+            }
+            network = &net.IPNet{IP: ip, Mask: net.CIDRMask(8*len(ip), 8*len(ip))}
+        }
+        options = append(options, echo.TrustIPRange(network))
+    }
+    if len(options) == 0 {
+        return echo.ExtractIPDirect()
+    }
+    
+    options = append(options, echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false))
+    return echo.ExtractIPFromXFFHeader(options...)
+}
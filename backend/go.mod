@@ -3,11 +3,27 @@ module wow-registration
 go 1.21
 
 require (
-    github.com/go-sql-driver/mysql v1.7.1
-    github.com/golang-jwt/jwt/v5 v5.0.0
-    github.com/google/uuid v1.3.0
-    github.com/joho/godotenv v1.5.1
-    github.com/labstack/echo/v4 v4.11.1
-    github.com/redis/go-redis/v9 v9.1.0
-    golang.org/x/crypto v0.12.0
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/golang-jwt/jwt/v5 v5.0.0
+	github.com/google/uuid v1.3.0
+	github.com/joho/godotenv v1.5.1
+	github.com/labstack/echo/v4 v4.11.1
+	github.com/redis/go-redis/v9 v9.1.0
+	golang.org/x/crypto v0.12.0
+	golang.org/x/sync v0.3.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
+	github.com/labstack/gommon v0.4.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/net v0.12.0 // indirect
+	golang.org/x/sys v0.11.0 // indirect
+	golang.org/x/text v0.12.0 // indirect
+	golang.org/x/time v0.3.0 // indirect
 )
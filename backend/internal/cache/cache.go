@@ -0,0 +1,166 @@
+package cache
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "log"
+    "sync"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "github.com/redis/go-redis/v9"
+    "golang.org/x/sync/singleflight"
+)
+
+// Сколько устаревшее значение еще можно отдавать, пока идет фоновое обновление
+const staleFactor = 3
+
+const refreshTimeout = 10 * time.Second
+
+// Верхняя граница размера in-process кэша, после которой чистим просроченное
+const memSweepThreshold = 1024
+
+type entry struct {
+    Value     json.RawMessage `json:"v"`
+    FreshTill time.Time       `json:"f"`
+}
+
+var (
+    group singleflight.Group
+    
+    memMu sync.RWMutex
+    mem   = make(map[string]memItem)
+)
+
+type memItem struct {
+    data      []byte
+    expiresAt time.Time
+}
+
+// GetOrLoad отдает значение из кэша. Свежее значение возвращается сразу,
+// устаревшее — тоже сразу, но с обновлением в фоне; при промахе loader
+// вызывается один раз на ключ, даже если запрос пришел от сотни клиентов.
+func GetOrLoad[T any](ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+    if !config.AppConfig.Cache.Enabled || ttl <= 0 {
+        return loader(ctx)
+    }
+    
+    var zero T
+    if e, ok := read(ctx, key); ok {
+        var value T
+        if err := json.Unmarshal(e.Value, &value); err == nil {
+            if time.Now().After(e.FreshTill) {
+                refreshInBackground(key, ttl, loader)
+            }
+            return value, nil
+        }
+    }
+    
+    // Загрузка не привязана к запросу: его отмена не должна ронять остальных ожидающих
+    result, err, _ := group.Do(key, func() (interface{}, error) {
+        loadCtx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+        defer cancel()
+        return load(loadCtx, key, ttl, loader)
+    })
+    if err != nil {
+        return zero, err
+    }
+    return result.(T), nil
+}
+
+// Invalidate удаляет ключ из обоих хранилищ
+func Invalidate(ctx context.Context, key string) {
+    memMu.Lock()
+    delete(mem, key)
+    memMu.Unlock()
+    
+    if useRedis() {
+        database.Redis.Del(ctx, cacheKey(key))
+    }
+}
+
+func refreshInBackground[T any](key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) {
+    // DoChan не запускает второй loader, если обновление уже идет
+    group.DoChan(key, func() (interface{}, error) {
+        ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+        defer cancel()
+        
+        value, err := load(ctx, key, ttl, loader)
+        if err != nil {
+            log.Printf("cache: background refresh of %s failed: %v", key, err)
+        }
+        return value, err
+    })
+}
+
+func load[T any](ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+    value, err := loader(ctx)
+    if err != nil {
+        return value, err
+    }
+    
+    raw, err := json.Marshal(value)
+    if err != nil {
+        return value, err
+    }
+    
+    write(ctx, key, entry{Value: raw, FreshTill: time.Now().Add(ttl)}, ttl*staleFactor)
+    return value, nil
+}
+
+func useRedis() bool {
+    return config.AppConfig.Cache.Type == "redis" && database.Redis != nil
+}
+
+func cacheKey(key string) string {
+    return "cache:" + key
+}
+
+func read(ctx context.Context, key string) (entry, bool) {
+    var e entry
+    
+    if useRedis() {
+        data, err := database.Redis.Get(ctx, cacheKey(key)).Bytes()
+        if err == nil {
+            return e, json.Unmarshal(data, &e) == nil
+        }
+        if errors.Is(err, redis.Nil) {
+            return e, false
+        }
+        // Redis недоступен — падаем на память процесса
+    }
+    
+    memMu.RLock()
+    item, ok := mem[key]
+    memMu.RUnlock()
+    if !ok || time.Now().After(item.expiresAt) {
+        return e, false
+    }
+    return e, json.Unmarshal(item.data, &e) == nil
+}
+
+func write(ctx context.Context, key string, e entry, ttl time.Duration) {
+    data, err := json.Marshal(e)
+    if err != nil {
+        return
+    }
+    
+    now := time.Now()
+    memMu.Lock()
+    if len(mem) >= memSweepThreshold {
+        for k, item := range mem {
+            if now.After(item.expiresAt) {
+                delete(mem, k)
+            }
+        }
+    }
+    mem[key] = memItem{data: data, expiresAt: now.Add(ttl)}
+    memMu.Unlock()
+    
+    if useRedis() {
+        if err := database.Redis.Set(ctx, cacheKey(key), data, ttl).Err(); err != nil {
+            log.Printf("cache: redis set %s failed: %v", key, err)
+        }
+    }
+}
@@ -0,0 +1,168 @@
+package charts
+
+import (
+    "fmt"
+    "html"
+    "html/template"
+    "math"
+    "strings"
+)
+
+// Простые SVG-графики, которые рендерятся на сервере без JS-библиотек
+
+const (
+    width        = 800
+    height       = 240
+    marginLeft   = 48
+    marginRight  = 12
+    marginTop    = 12
+    marginBottom = 28
+    maxXLabels   = 8
+)
+
+type Series struct {
+    Name   string
+    Color  string
+    Values []float64
+}
+
+type Bar struct {
+    Label string
+    Value float64
+}
+
+func plotWidth() float64  { return width - marginLeft - marginRight }
+func plotHeight() float64 { return height - marginTop - marginBottom }
+
+// niceMax округляет верх шкалы до 1/2/5 × 10^n
+func niceMax(v float64) float64 {
+    if v <= 0 {
+        return 1
+    }
+    exp := math.Pow(10, math.Floor(math.Log10(v)))
+    for _, m := range []float64{1, 2, 5, 10} {
+        if v <= m*exp {
+            return m * exp
+        }
+    }
+    return 10 * exp
+}
+
+func begin(b *strings.Builder, title string) {
+    fmt.Fprintf(b, `<svg viewBox="0 0 %d %d" class="w-full h-auto" role="img" aria-label="%s" xmlns="http://www.w3.org/2000/svg">`,
+        width, height, html.EscapeString(title))
+}
+
+func axes(b *strings.Builder, max float64) {
+    for i := 0; i <= 4; i++ {
+        value := max * float64(i) / 4
+        y := marginTop + plotHeight() - plotHeight()*float64(i)/4
+        fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#374151" stroke-width="1"/>`,
+            marginLeft, y, width-marginRight, y)
+        fmt.Fprintf(b, `<text x="%d" y="%.1f" fill="#9ca3af" font-size="11" text-anchor="end">%s</text>`,
+            marginLeft-6, y+4, formatValue(value))
+    }
+}
+
+func xLabel(b *strings.Builder, x float64, label string) {
+    fmt.Fprintf(b, `<text x="%.1f" y="%d" fill="#9ca3af" font-size="11" text-anchor="middle">%s</text>`,
+        x, height-8, html.EscapeString(label))
+}
+
+func formatValue(v float64) string {
+    if v >= 1000 {
+        return fmt.Sprintf("%.1fk", v/1000)
+    }
+    if v == math.Trunc(v) {
+        return fmt.Sprintf("%.0f", v)
+    }
+    return fmt.Sprintf("%.1f", v)
+}
+
+func empty(title string) template.HTML {
+    var b strings.Builder
+    begin(&b, title)
+    fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#6b7280" font-size="14" text-anchor="middle">No data yet</text></svg>`,
+        width/2, height/2)
+    return template.HTML(b.String())
+}
+
+// Line рисует одну или несколько линий по общим подписям оси X
+func Line(title string, labels []string, series []Series) template.HTML {
+    if len(labels) == 0 {
+        return empty(title)
+    }
+    
+    var max float64
+    for _, s := range series {
+        for _, v := range s.Values {
+            max = math.Max(max, v)
+        }
+    }
+    max = niceMax(max)
+    
+    step := 0.0
+    if len(labels) > 1 {
+        step = plotWidth() / float64(len(labels)-1)
+    }
+    x := func(i int) float64 { return marginLeft + step*float64(i) }
+    y := func(v float64) float64 { return marginTop + plotHeight() - plotHeight()*v/max }
+    
+    var b strings.Builder
+    begin(&b, title)
+    axes(&b, max)
+    
+    labelEvery := int(math.Ceil(float64(len(labels)) / maxXLabels))
+    for i, label := range labels {
+        if i%labelEvery == 0 {
+            xLabel(&b, x(i), label)
+        }
+    }
+    
+    for _, s := range series {
+        points := make([]string, 0, len(s.Values))
+        for i, v := range s.Values {
+            points = append(points, fmt.Sprintf("%.1f,%.1f", x(i), y(v)))
+        }
+        fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"><title>%s</title></polyline>`,
+            html.EscapeString(s.Color), strings.Join(points, " "), html.EscapeString(s.Name))
+    }
+    
+    b.WriteString(`</svg>`)
+    return template.HTML(b.String())
+}
+
+// Bars рисует столбчатую диаграмму
+func Bars(title, color string, bars []Bar) template.HTML {
+    if len(bars) == 0 {
+        return empty(title)
+    }
+    
+    var max float64
+    for _, bar := range bars {
+        max = math.Max(max, bar.Value)
+    }
+    max = niceMax(max)
+    
+    slot := plotWidth() / float64(len(bars))
+    barWidth := slot * 0.7
+    
+    var b strings.Builder
+    begin(&b, title)
+    axes(&b, max)
+    
+    labelEvery := int(math.Ceil(float64(len(bars)) / maxXLabels))
+    for i, bar := range bars {
+        h := plotHeight() * bar.Value / max
+        x := marginLeft + slot*float64(i) + (slot-barWidth)/2
+        fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" rx="2"><title>%s: %s</title></rect>`,
+            x, marginTop+plotHeight()-h, barWidth, h, html.EscapeString(color),
+            html.EscapeString(bar.Label), formatValue(bar.Value))
+        if i%labelEvery == 0 {
+            xLabel(&b, x+barWidth/2, bar.Label)
+        }
+    }
+    
+    b.WriteString(`</svg>`)
+    return template.HTML(b.String())
+}
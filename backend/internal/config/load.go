@@ -28,6 +28,7 @@ func LoadConfig() error {
     cfg.Server.RateLimit, _ = strconv.Atoi(getEnv("RATE_LIMIT", "100"))
     cfg.Server.RateLimitWindow, _ = strconv.Atoi(getEnv("RATE_LIMIT_WINDOW", "60"))
     cfg.Server.RegistrationCooldown, _ = strconv.Atoi(getEnv("REGISTRATION_COOLDOWN", "300"))
+    cfg.Server.SessionLifetime, _ = strconv.Atoi(getEnv("SESSION_LIFETIME", "168"))
     
     // Database
     cfg.Database.Host = getEnv("DB_HOST", "localhost")
@@ -43,6 +44,29 @@ func LoadConfig() error {
     cfg.Database.CharsPassword = getEnv("DB_CHARS_PASSWORD", cfg.Database.Password)
     cfg.Database.CharsName = getEnv("DB_CHARS_NAME", "characters")
     
+    cfg.Database.WorldHost = getEnv("DB_WORLD_HOST", cfg.Database.Host)
+    cfg.Database.WorldPort = getEnv("DB_WORLD_PORT", cfg.Database.Port)
+    cfg.Database.WorldUser = getEnv("DB_WORLD_USER", cfg.Database.User)
+    cfg.Database.WorldPassword = getEnv("DB_WORLD_PASSWORD", cfg.Database.Password)
+    cfg.Database.WorldName = getEnv("DB_WORLD_NAME", "world")
+    
+    // Базы персонажей по реалмам: REALM_IDS=1,2,3 и DB_CHARS_*_<ID> для переопределения
+    cfg.Database.RealmChars = make(map[int]RealmDBConfig)
+    for _, rawID := range strings.Split(getEnv("REALM_IDS", "1"), ",") {
+        id, err := strconv.Atoi(strings.TrimSpace(rawID))
+        if err != nil {
+            continue
+        }
+        suffix := "_" + strconv.Itoa(id)
+        cfg.Database.RealmChars[id] = RealmDBConfig{
+            Host:     getEnv("DB_CHARS_HOST"+suffix, cfg.Database.CharsHost),
+            Port:     getEnv("DB_CHARS_PORT"+suffix, cfg.Database.CharsPort),
+            User:     getEnv("DB_CHARS_USER"+suffix, cfg.Database.CharsUser),
+            Password: getEnv("DB_CHARS_PASSWORD"+suffix, cfg.Database.CharsPassword),
+            Name:     getEnv("DB_CHARS_NAME"+suffix, cfg.Database.CharsName),
+        }
+    }
+    
     cfg.Database.MaxOpenConns, _ = strconv.Atoi(getEnv("DB_MAX_OPEN_CONNS", "25"))
     cfg.Database.MaxIdleConns, _ = strconv.Atoi(getEnv("DB_MAX_IDLE_CONNS", "5"))
     cfg.Database.ConnMaxLifetime, _ = time.ParseDuration(getEnv("DB_CONN_MAX_LIFETIME", "300") + "s")
@@ -59,6 +83,8 @@ func LoadConfig() error {
     cfg.Game.ServerCore, _ = strconv.Atoi(getEnv("SERVER_CORE", "0"))
     cfg.Game.Expansion, _ = strconv.Atoi(getEnv("EXPANSION", "2"))
     cfg.Game.RealmList = getEnv("REALMLIST", "logon.yourserver.com")
+    cfg.Game.AuthServerPort = getEnv("AUTHSERVER_PORT", "3724")
+    cfg.Game.DefaultRealm, _ = strconv.Atoi(getEnv("DEFAULT_REALM", "1"))
     cfg.Game.ServerName = getEnv("SERVER_NAME", "WoW WotLK Server")
     cfg.Game.ServerMOTD = getEnv("SERVER_MOTD", "Welcome to our WoW Server!")
     cfg.Game.MaxAccountsPerIP, _ = strconv.Atoi(getEnv("MAX_ACCOUNTS_PER_IP", "5"))
@@ -66,6 +92,7 @@ func LoadConfig() error {
     
     cfg.Game.BattlenetSupport, _ = strconv.ParseBool(getEnv("BATTLENET_SUPPORT", "false"))
     cfg.Game.SRP6Version, _ = strconv.Atoi(getEnv("SRP6_VERSION", "0"))
+    cfg.Game.ArenaSeason, _ = strconv.Atoi(getEnv("ARENA_SEASON", "8"))
     
     // Security
     cfg.Security.PasswordMinLen, _ = strconv.Atoi(getEnv("PASSWORD_MIN_LEN", "4"))
@@ -91,6 +118,10 @@ func LoadConfig() error {
     cfg.Security.TwoFAProvider = getEnv("2FA_PROVIDER", "totp")
     cfg.Security.TwoFAIssuer = getEnv("2FA_ISSUER", "WoW Server")
     
+    cfg.Security.AdminGMLevel, _ = strconv.Atoi(getEnv("ADMIN_GM_LEVEL", "3"))
+    cfg.Security.BanReasons = strings.Split(getEnv("BAN_REASONS", "Cheating,Botting,Exploiting,Gold selling,Harassment,Account sharing"), ",")
+    cfg.Security.TrustedProxies = strings.Split(getEnv("TRUSTED_PROXIES", ""), ",")
+    
     // Email
     cfg.Email.SMTPHost = getEnv("SMTP_HOST", "smtp.gmail.com")
     cfg.Email.SMTPPort = getEnv("SMTP_PORT", "587")
@@ -107,6 +138,8 @@ func LoadConfig() error {
     cfg.Cache.Duration, _ = strconv.Atoi(getEnv("CACHE_DURATION", "300"))
     cfg.Cache.StatsDuration, _ = strconv.Atoi(getEnv("STATS_CACHE_DURATION", "60"))
     cfg.Cache.PlayersDuration, _ = strconv.Atoi(getEnv("PLAYERS_CACHE_DURATION", "30"))
+    cfg.Cache.ArmoryDuration, _ = strconv.Atoi(getEnv("ARMORY_CACHE_DURATION", "600"))
+    cfg.Cache.LeaderboardDuration, _ = strconv.Atoi(getEnv("LEADERBOARD_CACHE_DURATION", "900"))
     
     // Logging
     cfg.Logging.FilePath = getEnv("LOG_FILE_PATH", "./logs/app.log")
@@ -114,12 +147,15 @@ func LoadConfig() error {
     cfg.Logging.MaxAge, _ = strconv.Atoi(getEnv("LOG_MAX_AGE", "30"))
     cfg.Logging.MaxBackups, _ = strconv.Atoi(getEnv("LOG_MAX_BACKUPS", "7"))
     cfg.Logging.Compress, _ = strconv.ParseBool(getEnv("LOG_COMPRESS", "true"))
+    cfg.Logging.AuditRetentionDays, _ = strconv.Atoi(getEnv("AUDIT_RETENTION_DAYS", "365"))
     
     // Monitoring
     cfg.Monitoring.EnableMetrics, _ = strconv.ParseBool(getEnv("ENABLE_METRICS", "true"))
     cfg.Monitoring.MetricsPort = getEnv("METRICS_PORT", "9090")
     cfg.Monitoring.EnableHealthChecks, _ = strconv.ParseBool(getEnv("ENABLE_HEALTH_CHECKS", "true"))
     cfg.Monitoring.HealthCheckInterval, _ = strconv.Atoi(getEnv("HEALTH_CHECK_INTERVAL", "30"))
+    cfg.Monitoring.StatsSampleInterval, _ = strconv.Atoi(getEnv("STATS_SAMPLE_INTERVAL", "300"))
+    cfg.Monitoring.LadderInterval, _ = strconv.Atoi(getEnv("LADDER_REFRESH_INTERVAL", "600"))
     
     cfg.Monitoring.PrometheusEnabled, _ = strconv.ParseBool(getEnv("PROMETHEUS_ENABLED", "true"))
     cfg.Monitoring.PrometheusPath = getEnv("PROMETHEUS_PATH", "/metrics")
@@ -128,15 +164,47 @@ func LoadConfig() error {
     cfg.Integrations.DiscordWebhookURL = getEnv("DISCORD_WEBHOOK_URL", "")
     cfg.Integrations.DiscordRegistrationNotify, _ = strconv.ParseBool(getEnv("DISCORD_REGISTRATION_NOTIFY", "true"))
     cfg.Integrations.DiscordChannelID = getEnv("DISCORD_CHANNEL_ID", "")
+    // Подменяет https://discord.com в адресе webhook'а, например на локальный фейк
+    cfg.Integrations.DiscordBaseURL = getEnv("DISCORD_BASE_URL", "")
+    cfg.Integrations.DiscordQueueSize, _ = strconv.Atoi(getEnv("DISCORD_QUEUE_SIZE", "100"))
     
     cfg.Integrations.TelegramBotToken = getEnv("TELEGRAM_BOT_TOKEN", "")
     cfg.Integrations.TelegramChatID = getEnv("TELEGRAM_CHAT_ID", "")
+    // Подменяет https://api.telegram.org, например на локальный фейк
+    cfg.Integrations.TelegramAPIBase = getEnv("TELEGRAM_API_BASE", "")
+    cfg.Integrations.TelegramQueueSize, _ = strconv.Atoi(getEnv("TELEGRAM_QUEUE_SIZE", "100"))
+    cfg.Integrations.TelegramCommands, _ = strconv.ParseBool(getEnv("TELEGRAM_COMMANDS", "true"))
+    cfg.Integrations.TelegramAlerts = strings.Split(getEnv("TELEGRAM_ALERTS", "realm.down,realm.up,maintenance.start,maintenance.end,donation"), ",")
+    // Telegram user ID гейм-мастеров, которым доступны /ban и /unban
+    for _, rawID := range strings.Split(getEnv("TELEGRAM_ADMIN_IDS", ""), ",") {
+        if id, err := strconv.ParseInt(strings.TrimSpace(rawID), 10, 64); err == nil {
+            cfg.Integrations.TelegramAdminIDs = append(cfg.Integrations.TelegramAdminIDs, id)
+        }
+    }
+    
+    // Исходящие webhook'и; адреса и подписки настраиваются в /admin/webhooks
+    cfg.Integrations.WebhooksEnabled, _ = strconv.ParseBool(getEnv("WEBHOOKS_ENABLED", "true"))
+    cfg.Integrations.WebhookTimeout, _ = strconv.Atoi(getEnv("WEBHOOK_TIMEOUT", "10"))
+    cfg.Integrations.WebhookMaxAttempts, _ = strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "10"))
+    cfg.Integrations.WebhookLogDays, _ = strconv.Atoi(getEnv("WEBHOOK_LOG_DAYS", "30"))
     
     cfg.Integrations.SOAPEnabled, _ = strconv.ParseBool(getEnv("SOAP_ENABLED", "false"))
     cfg.Integrations.SOAPHost = getEnv("SOAP_HOST", "localhost")
     cfg.Integrations.SOAPPort = getEnv("SOAP_PORT", "7878")
     cfg.Integrations.SOAPUser = getEnv("SOAP_USER", "admin")
     cfg.Integrations.SOAPPassword = getEnv("SOAP_PASSWORD", "admin")
+    cfg.Integrations.SOAPURN = getEnv("SOAP_URN", "")
+    cfg.Integrations.SOAPTimeout, _ = strconv.Atoi(getEnv("SOAP_TIMEOUT", "10"))
+    cfg.Integrations.SOAPCommands = strings.Split(getEnv("SOAP_COMMANDS", "server info,revive,tele name,character rename,character customize,character changefaction,character changerace,character level,send items,send money,send mail,kick"), ",")
+    // SOAP_HOST_<ID> / SOAP_PORT_<ID> для реалмов из REALM_IDS
+    cfg.Integrations.SOAPEndpoints = make(map[int]SOAPEndpoint)
+    for id := range cfg.Database.RealmChars {
+        suffix := "_" + strconv.Itoa(id)
+        cfg.Integrations.SOAPEndpoints[id] = SOAPEndpoint{
+            Host: getEnv("SOAP_HOST"+suffix, cfg.Integrations.SOAPHost),
+            Port: getEnv("SOAP_PORT"+suffix, cfg.Integrations.SOAPPort),
+        }
+    }
     
     // Frontend
     cfg.Frontend.Theme = getEnv("THEME", "dark")
@@ -187,6 +255,30 @@ func LoadConfig() error {
     cfg.Custom.ReferralSystemEnabled, _ = strconv.ParseBool(getEnv("REFERRAL_SYSTEM_ENABLED", "false"))
     cfg.Custom.ReferralReward = getEnv("REFERRAL_REWARD", "")
     cfg.Custom.MaxReferralsPerAccount, _ = strconv.Atoi(getEnv("MAX_REFERRALS_PER_ACCOUNT", "10"))
+    // Награда рефереру, когда приглашенный достиг уровня или наиграл столько часов
+    cfg.Custom.ReferralMilestoneLevel, _ = strconv.Atoi(getEnv("REFERRAL_MILESTONE_LEVEL", "60"))
+    cfg.Custom.ReferralMilestoneHours, _ = strconv.Atoi(getEnv("REFERRAL_MILESTONE_HOURS", "10"))
+    
+    cfg.Custom.Leaderboards = strings.Split(getEnv("LEADERBOARDS", "playtime,wealth,achievements,first-80"), ",")
+    cfg.Custom.LeaderboardSize, _ = strconv.Atoi(getEnv("LEADERBOARD_SIZE", "100"))
+    
+    // Инструменты персонажей: "action:значение,…", действия без стоимости бесплатны
+    cfg.Custom.CharacterToolsEnabled, _ = strconv.ParseBool(getEnv("CHARACTER_TOOLS_ENABLED", "true"))
+    cfg.Custom.CharacterToolCosts = getEnv("CHARACTER_TOOL_COSTS", "")
+    cfg.Custom.CharacterToolCooldowns = getEnv("CHARACTER_TOOL_COOLDOWNS", "unstuck:1h,revive:30m,rename:24h,customize:24h,faction:168h,race:168h")
+    
+    // Магазин за поинты; 0 — потолок буста равен максимальному уровню дополнения
+    cfg.Custom.ShopEnabled, _ = strconv.ParseBool(getEnv("SHOP_ENABLED", "false"))
+    cfg.Custom.ShopMaxBoostLevel, _ = strconv.Atoi(getEnv("SHOP_MAX_BOOST_LEVEL", "0"))
+    
+    // Пожертвования: поинты за единицу валюты, минимальная сумма — в целых единицах
+    cfg.Custom.DonationsEnabled, _ = strconv.ParseBool(getEnv("DONATIONS_ENABLED", "false"))
+    cfg.Custom.DonationProviders = getEnv("DONATION_PROVIDERS", "")
+    cfg.Custom.DonationCurrency = strings.ToUpper(getEnv("DONATION_CURRENCY", "USD"))
+    cfg.Custom.DonationPointsPerUnit, _ = strconv.Atoi(getEnv("DONATION_POINTS_PER_UNIT", "10"))
+    cfg.Custom.DonationMinAmount, _ = strconv.Atoi(getEnv("DONATION_MIN_AMOUNT", "5"))
+    // Пожертвования от этой суммы объявляются в Discord; 0 — не объявлять
+    cfg.Custom.DonationNotifyAmount, _ = strconv.Atoi(getEnv("DONATION_NOTIFY_AMOUNT", "50"))
     
     // Сохраняем конфигурацию в глобальную переменную
     AppConfig = cfg
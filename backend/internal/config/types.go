@@ -32,6 +32,7 @@ type ServerConfig struct {
     RateLimit           int
     RateLimitWindow     int
     RegistrationCooldown int
+    SessionLifetime     int
 }
 
 type DatabaseConfig struct {
@@ -49,6 +50,9 @@ type DatabaseConfig struct {
     CharsPassword     string
     CharsName         string
     
+    // Per-realm character databases, keyed by realmlist.id
+    RealmChars        map[int]RealmDBConfig
+    
     // World database (optional)
     WorldHost         string
     WorldPort         string
@@ -62,6 +66,14 @@ type DatabaseConfig struct {
     ConnMaxLifetime   time.Duration
 }
 
+type RealmDBConfig struct {
+    Host     string
+    Port     string
+    User     string
+    Password string
+    Name     string
+}
+
 type RedisConfig struct {
     Host         string
     Port         string
@@ -75,12 +87,15 @@ type GameConfig struct {
     ServerCore       int
     Expansion        int
     RealmList        string
+    AuthServerPort   string
+    DefaultRealm     int
     ServerName       string
     ServerMOTD       string
     MaxAccountsPerIP int
     AllowMultiIP     bool
     BattlenetSupport bool
     SRP6Version      int
+    ArenaSeason      int
 }
 
 type SecurityConfig struct {
@@ -106,6 +121,10 @@ type SecurityConfig struct {
     Enable2FA                    bool
     TwoFAProvider                string
     TwoFAIssuer                  string
+    
+    AdminGMLevel                 int
+    BanReasons                   []string
+    TrustedProxies               []string
 }
 
 type EmailConfig struct {
@@ -125,14 +144,17 @@ type CacheConfig struct {
     Duration        int
     StatsDuration   int
     PlayersDuration int
+    ArmoryDuration  int
+    LeaderboardDuration int
 }
 
 type LoggingConfig struct {
-    FilePath    string
-    MaxSize     int
-    MaxAge      int
-    MaxBackups  int
-    Compress    bool
+    FilePath           string
+    MaxSize            int
+    MaxAge             int
+    MaxBackups         int
+    Compress           bool
+    AuditRetentionDays int
 }
 
 type MonitoringConfig struct {
@@ -140,6 +162,8 @@ type MonitoringConfig struct {
     MetricsPort         string
     EnableHealthChecks  bool
     HealthCheckInterval int
+    StatsSampleInterval int
+    LadderInterval      int
     PrometheusEnabled   bool
     PrometheusPath      string
 }
@@ -148,15 +172,37 @@ type IntegrationsConfig struct {
     DiscordWebhookURL        string
     DiscordRegistrationNotify bool
     DiscordChannelID         string
+    DiscordBaseURL           string
+    DiscordQueueSize         int
     
     TelegramBotToken         string
     TelegramChatID           string
+    TelegramAPIBase          string
+    TelegramQueueSize        int
+    TelegramCommands         bool
+    TelegramAlerts           []string
+    TelegramAdminIDs         []int64
+    
+    WebhooksEnabled          bool
+    WebhookTimeout           int
+    WebhookMaxAttempts       int
+    WebhookLogDays           int
     
     SOAPEnabled              bool
     SOAPHost                 string
     SOAPPort                 string
     SOAPUser                 string
     SOAPPassword             string
+    SOAPURN                  string
+    SOAPTimeout              int
+    SOAPCommands             []string
+    SOAPEndpoints            map[int]SOAPEndpoint
+}
+
+// У каждого реалма свой worldserver, а значит и свой SOAP
+type SOAPEndpoint struct {
+    Host string
+    Port string
 }
 
 type FrontendConfig struct {
@@ -212,4 +258,23 @@ type CustomConfig struct {
     ReferralSystemEnabled     bool
     ReferralReward            string
     MaxReferralsPerAccount    int
+    ReferralMilestoneLevel    int
+    ReferralMilestoneHours    int
+    
+    Leaderboards              []string
+    LeaderboardSize           int
+    
+    CharacterToolsEnabled     bool
+    CharacterToolCosts        string
+    CharacterToolCooldowns    string
+    
+    ShopEnabled               bool
+    ShopMaxBoostLevel         int
+    
+    DonationsEnabled          bool
+    DonationProviders         string
+    DonationCurrency          string
+    DonationPointsPerUnit     int
+    DonationMinAmount         int
+    DonationNotifyAmount      int
 }
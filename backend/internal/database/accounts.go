@@ -0,0 +1,188 @@
+package database
+
+import (
+    "time"
+    "wow-registration/internal/config"
+)
+
+type AccountCharacter struct {
+    Character
+    Online     bool
+    TotalTime  int64
+    LogoutTime int64
+}
+
+type LoginRecord struct {
+    IP   string
+    Time time.Time
+}
+
+// GetAccountCharacterDetails — персонажи аккаунта на реалме вместе с /played и временем выхода
+func GetAccountCharacterDetails(realmID, accountID int) ([]AccountCharacter, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT guid, name, race, class, gender, level, zone, online, totaltime, logout_time
+        FROM characters
+        WHERE account = ?
+        ORDER BY level DESC, name
+    `
+    
+    rows, err := db.Query(query, accountID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var characters []AccountCharacter
+    for rows.Next() {
+        c := AccountCharacter{Character: Character{RealmID: realmID}}
+        if err := rows.Scan(&c.GUID, &c.Name, &c.Race, &c.Class, &c.Gender, &c.Level, &c.Zone,
+            &c.Online, &c.TotalTime, &c.LogoutTime); err != nil {
+            return nil, err
+        }
+        characters = append(characters, c)
+    }
+    return characters, rows.Err()
+}
+
+// GetLoginHistory — последние входы аккаунта. TrinityCore пишет их в logs_ip_actions
+// (только при включенном AllowLoggingIPAddressesInDatabase), CMangos — в account_logons.
+// Если таблицы нет, вернется ошибка: история у ядра недоступна
+func GetLoginHistory(accountID, limit int) ([]LoginRecord, error) {
+    query := `
+        SELECT ip, unixtime FROM logs_ip_actions
+        WHERE account_id = ? AND type = 0
+        ORDER BY unixtime DESC LIMIT ?
+    `
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = `
+            SELECT ip, UNIX_TIMESTAMP(loginTime) FROM account_logons
+            WHERE accountId = ?
+            ORDER BY loginTime DESC LIMIT ?
+        `
+    }
+    
+    rows, err := DB.Query(query, accountID, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var history []LoginRecord
+    for rows.Next() {
+        var record LoginRecord
+        var unix int64
+        if err := rows.Scan(&record.IP, &unix); err != nil {
+            return nil, err
+        }
+        record.Time = time.Unix(unix, 0)
+        history = append(history, record)
+    }
+    return history, rows.Err()
+}
+
+type PasswordHistoryEntry struct {
+    Salt         string
+    VerifierHash string
+}
+
+// UpdateCredentials записывает новый пароль сразу в обоих форматах: sha_pass_hash
+// и SRP6 соль/verifier. sessionkey сбрасывается, чтобы клиент перелогинился
+func UpdateCredentials(accountID int, hash, salt, verifier string) error {
+    query := `
+        UPDATE account
+        SET sha_pass_hash = ?, s = ?, v = ?, sessionkey = ''
+        WHERE id = ?
+    `
+    
+    _, err := DB.Exec(query, hash, salt, verifier, accountID)
+    return err
+}
+
+func UpdateEmail(accountID int, email string) error {
+    _, err := DB.Exec("UPDATE account SET email = ? WHERE id = ?", email, accountID)
+    return err
+}
+
+func EmailInUse(email string) (bool, error) {
+    var count int
+    if err := DB.QueryRow("SELECT COUNT(*) FROM account WHERE email = ?", email).Scan(&count); err != nil {
+        return false, err
+    }
+    return count > 0, nil
+}
+
+// GetPasswordHistory — последние limit записей истории паролей, новые первыми
+func GetPasswordHistory(accountID, limit int) ([]PasswordHistoryEntry, error) {
+    query := `
+        SELECT salt, verifier_hash FROM web_password_history
+        WHERE account_id = ?
+        ORDER BY id DESC LIMIT ?
+    `
+    
+    rows, err := DB.Query(query, accountID, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var history []PasswordHistoryEntry
+    for rows.Next() {
+        var entry PasswordHistoryEntry
+        if err := rows.Scan(&entry.Salt, &entry.VerifierHash); err != nil {
+            return nil, err
+        }
+        history = append(history, entry)
+    }
+    return history, rows.Err()
+}
+
+// AddPasswordHistory добавляет запись и оставляет у аккаунта только keep последних
+func AddPasswordHistory(accountID int, entry PasswordHistoryEntry, keep int) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    _, err = tx.Exec(
+        "INSERT INTO web_password_history (account_id, salt, verifier_hash, created_at) VALUES (?, ?, ?, NOW())",
+        accountID, entry.Salt, entry.VerifierHash,
+    )
+    if err != nil {
+        return err
+    }
+    
+    // MySQL не умеет LIMIT в подзапросе с IN, поэтому через производную таблицу
+    _, err = tx.Exec(`
+        DELETE FROM web_password_history
+        WHERE account_id = ? AND id NOT IN (
+            SELECT id FROM (
+                SELECT id FROM web_password_history
+                WHERE account_id = ?
+                ORDER BY id DESC LIMIT ?
+            ) recent
+        )
+    `, accountID, accountID, keep)
+    if err != nil {
+        return err
+    }
+    
+    return tx.Commit()
+}
+
+// GetGMLevel — GM-уровень аккаунта, действующий на всех реалмах
+func GetGMLevel(accountID int) (int, error) {
+    query := "SELECT COALESCE(MAX(gmlevel), 0) FROM account_access WHERE id = ? AND RealmID = -1"
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = "SELECT COALESCE(MAX(gmlevel), 0) FROM account WHERE id = ?"
+    }
+    
+    var level int
+    err := DB.QueryRow(query, accountID).Scan(&level)
+    return level, err
+}
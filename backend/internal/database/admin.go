@@ -0,0 +1,246 @@
+package database
+
+import (
+    "database/sql"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+)
+
+// AccountSearch — фильтры поиска аккаунтов в панели. Пустые поля не учитываются
+type AccountSearch struct {
+    Username string
+    Email    string
+    IP       string
+    From     time.Time
+    To       time.Time
+    Limit    int
+    Offset   int
+}
+
+type AccountSummary struct {
+    ID        int        `json:"id"`
+    Username  string     `json:"username"`
+    Email     string     `json:"email"`
+    JoinDate  time.Time  `json:"join_date"`
+    LastLogin *time.Time `json:"last_login,omitempty"`
+    LastIP    string     `json:"last_ip"`
+    Locked    bool       `json:"locked"`
+}
+
+// Экранирует % и _ для LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchAccounts ищет по началу имени, подстроке почты, IP последнего входа
+// и дате регистрации. Возвращает страницу и общее число совпадений
+func SearchAccounts(s AccountSearch) ([]AccountSummary, int, error) {
+    var where []string
+    var args []interface{}
+    if s.Username != "" {
+        where = append(where, "username LIKE ?")
+        args = append(args, likeEscaper.Replace(strings.ToUpper(s.Username))+"%")
+    }
+    if s.Email != "" {
+        where = append(where, "email LIKE ?")
+        args = append(args, "%"+likeEscaper.Replace(strings.ToUpper(s.Email))+"%")
+    }
+    if s.IP != "" {
+        where = append(where, "last_ip = ?")
+        args = append(args, s.IP)
+    }
+    if !s.From.IsZero() {
+        where = append(where, "joindate >= ?")
+        args = append(args, s.From)
+    }
+    if !s.To.IsZero() {
+        where = append(where, "joindate < ?")
+        args = append(args, s.To)
+    }
+    
+    filter := ""
+    if len(where) > 0 {
+        filter = " WHERE " + strings.Join(where, " AND ")
+    }
+    
+    var total int
+    if err := DB.QueryRow("SELECT COUNT(*) FROM account"+filter, args...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
+    
+    rows, err := DB.Query(
+        "SELECT id, username, email, joindate, last_login, last_ip, locked FROM account"+filter+" ORDER BY id DESC LIMIT ? OFFSET ?",
+        append(args, s.Limit, s.Offset)...,
+    )
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+    
+    var accounts []AccountSummary
+    for rows.Next() {
+        var a AccountSummary
+        var lastLogin sql.NullTime
+        if err := rows.Scan(&a.ID, &a.Username, &a.Email, &a.JoinDate, &lastLogin, &a.LastIP, &a.Locked); err != nil {
+            return nil, 0, err
+        }
+        if lastLogin.Valid {
+            a.LastLogin = &lastLogin.Time
+        }
+        accounts = append(accounts, a)
+    }
+    return accounts, total, rows.Err()
+}
+
+// GetAccountByID — аккаунт по id, те же поля, что у GetAccountByUsername
+func GetAccountByID(id int) (*Account, error) {
+    account := &Account{}
+    err := DB.QueryRow(`
+        SELECT id, username, email, expansion, joindate, last_login, last_ip, locked
+        FROM account WHERE id = ?
+    `, id).Scan(
+        &account.ID, &account.Username, &account.Email, &account.Expansion,
+        &account.CreatedAt, &account.LastLogin, &account.IP, &account.Locked,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return account, nil
+}
+
+// SetAccountLocked — флаг locked ядра: вход только с IP последнего входа
+func SetAccountLocked(accountID int, locked bool) error {
+    _, err := DB.Exec("UPDATE account SET locked = ? WHERE id = ?", locked, accountID)
+    return err
+}
+
+func SetAccountExpansion(accountID, expansion int) error {
+    _, err := DB.Exec("UPDATE account SET expansion = ? WHERE id = ?", expansion, accountID)
+    return err
+}
+
+// ResetTwoFactor отключает аутентификатор: TrinityCore и AzerothCore хранят
+// секрет TOTP в totp_secret, CMangos — в token
+func ResetTwoFactor(accountID int) error {
+    query := "UPDATE account SET totp_secret = NULL WHERE id = ?"
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = "UPDATE account SET token = '' WHERE id = ?"
+    }
+    _, err := DB.Exec(query, accountID)
+    return err
+}
+
+// RealmGMLevel — строка account_access: уровень на одном реалме, -1 — на всех
+type RealmGMLevel struct {
+    RealmID int `json:"realm_id"`
+    Level   int `json:"gm_level"`
+}
+
+// GetRealmGMLevels возвращает все строки прав аккаунта, включая права на
+// отдельных реалмах; у CMangos уровень один и хранится в account
+func GetRealmGMLevels(accountID int) ([]RealmGMLevel, error) {
+    query := "SELECT RealmID, gmlevel FROM account_access WHERE id = ? ORDER BY RealmID"
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = "SELECT -1, gmlevel FROM account WHERE id = ? AND gmlevel > 0"
+    }
+    
+    rows, err := DB.Query(query, accountID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    levels := []RealmGMLevel{}
+    for rows.Next() {
+        var l RealmGMLevel
+        if err := rows.Scan(&l.RealmID, &l.Level); err != nil {
+            return nil, err
+        }
+        levels = append(levels, l)
+    }
+    return levels, rows.Err()
+}
+
+// GetHighestGMLevel — наибольший GM-уровень аккаунта на любом из реалмов
+func GetHighestGMLevel(accountID int) (int, error) {
+    levels, err := GetRealmGMLevels(accountID)
+    if err != nil {
+        return 0, err
+    }
+    highest := 0
+    for _, l := range levels {
+        if l.Level > highest {
+            highest = l.Level
+        }
+    }
+    return highest, nil
+}
+
+// SetGMLevel задает GM-уровень на всех реалмах; 0 — обычный игрок
+func SetGMLevel(accountID, level int) error {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        _, err := DB.Exec("UPDATE account SET gmlevel = ? WHERE id = ?", level, accountID)
+        return err
+    }
+    
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    // Права на отдельных реалмах тоже снимаются, иначе на них остался бы старый уровень
+    if _, err := tx.Exec("DELETE FROM account_access WHERE id = ?", accountID); err != nil {
+        return err
+    }
+    if level > 0 {
+        if _, err := tx.Exec("INSERT INTO account_access (id, gmlevel, RealmID) VALUES (?, ?, -1)", accountID, level); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+type AccountNote struct {
+    ID         int       `json:"id"`
+    AccountID  int       `json:"account_id"`
+    AuthorID   int       `json:"author_id"`
+    AuthorName string    `json:"author_name"`
+    Note       string    `json:"note"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+func AddAccountNote(n *AccountNote) error {
+    n.CreatedAt = time.Now()
+    result, err := DB.Exec(
+        "INSERT INTO web_account_notes (account_id, author_id, author_name, note, created_at) VALUES (?, ?, ?, ?, ?)",
+        n.AccountID, n.AuthorID, n.AuthorName, n.Note, n.CreatedAt,
+    )
+    if err != nil {
+        return err
+    }
+    id, err := result.LastInsertId()
+    n.ID = int(id)
+    return err
+}
+
+// GetAccountNotes — заметки к аккаунту, новые сверху
+func GetAccountNotes(accountID int) ([]AccountNote, error) {
+    rows, err := DB.Query(
+        "SELECT id, account_id, author_id, author_name, note, created_at FROM web_account_notes WHERE account_id = ? ORDER BY id DESC",
+        accountID,
+    )
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var notes []AccountNote
+    for rows.Next() {
+        var n AccountNote
+        if err := rows.Scan(&n.ID, &n.AccountID, &n.AuthorID, &n.AuthorName, &n.Note, &n.CreatedAt); err != nil {
+            return nil, err
+        }
+        notes = append(notes, n)
+    }
+    return notes, rows.Err()
+}
@@ -0,0 +1,355 @@
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "strings"
+)
+
+type CharacterProfile struct {
+    Character
+    Account    int
+    Online     bool
+    TotalTime  int64
+    TotalKills int
+    GuildID    int
+    GuildName  string
+}
+
+type EquippedItem struct {
+    Slot  int
+    Entry int
+}
+
+type CharacterTalent struct {
+    Spell    int
+    SpecMask int
+}
+
+type CharacterSkill struct {
+    Skill int
+    Value int
+    Max   int
+}
+
+type CharacterReputation struct {
+    Faction  int
+    Standing int
+}
+
+// GetCharacterByName ищет персонажа по имени; удаленные персонажи (account = 0) не отдаются
+func GetCharacterByName(realmID int, name string) (*CharacterProfile, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT c.guid, c.account, c.name, c.race, c.class, c.gender, c.level, c.zone,
+               c.online, c.totaltime, c.totalKills,
+               COALESCE(g.guildid, 0), COALESCE(g.name, '')
+        FROM characters c
+        LEFT JOIN guild_member gm ON gm.guid = c.guid
+        LEFT JOIN guild g ON g.guildid = gm.guildid
+        WHERE c.name = ? AND c.account <> 0
+    `
+    
+    p := &CharacterProfile{}
+    err = db.QueryRow(query, name).Scan(
+        &p.GUID, &p.Account, &p.Name, &p.Race, &p.Class, &p.Gender, &p.Level, &p.Zone,
+        &p.Online, &p.TotalTime, &p.TotalKills,
+        &p.GuildID, &p.GuildName,
+    )
+    if err != nil {
+        return nil, err
+    }
+    p.RealmID = realmID
+    return p, nil
+}
+
+// GetCharacterOwner возвращает аккаунт владельца и имя персонажа
+func GetCharacterOwner(realmID, guid int) (int, string, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return 0, "", err
+    }
+    
+    var account int
+    var name string
+    err = db.QueryRow("SELECT account, name FROM characters WHERE guid = ?", guid).Scan(&account, &name)
+    return account, name, err
+}
+
+// GetAccountCharacters — все персонажи аккаунта на реалме
+func GetAccountCharacters(realmID, accountID int) ([]Character, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT guid, name, race, class, gender, level, zone
+        FROM characters
+        WHERE account = ?
+        ORDER BY level DESC, name
+    `
+    
+    rows, err := db.Query(query, accountID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var characters []Character
+    for rows.Next() {
+        c := Character{RealmID: realmID}
+        if err := rows.Scan(&c.GUID, &c.Name, &c.Race, &c.Class, &c.Gender, &c.Level, &c.Zone); err != nil {
+            return nil, err
+        }
+        characters = append(characters, c)
+    }
+    return characters, rows.Err()
+}
+
+// GetHiddenCharacters — GUID скрытых из армори персонажей аккаунта на реалме
+func GetHiddenCharacters(realmID, accountID int) (map[int]bool, error) {
+    rows, err := DB.Query("SELECT guid FROM web_armory_privacy WHERE realm_id = ? AND account_id = ?", realmID, accountID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    hidden := make(map[int]bool)
+    for rows.Next() {
+        var guid int
+        if err := rows.Scan(&guid); err != nil {
+            return nil, err
+        }
+        hidden[guid] = true
+    }
+    return hidden, rows.Err()
+}
+
+// GetEquippedItems — надетые предметы (сумка 0, слоты 0–18)
+func GetEquippedItems(realmID, guid int) ([]EquippedItem, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT ci.slot, ii.itemEntry
+        FROM character_inventory ci
+        JOIN item_instance ii ON ii.guid = ci.item
+        WHERE ci.guid = ? AND ci.bag = 0 AND ci.slot < 19
+        ORDER BY ci.slot
+    `
+    
+    rows, err := db.Query(query, guid)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var items []EquippedItem
+    for rows.Next() {
+        var item EquippedItem
+        if err := rows.Scan(&item.Slot, &item.Entry); err != nil {
+            return nil, err
+        }
+        items = append(items, item)
+    }
+    return items, rows.Err()
+}
+
+func GetCharacterTalents(realmID, guid int) ([]CharacterTalent, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    rows, err := db.Query("SELECT spell, specMask FROM character_talent WHERE guid = ?", guid)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var talents []CharacterTalent
+    for rows.Next() {
+        var t CharacterTalent
+        if err := rows.Scan(&t.Spell, &t.SpecMask); err != nil {
+            return nil, err
+        }
+        talents = append(talents, t)
+    }
+    return talents, rows.Err()
+}
+
+func GetCharacterSkills(realmID, guid int, skills []int) ([]CharacterSkill, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    if len(skills) == 0 {
+        return nil, nil
+    }
+    
+    placeholders := make([]string, len(skills))
+    args := []interface{}{guid}
+    for i, skill := range skills {
+        placeholders[i] = "?"
+        args = append(args, skill)
+    }
+    
+    query := `
+        SELECT skill, value, max
+        FROM character_skills
+        WHERE guid = ? AND skill IN (` + strings.Join(placeholders, ", ") + `)
+        ORDER BY value DESC
+    `
+    
+    rows, err := db.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var result []CharacterSkill
+    for rows.Next() {
+        var s CharacterSkill
+        if err := rows.Scan(&s.Skill, &s.Value, &s.Max); err != nil {
+            return nil, err
+        }
+        result = append(result, s)
+    }
+    return result, rows.Err()
+}
+
+// GetCharacterReputation — только видимые в игре фракции (FACTION_FLAG_VISIBLE)
+func GetCharacterReputation(realmID, guid int) ([]CharacterReputation, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT faction, standing
+        FROM character_reputation
+        WHERE guid = ? AND (flags & 1) = 1
+        ORDER BY standing DESC
+    `
+    
+    rows, err := db.Query(query, guid)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var result []CharacterReputation
+    for rows.Next() {
+        var r CharacterReputation
+        if err := rows.Scan(&r.Faction, &r.Standing); err != nil {
+            return nil, err
+        }
+        result = append(result, r)
+    }
+    return result, rows.Err()
+}
+
+func GetCharacterAchievements(realmID, guid int) ([]int, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    rows, err := db.Query("SELECT achievement FROM character_achievement WHERE guid = ?", guid)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var ids []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// GetAchievementPoints — справочник очков из web_achievements
+func GetAchievementPoints() (map[int]int, error) {
+    rows, err := DB.Query("SELECT id, points FROM web_achievements")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    points := make(map[int]int)
+    for rows.Next() {
+        var id, p int
+        if err := rows.Scan(&id, &p); err != nil {
+            return nil, err
+        }
+        points[id] = p
+    }
+    return points, rows.Err()
+}
+
+func IsArmoryHidden(realmID, guid int) (bool, error) {
+    var exists int
+    err := DB.QueryRow("SELECT 1 FROM web_armory_privacy WHERE realm_id = ? AND guid = ?", realmID, guid).Scan(&exists)
+    if err == sql.ErrNoRows {
+        return false, nil
+    }
+    return err == nil, err
+}
+
+func SetArmoryHidden(realmID, guid, accountID int, hidden bool) error {
+    if !hidden {
+        _, err := DB.Exec("DELETE FROM web_armory_privacy WHERE realm_id = ? AND guid = ?", realmID, guid)
+        return err
+    }
+    
+    query := `
+        INSERT INTO web_armory_privacy (realm_id, guid, account_id)
+        VALUES (?, ?, ?)
+        ON DUPLICATE KEY UPDATE account_id = VALUES(account_id)
+    `
+    _, err := DB.Exec(query, realmID, guid, accountID)
+    return err
+}
+
+type Achievement struct {
+    ID     int
+    Name   string
+    Points int
+}
+
+// ImportAchievements заливает справочник достижений одной транзакцией
+func ImportAchievements(achievements []Achievement) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    stmt, err := tx.Prepare(`
+        INSERT INTO web_achievements (id, name, points)
+        VALUES (?, ?, ?)
+        ON DUPLICATE KEY UPDATE name = VALUES(name), points = VALUES(points)
+    `)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+    
+    for _, a := range achievements {
+        if _, err := stmt.Exec(a.ID, a.Name, a.Points); err != nil {
+            return fmt.Errorf("achievement %d: %w", a.ID, err)
+        }
+    }
+    return tx.Commit()
+}
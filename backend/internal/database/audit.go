@@ -0,0 +1,301 @@
+package database
+
+import (
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "encoding/json"
+    "strings"
+    "time"
+)
+
+// AuditEntry — запись журнала аудита. ActorID 0 — действие не от имени
+// аккаунта сайта (Telegram, аноним); Details и Changes — JSON
+type AuditEntry struct {
+    ID              int64     `json:"id"`
+    ActorID         int       `json:"actor_id"`
+    ActorName       string    `json:"actor_name"`
+    Action          string    `json:"action"`
+    TargetAccountID int       `json:"target_account_id"`
+    Target          string    `json:"target"`
+    Details         string    `json:"details"`
+    Changes         string    `json:"changes"`
+    IP              string    `json:"ip"`
+    UserAgent       string    `json:"user_agent"`
+    RequestID       string    `json:"request_id"`
+    PrevHash        string    `json:"prev_hash"`
+    Hash            string    `json:"hash"`
+    CreatedAt       time.Time `json:"created_at"`
+}
+
+// AuditFilter — фильтры поиска по журналу. Пустые поля не учитываются;
+// Action — префикс ("account." найдет все действия с аккаунтами)
+type AuditFilter struct {
+    Actor           string
+    Action          string
+    TargetAccountID int
+    Target          string
+    IP              string
+    RequestID       string
+    MaxID           int64
+    From            time.Time
+    To              time.Time
+    Limit           int
+    Offset          int
+}
+
+// AuditChainStatus — результат проверки цепочки хешей
+type AuditChainStatus struct {
+    Checked  int   `json:"checked"`
+    Unsealed int   `json:"unsealed"` // записи до появления цепочки, без хеша
+    BrokenAt int64 `json:"broken_at,omitempty"`
+    LastID   int64 `json:"last_id"`
+    PrunedID int64 `json:"pruned_id"`
+    Valid    bool  `json:"valid"`
+}
+
+const auditColumns = "id, actor_id, actor_name, action, target_account_id, target, details, changes, ip, user_agent, request_id, prev_hash, hash, created_at"
+
+// auditHash — SHA-256 от хеша предыдущей записи и всех полей текущей.
+// Время берется в секундах: DATETIME дробную часть не хранит
+func auditHash(e *AuditEntry) string {
+    data, _ := json.Marshal([]interface{}{
+        e.PrevHash, e.ActorID, e.ActorName, e.Action, e.TargetAccountID, e.Target,
+        e.Details, e.Changes, e.IP, e.UserAgent, e.RequestID, e.CreatedAt.Unix(),
+    })
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// AddAuditEntry дописывает запись в конец цепочки. Голова цепочки
+// блокируется на время вставки, так что записи не могут сослаться на один хеш
+func AddAuditEntry(e *AuditEntry) error {
+    e.CreatedAt = time.Now().Truncate(time.Second)
+    
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    if err := tx.QueryRow("SELECT last_hash FROM web_audit_chain WHERE id = 1 FOR UPDATE").Scan(&e.PrevHash); err != nil {
+        return err
+    }
+    e.Hash = auditHash(e)
+    
+    result, err := tx.Exec(`
+        INSERT INTO web_audit_log (actor_id, actor_name, action, target_account_id, target, details, changes,
+                                   ip, user_agent, request_id, prev_hash, hash, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, e.ActorID, e.ActorName, e.Action, e.TargetAccountID, e.Target, e.Details, e.Changes,
+        e.IP, e.UserAgent, e.RequestID, e.PrevHash, e.Hash, e.CreatedAt)
+    if err != nil {
+        return err
+    }
+    if e.ID, err = result.LastInsertId(); err != nil {
+        return err
+    }
+    if _, err := tx.Exec("UPDATE web_audit_chain SET last_id = ?, last_hash = ? WHERE id = 1", e.ID, e.Hash); err != nil {
+        return err
+    }
+    
+    return tx.Commit()
+}
+
+func scanAuditEntry(rows *sql.Rows) (AuditEntry, error) {
+    var e AuditEntry
+    err := rows.Scan(&e.ID, &e.ActorID, &e.ActorName, &e.Action, &e.TargetAccountID, &e.Target, &e.Details, &e.Changes,
+        &e.IP, &e.UserAgent, &e.RequestID, &e.PrevHash, &e.Hash, &e.CreatedAt)
+    return e, err
+}
+
+// GetAuditEntries — последние записи журнала; targetAccountID 0 — по всем аккаунтам
+func GetAuditEntries(targetAccountID, limit int) ([]AuditEntry, error) {
+    entries, _, err := SearchAuditEntries(AuditFilter{TargetAccountID: targetAccountID, Limit: limit})
+    return entries, err
+}
+
+func (f AuditFilter) where() (string, []interface{}) {
+    var conditions []string
+    var args []interface{}
+    if f.Actor != "" {
+        conditions = append(conditions, "actor_name = ?")
+        args = append(args, f.Actor)
+    }
+    if f.Action != "" {
+        conditions = append(conditions, "action LIKE ?")
+        args = append(args, likeEscaper.Replace(f.Action)+"%")
+    }
+    if f.TargetAccountID > 0 {
+        conditions = append(conditions, "target_account_id = ?")
+        args = append(args, f.TargetAccountID)
+    }
+    if f.Target != "" {
+        conditions = append(conditions, "target = ?")
+        args = append(args, f.Target)
+    }
+    if f.IP != "" {
+        conditions = append(conditions, "ip = ?")
+        args = append(args, f.IP)
+    }
+    if f.RequestID != "" {
+        conditions = append(conditions, "request_id = ?")
+        args = append(args, f.RequestID)
+    }
+    if f.MaxID > 0 {
+        conditions = append(conditions, "id <= ?")
+        args = append(args, f.MaxID)
+    }
+    if !f.From.IsZero() {
+        conditions = append(conditions, "created_at >= ?")
+        args = append(args, f.From)
+    }
+    if !f.To.IsZero() {
+        conditions = append(conditions, "created_at < ?")
+        args = append(args, f.To)
+    }
+    if len(conditions) == 0 {
+        return "", nil
+    }
+    return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// SearchAuditEntries — страница журнала по фильтру, новые сверху, и общее число совпадений
+func SearchAuditEntries(f AuditFilter) ([]AuditEntry, int, error) {
+    where, args := f.where()
+    
+    var total int
+    if err := DB.QueryRow("SELECT COUNT(*) FROM web_audit_log"+where, args...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
+    
+    rows, err := DB.Query("SELECT "+auditColumns+" FROM web_audit_log"+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+        append(args, f.Limit, f.Offset)...)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+    
+    var entries []AuditEntry
+    for rows.Next() {
+        e, err := scanAuditEntry(rows)
+        if err != nil {
+            return nil, 0, err
+        }
+        entries = append(entries, e)
+    }
+    return entries, total, rows.Err()
+}
+
+// EachAuditEntry обходит все записи по фильтру в порядке появления, не
+// загружая журнал в память целиком; Limit и Offset не учитываются
+func EachAuditEntry(f AuditFilter, fn func(*AuditEntry) error) error {
+    where, args := f.where()
+    rows, err := DB.Query("SELECT "+auditColumns+" FROM web_audit_log"+where+" ORDER BY id", args...)
+    if err != nil {
+        return err
+    }
+    defer rows.Close()
+    
+    for rows.Next() {
+        e, err := scanAuditEntry(rows)
+        if err != nil {
+            return err
+        }
+        if err := fn(&e); err != nil {
+            return err
+        }
+    }
+    return rows.Err()
+}
+
+// VerifyAuditChain пересчитывает хеши записей до текущей головы. Цепочка
+// цела, если каждая запись ссылается на хеш предыдущей, хеши совпадают с
+// содержимым, а последняя запись — та, что записана в голове; так видны и
+// правка, и удаление записей
+func VerifyAuditChain() (*AuditChainStatus, error) {
+    status := &AuditChainStatus{}
+    var headID int64
+    var expected, head string
+    err := DB.QueryRow("SELECT last_id, last_hash, pruned_id, pruned_hash FROM web_audit_chain WHERE id = 1").
+        Scan(&headID, &head, &status.PrunedID, &expected)
+    if err != nil {
+        return nil, err
+    }
+    if headID == 0 {
+        // В цепочку еще ничего не записано
+        status.Valid = true
+        return status, nil
+    }
+    
+    sealed := false
+    err = EachAuditEntry(AuditFilter{MaxID: headID}, func(e *AuditEntry) error {
+        if status.BrokenAt != 0 {
+            return nil
+        }
+        status.LastID = e.ID
+        if e.Hash == "" && !sealed {
+            status.Unsealed++
+            return nil
+        }
+        sealed = true
+        status.Checked++
+        if e.PrevHash != expected || auditHash(e) != e.Hash {
+            status.BrokenAt = e.ID
+            return nil
+        }
+        expected = e.Hash
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    
+    if status.BrokenAt == 0 && expected != head {
+        // Хвост журнала удален: голова ссылается на запись, которой нет
+        status.BrokenAt = status.LastID + 1
+        if status.LastID == 0 {
+            status.BrokenAt = status.PrunedID + 1
+        }
+    }
+    status.Valid = status.BrokenAt == 0
+    return status, nil
+}
+
+// PruneAuditEntries удаляет записи старше before и запоминает хеш последней
+// удаленной, чтобы проверка цепочки начиналась с нее
+func PruneAuditEntries(before time.Time) (int64, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback()
+    
+    // Голова блокируется, чтобы очистка не шла одновременно с вставкой
+    var head string
+    if err := tx.QueryRow("SELECT last_hash FROM web_audit_chain WHERE id = 1 FOR UPDATE").Scan(&head); err != nil {
+        return 0, err
+    }
+    
+    var lastID int64
+    var lastHash string
+    err = tx.QueryRow("SELECT id, hash FROM web_audit_log WHERE created_at < ? ORDER BY id DESC LIMIT 1", before).Scan(&lastID, &lastHash)
+    if err == sql.ErrNoRows {
+        return 0, nil
+    }
+    if err != nil {
+        return 0, err
+    }
+    
+    result, err := tx.Exec("DELETE FROM web_audit_log WHERE id <= ?", lastID)
+    if err != nil {
+        return 0, err
+    }
+    if _, err := tx.Exec("UPDATE web_audit_chain SET pruned_id = ?, pruned_hash = ? WHERE id = 1", lastID, lastHash); err != nil {
+        return 0, err
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, err
+    }
+    return result.RowsAffected()
+}
@@ -0,0 +1,389 @@
+package database
+
+import (
+    "database/sql"
+    "errors"
+    "time"
+    "wow-registration/internal/config"
+)
+
+// AccountBan — бан аккаунта. Until == nil — бессрочный; Active — не снят
+// вручную (истекший бан ядро тоже оставляет active = 1)
+type AccountBan struct {
+    AccountID int        `json:"account_id"`
+    Username  string     `json:"username,omitempty"`
+    BannedAt  time.Time  `json:"banned_at"`
+    Until     *time.Time `json:"until"`
+    BannedBy  string     `json:"banned_by"`
+    Reason    string     `json:"reason"`
+    Active    bool       `json:"active"`
+}
+
+// IPBan — бан адреса. Ядра сравнивают адрес целиком, диапазон — это набор строк
+type IPBan struct {
+    IP       string     `json:"ip"`
+    BannedAt time.Time  `json:"banned_at"`
+    Until    *time.Time `json:"until"`
+    BannedBy string     `json:"banned_by"`
+    Reason   string     `json:"reason"`
+}
+
+// CharacterBan — бан персонажа на реалме
+type CharacterBan struct {
+    RealmID  int        `json:"realm_id"`
+    GUID     int        `json:"guid"`
+    Name     string     `json:"name"`
+    BannedAt time.Time  `json:"banned_at"`
+    Until    *time.Time `json:"until"`
+    BannedBy string     `json:"banned_by"`
+    Reason   string     `json:"reason"`
+}
+
+var ErrCharacterBansUnsupported = errors.New("character bans are not supported for this core")
+
+// banUntil переводит unbandate в Until; бессрочный бан — nil
+func banUntil(bannedAt, until int64) *time.Time {
+    if until <= bannedAt {
+        return nil
+    }
+    t := time.Unix(until, 0)
+    return &t
+}
+
+// Бессрочный бан оба ядра записывают как unbandate == bandate
+func banFromRow(accountID int, bannedAt, until int64, by, reason string, active bool) *AccountBan {
+    return &AccountBan{
+        AccountID: accountID,
+        BannedAt:  time.Unix(bannedAt, 0),
+        Until:     banUntil(bannedAt, until),
+        BannedBy:  by,
+        Reason:    reason,
+        Active:    active,
+    }
+}
+
+// GetActiveBan — текущий бан аккаунта или nil
+func GetActiveBan(accountID int) (*AccountBan, error) {
+    query := `
+        SELECT bandate, unbandate, bannedby, banreason FROM account_banned
+        WHERE id = ? AND active = 1 AND (unbandate = bandate OR unbandate > UNIX_TIMESTAMP())
+        ORDER BY bandate DESC LIMIT 1
+    `
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = `
+            SELECT banned_at, expires_at, banned_by, reason FROM account_banned
+            WHERE account_id = ? AND active = 1 AND (expires_at = banned_at OR expires_at > UNIX_TIMESTAMP())
+            ORDER BY banned_at DESC LIMIT 1
+        `
+    }
+    
+    var bannedAt, until int64
+    var by, reason string
+    err := DB.QueryRow(query, accountID).Scan(&bannedAt, &until, &by, &reason)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return banFromRow(accountID, bannedAt, until, by, reason, true), nil
+}
+
+// GetBanHistory — все баны аккаунта, новые сверху
+func GetBanHistory(accountID, limit int) ([]AccountBan, error) {
+    query := `
+        SELECT bandate, unbandate, bannedby, banreason, active FROM account_banned
+        WHERE id = ? ORDER BY bandate DESC LIMIT ?
+    `
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = `
+            SELECT banned_at, expires_at, banned_by, reason, active FROM account_banned
+            WHERE account_id = ? ORDER BY banned_at DESC LIMIT ?
+        `
+    }
+    
+    rows, err := DB.Query(query, accountID, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var bans []AccountBan
+    for rows.Next() {
+        var bannedAt, until int64
+        var by, reason string
+        var active bool
+        if err := rows.Scan(&bannedAt, &until, &by, &reason, &active); err != nil {
+            return nil, err
+        }
+        bans = append(bans, *banFromRow(accountID, bannedAt, until, by, reason, active))
+    }
+    return bans, rows.Err()
+}
+
+// GetActiveAccountBans — действующие баны аккаунтов с именами, новые сверху
+func GetActiveAccountBans(limit int) ([]AccountBan, error) {
+    query := `
+        SELECT b.id, a.username, b.bandate, b.unbandate, b.bannedby, b.banreason
+        FROM account_banned b JOIN account a ON a.id = b.id
+        WHERE b.active = 1 AND (b.unbandate = b.bandate OR b.unbandate > UNIX_TIMESTAMP())
+        ORDER BY b.bandate DESC LIMIT ?
+    `
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = `
+            SELECT b.account_id, a.username, b.banned_at, b.expires_at, b.banned_by, b.reason
+            FROM account_banned b JOIN account a ON a.id = b.account_id
+            WHERE b.active = 1 AND (b.expires_at = b.banned_at OR b.expires_at > UNIX_TIMESTAMP())
+            ORDER BY b.banned_at DESC LIMIT ?
+        `
+    }
+    
+    rows, err := DB.Query(query, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var bans []AccountBan
+    for rows.Next() {
+        var accountID int
+        var username, by, reason string
+        var bannedAt, until int64
+        if err := rows.Scan(&accountID, &username, &bannedAt, &until, &by, &reason); err != nil {
+            return nil, err
+        }
+        ban := banFromRow(accountID, bannedAt, until, by, reason, true)
+        ban.Username = username
+        bans = append(bans, *ban)
+    }
+    return bans, rows.Err()
+}
+
+// BanAccount снимает прежние баны и записывает новый; duration 0 — бессрочно.
+// authserver проверяет account_banned при входе, так что бан действует сразу
+func BanAccount(accountID int, duration time.Duration, bannedBy, reason string) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    now := time.Now().Unix()
+    until := now + int64(duration/time.Second)
+    
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        if _, err := tx.Exec("UPDATE account_banned SET active = 0, unbanned_at = ?, unbanned_by = ? WHERE account_id = ? AND active = 1", now, bannedBy, accountID); err != nil {
+            return err
+        }
+        _, err = tx.Exec(
+            "INSERT INTO account_banned (account_id, banned_at, expires_at, banned_by, reason, active) VALUES (?, ?, ?, ?, ?, 1)",
+            accountID, now, until, bannedBy, reason,
+        )
+    } else {
+        if _, err := tx.Exec("UPDATE account_banned SET active = 0 WHERE id = ? AND active = 1", accountID); err != nil {
+            return err
+        }
+        _, err = tx.Exec(
+            "INSERT INTO account_banned (id, bandate, unbandate, bannedby, banreason, active) VALUES (?, ?, ?, ?, ?, 1)",
+            accountID, now, until, bannedBy, reason,
+        )
+    }
+    if err != nil {
+        return err
+    }
+    
+    return tx.Commit()
+}
+
+// UnbanAccount снимает действующие баны; false — снимать было нечего
+func UnbanAccount(accountID int, unbannedBy string) (bool, error) {
+    var result sql.Result
+    var err error
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        result, err = DB.Exec("UPDATE account_banned SET active = 0, unbanned_at = UNIX_TIMESTAMP(), unbanned_by = ? WHERE account_id = ? AND active = 1", unbannedBy, accountID)
+    } else {
+        result, err = DB.Exec("UPDATE account_banned SET active = 0 WHERE id = ? AND active = 1", accountID)
+    }
+    if err != nil {
+        return false, err
+    }
+    
+    affected, err := result.RowsAffected()
+    return affected > 0, err
+}
+
+func ipBanColumns() (bannedAt, until, by, reason string) {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return "banned_at", "expires_at", "banned_by", "reason"
+    }
+    return "bandate", "unbandate", "bannedby", "banreason"
+}
+
+// GetActiveIPBan — действующий бан адреса или nil
+func GetActiveIPBan(ip string) (*IPBan, error) {
+    bannedAt, until, by, reason := ipBanColumns()
+    query := "SELECT " + bannedAt + ", " + until + ", " + by + ", " + reason + " FROM ip_banned" +
+        " WHERE ip = ? AND (" + until + " = " + bannedAt + " OR " + until + " > UNIX_TIMESTAMP())" +
+        " ORDER BY " + bannedAt + " DESC LIMIT 1"
+    
+    var from, to int64
+    ban := &IPBan{IP: ip}
+    err := DB.QueryRow(query, ip).Scan(&from, &to, &ban.BannedBy, &ban.Reason)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    ban.BannedAt = time.Unix(from, 0)
+    ban.Until = banUntil(from, to)
+    return ban, nil
+}
+
+// GetActiveIPBans — действующие баны адресов, новые сверху
+func GetActiveIPBans(limit int) ([]IPBan, error) {
+    bannedAt, until, by, reason := ipBanColumns()
+    query := "SELECT ip, " + bannedAt + ", " + until + ", " + by + ", " + reason + " FROM ip_banned" +
+        " WHERE " + until + " = " + bannedAt + " OR " + until + " > UNIX_TIMESTAMP()" +
+        " ORDER BY " + bannedAt + " DESC, ip LIMIT ?"
+    
+    rows, err := DB.Query(query, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var bans []IPBan
+    for rows.Next() {
+        var ban IPBan
+        var from, to int64
+        if err := rows.Scan(&ban.IP, &from, &to, &ban.BannedBy, &ban.Reason); err != nil {
+            return nil, err
+        }
+        ban.BannedAt = time.Unix(from, 0)
+        ban.Until = banUntil(from, to)
+        bans = append(bans, ban)
+    }
+    return bans, rows.Err()
+}
+
+// BanIPs банит адреса одной транзакцией; прежние баны этих адресов заменяются.
+// Истории у ip_banned нет ни в одном ядре
+func BanIPs(ips []string, duration time.Duration, bannedBy, reason string) error {
+    bannedAt, until, by, reasonColumn := ipBanColumns()
+    
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    now := time.Now().Unix()
+    expires := now + int64(duration/time.Second)
+    insert := "INSERT INTO ip_banned (ip, " + bannedAt + ", " + until + ", " + by + ", " + reasonColumn + ") VALUES (?, ?, ?, ?, ?)"
+    for _, ip := range ips {
+        if _, err := tx.Exec("DELETE FROM ip_banned WHERE ip = ?", ip); err != nil {
+            return err
+        }
+        if _, err := tx.Exec(insert, ip, now, expires, bannedBy, reason); err != nil {
+            return err
+        }
+    }
+    
+    return tx.Commit()
+}
+
+// UnbanIP удаляет бан адреса; false — снимать было нечего
+func UnbanIP(ip string) (bool, error) {
+    result, err := DB.Exec("DELETE FROM ip_banned WHERE ip = ?", ip)
+    if err != nil {
+        return false, err
+    }
+    
+    affected, err := result.RowsAffected()
+    return affected > 0, err
+}
+
+// GetActiveCharacterBans — действующие баны персонажей реалма
+func GetActiveCharacterBans(realmID, limit int) ([]CharacterBan, error) {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos банит только аккаунты
+        return nil, ErrCharacterBansUnsupported
+    }
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    rows, err := db.Query(`
+        SELECT b.guid, c.name, b.bandate, b.unbandate, b.bannedby, b.banreason
+        FROM character_banned b JOIN characters c ON c.guid = b.guid
+        WHERE b.active = 1 AND (b.unbandate = b.bandate OR b.unbandate > UNIX_TIMESTAMP())
+        ORDER BY b.bandate DESC LIMIT ?
+    `, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var bans []CharacterBan
+    for rows.Next() {
+        ban := CharacterBan{RealmID: realmID}
+        var from, to int64
+        if err := rows.Scan(&ban.GUID, &ban.Name, &from, &to, &ban.BannedBy, &ban.Reason); err != nil {
+            return nil, err
+        }
+        ban.BannedAt = time.Unix(from, 0)
+        ban.Until = banUntil(from, to)
+        bans = append(bans, ban)
+    }
+    return bans, rows.Err()
+}
+
+// BanCharacter снимает прежние баны персонажа и записывает новый
+func BanCharacter(realmID, guid int, duration time.Duration, bannedBy, reason string) error {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return ErrCharacterBansUnsupported
+    }
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return err
+    }
+    
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    now := time.Now().Unix()
+    if _, err := tx.Exec("UPDATE character_banned SET active = 0 WHERE guid = ? AND active = 1", guid); err != nil {
+        return err
+    }
+    if _, err := tx.Exec(
+        "INSERT INTO character_banned (guid, bandate, unbandate, bannedby, banreason, active) VALUES (?, ?, ?, ?, ?, 1)",
+        guid, now, now+int64(duration/time.Second), bannedBy, reason,
+    ); err != nil {
+        return err
+    }
+    
+    return tx.Commit()
+}
+
+// UnbanCharacter снимает действующие баны персонажа; false — снимать было нечего
+func UnbanCharacter(realmID, guid int) (bool, error) {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return false, ErrCharacterBansUnsupported
+    }
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return false, err
+    }
+    
+    result, err := db.Exec("UPDATE character_banned SET active = 0 WHERE guid = ? AND active = 1", guid)
+    if err != nil {
+        return false, err
+    }
+    
+    affected, err := result.RowsAffected()
+    return affected > 0, err
+}
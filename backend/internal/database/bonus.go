@@ -0,0 +1,180 @@
+package database
+
+import (
+    "fmt"
+    "time"
+)
+
+// Статусы web_registration_bonus
+const (
+    BonusWaiting    = "waiting"
+    BonusDelivering = "delivering"
+    BonusDone       = "done"
+)
+
+// Состояния частей бонуса: sent ставится до отправки, так что после падения
+// часть не уйдет второй раз
+const (
+    BonusStepPending = "pending"
+    BonusStepSent    = "sent"
+    BonusStepSkipped = "skipped"
+)
+
+var bonusStepColumns = map[string]string{
+    "money":  "money_state",
+    "items":  "items_state",
+    "spells": "spells_state",
+}
+
+type RegistrationBonus struct {
+    AccountID     int
+    Status        string
+    RealmID       int
+    GUID          int
+    CharacterName string
+    Steps         map[string]string
+    ItemsSent     int
+}
+
+func CreateRegistrationBonus(accountID int) error {
+    _, err := DB.Exec("INSERT IGNORE INTO web_registration_bonus (account_id, created_at) VALUES (?, NOW())", accountID)
+    return err
+}
+
+// GetOpenBonuses — бонусы, которые ждут персонажа или доставки и чья очередь
+// проверки подошла
+func GetOpenBonuses(limit int) ([]RegistrationBonus, error) {
+    query := `
+        SELECT account_id, status, realm_id, guid, character_name, money_state, items_state, spells_state, items_sent
+        FROM web_registration_bonus
+        WHERE status IN (?, ?) AND next_check_at <= NOW()
+        ORDER BY next_check_at
+        LIMIT ?
+    `
+    
+    rows, err := DB.Query(query, BonusWaiting, BonusDelivering, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var bonuses []RegistrationBonus
+    for rows.Next() {
+        var b RegistrationBonus
+        var money, items, spells string
+        if err := rows.Scan(&b.AccountID, &b.Status, &b.RealmID, &b.GUID, &b.CharacterName, &money, &items, &spells, &b.ItemsSent); err != nil {
+            return nil, err
+        }
+        b.Steps = map[string]string{"money": money, "items": items, "spells": spells}
+        bonuses = append(bonuses, b)
+    }
+    return bonuses, rows.Err()
+}
+
+// PostponeBonuses откладывает следующую проверку бонусов на delay, а для
+// аккаунтов старше суток — на idleDelay
+func PostponeBonuses(accountIDs []int, delay, idleDelay time.Duration) error {
+    if len(accountIDs) == 0 {
+        return nil
+    }
+    
+    args := []interface{}{int64(idleDelay / time.Second), int64(delay / time.Second)}
+    for _, id := range accountIDs {
+        args = append(args, id)
+    }
+    _, err := DB.Exec(`
+        UPDATE web_registration_bonus
+        SET next_check_at = NOW() + INTERVAL IF(created_at < NOW() - INTERVAL 1 DAY, ?, ?) SECOND
+        WHERE account_id IN (`+sqlPlaceholders(len(accountIDs))+`)
+    `, args...)
+    return err
+}
+
+// FindFirstCharacters — персонаж с наименьшим GUID у каждого из аккаунтов на
+// реалме. GUID выдаются по порядку, так что это первый созданный на этом реалме
+func FindFirstCharacters(realmID int, accountIDs []int) (map[int]Character, error) {
+    first := make(map[int]Character)
+    if len(accountIDs) == 0 {
+        return first, nil
+    }
+    
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    args := make([]interface{}, len(accountIDs))
+    for i, id := range accountIDs {
+        args[i] = id
+    }
+    query := `
+        SELECT c.account, c.guid, c.name
+        FROM characters c
+        JOIN (
+            SELECT account, MIN(guid) AS guid FROM characters
+            WHERE account IN (` + sqlPlaceholders(len(accountIDs)) + `)
+            GROUP BY account
+        ) f ON f.guid = c.guid
+    `
+    
+    rows, err := db.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    for rows.Next() {
+        var account int
+        c := Character{RealmID: realmID}
+        if err := rows.Scan(&account, &c.GUID, &c.Name); err != nil {
+            return nil, err
+        }
+        first[account] = c
+    }
+    return first, rows.Err()
+}
+
+// AssignBonusCharacter закрепляет бонус за персонажем; false — уже закреплен
+func AssignBonusCharacter(accountID int, character Character) (bool, error) {
+    result, err := DB.Exec(`
+        UPDATE web_registration_bonus
+        SET status = ?, realm_id = ?, guid = ?, character_name = ?
+        WHERE account_id = ? AND status = ?
+    `, BonusDelivering, character.RealmID, character.GUID, character.Name, accountID, BonusWaiting)
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+// SetBonusStep переводит часть бонуса из from в to; false — ее уже кто-то перевел
+func SetBonusStep(accountID int, step, from, to string) (bool, error) {
+    column, ok := bonusStepColumns[step]
+    if !ok {
+        return false, fmt.Errorf("unknown bonus step %q", step)
+    }
+    
+    result, err := DB.Exec(
+        "UPDATE web_registration_bonus SET "+column+" = ? WHERE account_id = ? AND "+column+" = ?",
+        to, accountID, from,
+    )
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+// SetBonusItemsSent запоминает, сколько предметов уже ушло письмами
+func SetBonusItemsSent(accountID, sent int) error {
+    _, err := DB.Exec("UPDATE web_registration_bonus SET items_sent = ? WHERE account_id = ?", sent, accountID)
+    return err
+}
+
+// UpdateBonusStatus меняет статус и запоминает последнюю ошибку доставки
+func UpdateBonusStatus(accountID int, status, message string) error {
+    message = TruncateText(message, 255)
+    _, err := DB.Exec("UPDATE web_registration_bonus SET status = ?, error = ? WHERE account_id = ?", status, message, accountID)
+    return err
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+    "database/sql"
+    "strconv"
+    "time"
+)
+
+// Статусы web_character_actions
+const (
+    ActionPending = "pending"
+    ActionDone    = "done"
+    ActionFailed  = "failed"
+    ActionReview  = "review"
+)
+
+// IsCharacterOnline — в игре ли персонаж прямо сейчас
+func IsCharacterOnline(realmID, guid int) (bool, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return false, err
+    }
+    
+    var online bool
+    err = db.QueryRow("SELECT online FROM characters WHERE guid = ?", guid).Scan(&online)
+    return online, err
+}
+
+// GetCharacterLevel — текущий уровень персонажа
+func GetCharacterLevel(realmID, guid int) (int, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return 0, err
+    }
+    
+    var level int
+    err = db.QueryRow("SELECT level FROM characters WHERE guid = ?", guid).Scan(&level)
+    return level, err
+}
+
+// CharacterActionCooldown — сколько еще ждать до повтора действия после последнего
+// не проваленного. Считается в SQL: created_at пишется через NOW(), и сравнивать
+// его с часами Go нельзя, пока DSN и сервер MySQL живут в разных часовых поясах
+func CharacterActionCooldown(realmID, guid int, action string, cooldown time.Duration) (time.Duration, error) {
+    var remaining sql.NullInt64
+    query := `
+        SELECT TIMESTAMPDIFF(SECOND, NOW(), MAX(created_at) + INTERVAL ? SECOND) FROM web_character_actions
+        WHERE realm_id = ? AND guid = ? AND action = ? AND status <> ?
+    `
+    if err := DB.QueryRow(query, int64(cooldown/time.Second), realmID, guid, action, ActionFailed).Scan(&remaining); err != nil {
+        return 0, err
+    }
+    if !remaining.Valid || remaining.Int64 <= 0 {
+        return 0, nil
+    }
+    return time.Duration(remaining.Int64) * time.Second, nil
+}
+
+func characterActionReference(id int64) string {
+    return "chartool:" + strconv.FormatInt(id, 10)
+}
+
+// CreateCharacterAction заводит действие и списывает его стоимость одной
+// транзакцией: действие без оплаты или оплата без действия не остаются
+func CreateCharacterAction(accountID, realmID, guid int, action string, cost int) (int64, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback()
+    
+    result, err := tx.Exec(`
+        INSERT INTO web_character_actions (account_id, realm_id, guid, action, cost, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, NOW())
+    `, accountID, realmID, guid, action, cost, ActionPending)
+    if err != nil {
+        return 0, err
+    }
+    id, err := result.LastInsertId()
+    if err != nil {
+        return 0, err
+    }
+    
+    if cost > 0 {
+        entry := &PointsEntry{AccountID: accountID, Amount: -cost, Kind: PointsPurchase, Reference: characterActionReference(id)}
+        if err := recordPointsTx(tx, entry); err != nil {
+            return 0, err
+        }
+    }
+    return id, tx.Commit()
+}
+
+// RefundCharacterAction помечает незавершенное действие проваленным и
+// возвращает его стоимость в той же транзакции
+func RefundCharacterAction(id int64, result string) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    var accountID, cost int
+    err = tx.QueryRow(
+        "SELECT account_id, cost FROM web_character_actions WHERE id = ? AND status = ? FOR UPDATE", id, ActionPending,
+    ).Scan(&accountID, &cost)
+    if err == sql.ErrNoRows {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    
+    if _, err := tx.Exec(
+        "UPDATE web_character_actions SET status = ?, result = ? WHERE id = ?", ActionFailed, TruncateText(result, 255), id,
+    ); err != nil {
+        return err
+    }
+    if cost > 0 {
+        entry := &PointsEntry{AccountID: accountID, Amount: cost, Kind: PointsRefund, Reference: characterActionReference(id)}
+        if err := recordPointsTx(tx, entry); err != nil {
+            return err
+        }
+    }
+    return tx.Commit()
+}
+
+// ReviewStaleCharacterActions переводит в review действия, которые дольше age
+// остаются pending, и возвращает их число
+func ReviewStaleCharacterActions(age time.Duration, result string) (int64, error) {
+    res, err := DB.Exec(`
+        UPDATE web_character_actions SET status = ?, result = ?
+        WHERE status = ? AND created_at < NOW() - INTERVAL ? SECOND
+    `, ActionReview, TruncateText(result, 255), ActionPending, int64(age/time.Second))
+    if err != nil {
+        return 0, err
+    }
+    return res.RowsAffected()
+}
+
+func FinishCharacterAction(id int64, status, result string) error {
+    result = TruncateText(result, 255)
+    _, err := DB.Exec("UPDATE web_character_actions SET status = ?, result = ? WHERE id = ?", status, result, id)
+    return err
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+    "strings"
+)
+
+type CharacterFilter struct {
+    Races    []int
+    Class    int
+    MinLevel int
+    MaxLevel int
+    Sort     string
+    Desc     bool
+    Limit    int
+    Offset   int
+}
+
+// Разрешенные поля сортировки -> колонки characters
+var characterSortColumns = map[string]string{
+    "name":  "name",
+    "level": "level",
+    "race":  "race",
+    "class": "class",
+    "zone":  "zone",
+}
+
+// ListOnlineCharacters возвращает страницу онлайн-персонажей и общее число под фильтром
+func ListOnlineCharacters(realmID int, f CharacterFilter) ([]Character, int, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, 0, err
+    }
+    
+    where := []string{"online = 1"}
+    var args []interface{}
+    
+    if len(f.Races) > 0 {
+        placeholders := make([]string, len(f.Races))
+        for i, race := range f.Races {
+            placeholders[i] = "?"
+            args = append(args, race)
+        }
+        where = append(where, "race IN ("+strings.Join(placeholders, ", ")+")")
+    }
+    if f.Class > 0 {
+        where = append(where, "class = ?")
+        args = append(args, f.Class)
+    }
+    if f.MinLevel > 0 {
+        where = append(where, "level >= ?")
+        args = append(args, f.MinLevel)
+    }
+    if f.MaxLevel > 0 {
+        where = append(where, "level <= ?")
+        args = append(args, f.MaxLevel)
+    }
+    whereSQL := strings.Join(where, " AND ")
+    
+    var total int
+    if err := db.QueryRow("SELECT COUNT(*) FROM characters WHERE "+whereSQL, args...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
+    
+    column, ok := characterSortColumns[f.Sort]
+    if !ok {
+        column = "level"
+    }
+    direction := "ASC"
+    if f.Desc {
+        direction = "DESC"
+    }
+    if f.Limit <= 0 {
+        f.Limit = 50
+    }
+    
+    query := `
+        SELECT guid, name, race, class, level, gender, zone
+        FROM characters
+        WHERE ` + whereSQL + `
+        ORDER BY ` + column + ` ` + direction + `, name ASC
+        LIMIT ? OFFSET ?
+    `
+    
+    rows, err := db.Query(query, append(args, f.Limit, f.Offset)...)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+    
+    var characters []Character
+    for rows.Next() {
+        var c Character
+        if err := rows.Scan(&c.GUID, &c.Name, &c.Race, &c.Class, &c.Level, &c.Gender, &c.Zone); err != nil {
+            return nil, 0, err
+        }
+        c.RealmID = realmID
+        characters = append(characters, c)
+    }
+    
+    return characters, total, rows.Err()
+}
@@ -4,6 +4,7 @@ import (
     "database/sql"
     "fmt"
     "log"
+    "strings"
     "time"
     "wow-registration/internal/config"
     _ "github.com/go-sql-driver/mysql"
@@ -11,8 +12,9 @@ import (
 )
 
 var (
-    DB    *sql.DB
-    Redis *redis.Client
+    DB      *sql.DB
+    WorldDB *sql.DB
+    Redis   *redis.Client
 )
 
 type Account struct {
@@ -36,6 +38,7 @@ type Character struct {
     Class   int
     Level   int
     Gender  int
+    Zone    int
     RealmID int
 }
 
@@ -43,31 +46,18 @@ func Connect() error {
     cfg := config.AppConfig
     
     // MySQL connection
-    dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=true",
-        cfg.Database.User,
-        cfg.Database.Password,
+    var err error
+    DB, err = openMySQL(
         cfg.Database.Host,
         cfg.Database.Port,
+        cfg.Database.User,
+        cfg.Database.Password,
         cfg.Database.Name,
-        cfg.Database.Charset,
     )
-    
-    var err error
-    DB, err = sql.Open("mysql", dsn)
     if err != nil {
-        return fmt.Errorf("failed to connect to database: %w", err)
+        return err
     }
     
-    // Test connection
-    if err := DB.Ping(); err != nil {
-        return fmt.Errorf("database ping failed: %w", err)
-    }
-    
-    // Set connection pool settings
-    DB.SetMaxOpenConns(25)
-    DB.SetMaxIdleConns(5)
-    DB.SetConnMaxLifetime(5 * time.Minute)
-    
     // Redis connection
     Redis = redis.NewClient(&redis.Options{
         Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
@@ -75,10 +65,74 @@ func Connect() error {
         DB:       cfg.Redis.DB,
     })
     
+    // Базы персонажей для каждого реалма
+    if err := ConnectRealms(); err != nil {
+        return err
+    }
+    
+    // World база опциональна: без нее армори покажет предметы без названий
+    if cfg.Database.WorldName != "" {
+        WorldDB, err = openMySQL(
+            cfg.Database.WorldHost,
+            cfg.Database.WorldPort,
+            cfg.Database.WorldUser,
+            cfg.Database.WorldPassword,
+            cfg.Database.WorldName,
+        )
+        if err != nil {
+            log.Printf("⚠️  World database unavailable: %v", err)
+        }
+    }
+    
     log.Println("✅ Database connections established")
     return nil
 }
 
+func openMySQL(host, port, user, password, name string) (*sql.DB, error) {
+    dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=true",
+        user,
+        password,
+        host,
+        port,
+        name,
+        config.AppConfig.Database.Charset,
+    )
+    
+    db, err := sql.Open("mysql", dsn)
+    if err != nil {
+        return nil, fmt.Errorf("failed to connect to database %s: %w", name, err)
+    }
+    
+    // Test connection
+    if err := db.Ping(); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("database %s ping failed: %w", name, err)
+    }
+    
+    // Set connection pool settings
+    db.SetMaxOpenConns(25)
+    db.SetMaxIdleConns(5)
+    db.SetConnMaxLifetime(5 * time.Minute)
+    
+    return db, nil
+}
+
+// Close закрывает все открытые соединения с базами
+func Close() {
+    for _, db := range charDBs {
+        db.Close()
+    }
+    if WorldDB != nil {
+        WorldDB.Close()
+    }
+    if Redis != nil {
+        Redis.Close()
+    }
+    if DB != nil {
+        DB.Close()
+    }
+}
+
 func CreateAccount(account *Account) error {
     query := `
         INSERT INTO account (
@@ -87,7 +141,7 @@ func CreateAccount(account *Account) error {
         ) VALUES (?, ?, ?, ?, ?, NOW(), '', ?, ?, ?)
     `
     
-    _, err := DB.Exec(query,
+    result, err := DB.Exec(query,
         account.Username,
         account.Email,
         account.Password,
@@ -97,7 +151,12 @@ func CreateAccount(account *Account) error {
         account.Salt,
         account.Locked,
     )
+    if err != nil {
+        return err
+    }
     
+    id, err := result.LastInsertId()
+    account.ID = int(id)
     return err
 }
 
@@ -141,6 +200,31 @@ func GetAccountByUsername(username string) (*Account, error) {
     return account, nil
 }
 
+// GetAccountCredentials — аккаунт вместе с хэшем пароля и SRP6 для проверки входа
+func GetAccountCredentials(username string) (*Account, error) {
+    query := `
+        SELECT id, username, email, COALESCE(sha_pass_hash, ''), COALESCE(s, ''), COALESCE(v, ''), locked
+        FROM account WHERE username = ?
+    `
+    
+    account := &Account{}
+    err := DB.QueryRow(query, username).Scan(
+        &account.ID,
+        &account.Username,
+        &account.Email,
+        &account.Password,
+        &account.Salt,
+        &account.Verifier,
+        &account.Locked,
+    )
+    
+    if err != nil {
+        return nil, err
+    }
+    
+    return account, nil
+}
+
 func UpdatePassword(username, newHash string) error {
     query := `
         UPDATE account 
@@ -152,35 +236,28 @@ func UpdatePassword(username, newHash string) error {
     return err
 }
 
+// GetOnlinePlayers — короткий список для главной страницы
 func GetOnlinePlayers(realmID int) ([]Character, error) {
-    query := `
-        SELECT guid, name, race, class, level, gender
-        FROM characters 
-        WHERE online = 1 AND realm = ?
-        ORDER BY level DESC
-        LIMIT 20
-    `
-    
-    rows, err := DB.Query(query, realmID)
+    characters, _, err := ListOnlineCharacters(realmID, CharacterFilter{
+        Sort:  "level",
+        Desc:  true,
+        Limit: 20,
+    })
+    return characters, err
+}
+
+func CountOnlinePlayers(realmID int) (int, error) {
+    db, err := CharsDB(realmID)
     if err != nil {
-        return nil, err
+        return 0, err
     }
-    defer rows.Close()
     
-    var characters []Character
-    for rows.Next() {
-        var c Character
-        if err := rows.Scan(&c.GUID, &c.Name, &c.Race, &c.Class, &c.Level, &c.Gender); err != nil {
-            return nil, err
-        }
-        c.RealmID = realmID
-        characters = append(characters, c)
-    }
-    
-    return characters, nil
+    var count int
+    err = db.QueryRow("SELECT COUNT(*) FROM characters WHERE online = 1").Scan(&count)
+    return count, err
 }
 
-func GetServerStats() (map[string]interface{}, error) {
+func GetServerStats(realmID int) (map[string]interface{}, error) {
     stats := make(map[string]interface{})
     
     // Total accounts
@@ -199,8 +276,28 @@ func GetServerStats() (map[string]interface{}, error) {
     }
     stats["today_registrations"] = todayRegistrations
     
-    // Online players (from characters DB - нужно отдельное соединение)
-    stats["online_players"] = 0 // Заполняется отдельно
+    // Online players (из базы персонажей реалма)
+    onlinePlayers, err := CountOnlinePlayers(realmID)
+    if err != nil {
+        return nil, err
+    }
+    stats["online_players"] = onlinePlayers
+    stats["realm_id"] = realmID
     
     return stats, nil
 }
+
+// TruncateText обрезает s до n символов под колонку VARCHAR(n): utf8mb4 считает
+// символы, а срез по байтам может разрезать многобайтный символ, и такую строку
+// MySQL в строгом режиме отвергнет. Битые последовательности заменяются на U+FFFD
+func TruncateText(s string, n int) string {
+    s = strings.ToValidUTF8(s, "�")
+    count := 0
+    for i := range s {
+        if count == n {
+            return s[:i]
+        }
+        count++
+    }
+    return s
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+    "testing"
+    "unicode/utf8"
+)
+
+func TestTruncateText(t *testing.T) {
+    tests := []struct {
+        in   string
+        n    int
+        want string
+    }{
+        {"short", 10, "short"},
+        {"exactly", 7, "exactly"},
+        {"Причина бана", 7, "Причина"},
+        {"gold 💰💰💰", 6, "gold 💰"},
+        {"bad \xff byte", 5, "bad �"},
+    }
+    for _, tt := range tests {
+        got := TruncateText(tt.in, tt.n)
+        if got != tt.want || !utf8.ValidString(got) {
+            t.Errorf("TruncateText(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+    "time"
+)
+
+type Donation struct {
+    ID        int64     `json:"id"`
+    AccountID int       `json:"-"`
+    Provider  string    `json:"provider"`
+    Reference string    `json:"reference"`
+    PaymentID string    `json:"-"`
+    Amount    int64     `json:"amount"`
+    Currency  string    `json:"currency"`
+    Points    int       `json:"points"`
+    Status    string    `json:"status"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+const donationColumns = "id, account_id, provider, reference, payment_id, amount, currency, points, status, created_at"
+
+func scanDonation(row interface{ Scan(...interface{}) error }) (*Donation, error) {
+    d := &Donation{}
+    err := row.Scan(&d.ID, &d.AccountID, &d.Provider, &d.Reference, &d.PaymentID, &d.Amount, &d.Currency, &d.Points, &d.Status, &d.CreatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return d, nil
+}
+
+func CreateDonation(d *Donation) error {
+    d.CreatedAt = time.Now()
+    result, err := DB.Exec(`
+        INSERT INTO web_donations (account_id, provider, reference, amount, currency, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `, d.AccountID, d.Provider, d.Reference, d.Amount, d.Currency, d.Status, d.CreatedAt)
+    if err != nil {
+        return err
+    }
+    d.ID, err = result.LastInsertId()
+    return err
+}
+
+func GetDonationByReference(reference string) (*Donation, error) {
+    return scanDonation(DB.QueryRow("SELECT "+donationColumns+" FROM web_donations WHERE reference = ?", reference))
+}
+
+// GetAccountDonations — пожертвования аккаунта, новые первыми
+func GetAccountDonations(accountID, limit int) ([]Donation, error) {
+    rows, err := DB.Query("SELECT "+donationColumns+" FROM web_donations WHERE account_id = ? ORDER BY id DESC LIMIT ?", accountID, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var donations []Donation
+    for rows.Next() {
+        d, err := scanDonation(rows)
+        if err != nil {
+            return nil, err
+        }
+        donations = append(donations, *d)
+    }
+    return donations, rows.Err()
+}
+
+// TransitionDonation переводит платеж из d.Status в to, пишет событие и, если
+// задан points, движение по поинтам — все в одной транзакции. false — статус
+// успел смениться параллельным уведомлением, d нужно перечитать
+func TransitionDonation(d *Donation, to, paymentID string, amount int64, points *PointsEntry) (bool, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return false, err
+    }
+    defer tx.Rollback()
+    
+    var current string
+    if err := tx.QueryRow("SELECT status FROM web_donations WHERE id = ? FOR UPDATE", d.ID).Scan(&current); err != nil {
+        return false, err
+    }
+    if current != d.Status {
+        return false, nil
+    }
+    
+    credited := d.Points
+    delta := 0
+    if points != nil {
+        if err := recordPointsTx(tx, points); err != nil {
+            return false, err
+        }
+        delta = points.Amount
+        if delta > 0 {
+            credited = delta
+        }
+    }
+    
+    if paymentID == "" {
+        paymentID = d.PaymentID
+    }
+    _, err = tx.Exec(
+        "UPDATE web_donations SET status = ?, payment_id = ?, amount = ?, points = ? WHERE id = ?",
+        to, paymentID, amount, credited, d.ID,
+    )
+    if err != nil {
+        return false, err
+    }
+    _, err = tx.Exec(`
+        INSERT INTO web_donation_events (donation_id, from_status, to_status, payment_id, amount, points, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, NOW())
+    `, d.ID, d.Status, to, paymentID, amount, delta)
+    if err != nil {
+        return false, err
+    }
+    
+    if err := tx.Commit(); err != nil {
+        return false, err
+    }
+    d.Status, d.PaymentID, d.Amount, d.Points = to, paymentID, amount, credited
+    return true, nil
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+    "database/sql"
+    "strings"
+    "time"
+)
+
+type Guild struct {
+    ID         int
+    Name       string
+    LeaderGUID int
+    LeaderName string
+    Info       string
+    MOTD       string
+    CreatedAt  time.Time
+    Members    int
+    RealmID    int
+}
+
+type GuildMember struct {
+    Character
+    Rank     int
+    RankName string
+    Online   bool
+}
+
+type GuildFilter struct {
+    Name   string
+    Limit  int
+    Offset int
+}
+
+// Настройки по умолчанию: MOTD обычно пишется для своих, описание — для всех
+type GuildSettings struct {
+    ShowMOTD bool
+    ShowInfo bool
+}
+
+var DefaultGuildSettings = GuildSettings{ShowMOTD: false, ShowInfo: true}
+
+const guildColumns = `
+    g.guildid, g.name, g.leaderguid, COALESCE(l.name, ''), g.info, g.motd, g.createdate,
+    (SELECT COUNT(*) FROM guild_member m WHERE m.guildid = g.guildid)
+`
+
+func scanGuild(scanner interface{ Scan(...interface{}) error }, realmID int) (*Guild, error) {
+    g := &Guild{RealmID: realmID}
+    var created int64
+    err := scanner.Scan(&g.ID, &g.Name, &g.LeaderGUID, &g.LeaderName, &g.Info, &g.MOTD, &created, &g.Members)
+    if err != nil {
+        return nil, err
+    }
+    g.CreatedAt = time.Unix(created, 0)
+    return g, nil
+}
+
+func GetGuildByName(realmID int, name string) (*Guild, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT ` + guildColumns + `
+        FROM guild g
+        LEFT JOIN characters l ON l.guid = g.leaderguid
+        WHERE g.name = ?
+    `
+    return scanGuild(db.QueryRow(query, name), realmID)
+}
+
+func GetGuildByID(realmID, guildID int) (*Guild, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT ` + guildColumns + `
+        FROM guild g
+        LEFT JOIN characters l ON l.guid = g.leaderguid
+        WHERE g.guildid = ?
+    `
+    return scanGuild(db.QueryRow(query, guildID), realmID)
+}
+
+// SearchGuilds — поиск гильдий реалма по подстроке в названии, крупные первыми
+func SearchGuilds(realmID int, f GuildFilter) ([]Guild, int, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, 0, err
+    }
+    
+    where := "1 = 1"
+    var args []interface{}
+    if f.Name != "" {
+        // Экранируем спецсимволы LIKE, чтобы "%" в запросе не матчил все подряд
+        escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.Name)
+        where = "g.name LIKE ?"
+        args = append(args, "%"+escaped+"%")
+    }
+    
+    var total int
+    if err := db.QueryRow("SELECT COUNT(*) FROM guild g WHERE "+where, args...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
+    
+    if f.Limit <= 0 {
+        f.Limit = 50
+    }
+    
+    query := `
+        SELECT ` + guildColumns + ` AS members
+        FROM guild g
+        LEFT JOIN characters l ON l.guid = g.leaderguid
+        WHERE ` + where + `
+        ORDER BY members DESC, g.name ASC
+        LIMIT ? OFFSET ?
+    `
+    
+    rows, err := db.Query(query, append(args, f.Limit, f.Offset)...)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+    
+    var guilds []Guild
+    for rows.Next() {
+        g, err := scanGuild(rows, realmID)
+        if err != nil {
+            return nil, 0, err
+        }
+        guilds = append(guilds, *g)
+    }
+    return guilds, total, rows.Err()
+}
+
+// GetGuildRoster — состав гильдии с названиями рангов
+func GetGuildRoster(realmID, guildID int) ([]GuildMember, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT c.guid, c.name, c.race, c.class, c.gender, c.level, c.zone, c.online,
+               m.rank, COALESCE(r.rname, '')
+        FROM guild_member m
+        JOIN characters c ON c.guid = m.guid
+        LEFT JOIN guild_rank r ON r.guildid = m.guildid AND r.rid = m.rank
+        WHERE m.guildid = ?
+        ORDER BY m.rank ASC, c.level DESC, c.name ASC
+    `
+    
+    rows, err := db.Query(query, guildID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var members []GuildMember
+    for rows.Next() {
+        m := GuildMember{Character: Character{RealmID: realmID}}
+        if err := rows.Scan(
+            &m.GUID, &m.Name, &m.Race, &m.Class, &m.Gender, &m.Level, &m.Zone, &m.Online,
+            &m.Rank, &m.RankName,
+        ); err != nil {
+            return nil, err
+        }
+        members = append(members, m)
+    }
+    return members, rows.Err()
+}
+
+// GetLeaderGuilds — гильдии, где лидер — персонаж этого аккаунта
+func GetLeaderGuilds(realmID, accountID int) ([]Guild, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    query := `
+        SELECT ` + guildColumns + `
+        FROM guild g
+        JOIN characters l ON l.guid = g.leaderguid
+        WHERE l.account = ?
+        ORDER BY g.name
+    `
+    
+    rows, err := db.Query(query, accountID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var guilds []Guild
+    for rows.Next() {
+        g, err := scanGuild(rows, realmID)
+        if err != nil {
+            return nil, err
+        }
+        guilds = append(guilds, *g)
+    }
+    return guilds, rows.Err()
+}
+
+func GetGuildSettings(realmID, guildID int) (GuildSettings, error) {
+    s := DefaultGuildSettings
+    err := DB.QueryRow(
+        "SELECT show_motd, show_info FROM web_guild_settings WHERE realm_id = ? AND guild_id = ?",
+        realmID, guildID,
+    ).Scan(&s.ShowMOTD, &s.ShowInfo)
+    if err == sql.ErrNoRows {
+        return DefaultGuildSettings, nil
+    }
+    return s, err
+}
+
+func SetGuildSettings(realmID, guildID, accountID int, s GuildSettings) error {
+    query := `
+        INSERT INTO web_guild_settings (realm_id, guild_id, show_motd, show_info, updated_by)
+        VALUES (?, ?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE show_motd = VALUES(show_motd), show_info = VALUES(show_info), updated_by = VALUES(updated_by)
+    `
+    _, err := DB.Exec(query, realmID, guildID, s.ShowMOTD, s.ShowInfo, accountID)
+    return err
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+    "time"
+)
+
+type StatsSample struct {
+    RealmID   int
+    SampledAt time.Time
+    Online    int
+    Alliance  int
+    Horde     int
+}
+
+type OnlinePoint struct {
+    Time     time.Time `json:"t"`
+    Online   float64   `json:"online"`
+    Peak     int       `json:"peak"`
+    Alliance float64   `json:"alliance"`
+    Horde    float64   `json:"horde"`
+}
+
+type HourlyOnline struct {
+    Hour   int     `json:"hour"`
+    Online float64 `json:"online"`
+}
+
+type DailyRegistrations struct {
+    Day   time.Time `json:"day"`
+    Count int       `json:"count"`
+}
+
+// CountOnlineByRace возвращает онлайн реалма в разбивке по расам
+func CountOnlineByRace(realmID int) (map[int]int, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    rows, err := db.Query("SELECT race, COUNT(*) FROM characters WHERE online = 1 GROUP BY race")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    counts := make(map[int]int)
+    for rows.Next() {
+        var race, count int
+        if err := rows.Scan(&race, &count); err != nil {
+            return nil, err
+        }
+        counts[race] = count
+    }
+    return counts, rows.Err()
+}
+
+func InsertStatsSample(s StatsSample) error {
+    query := `
+        INSERT INTO web_stats_samples (realm_id, sampled_at, online, alliance, horde)
+        VALUES (?, ?, ?, ?, ?)
+    `
+    
+    _, err := DB.Exec(query, s.RealmID, s.SampledAt, s.Online, s.Alliance, s.Horde)
+    return err
+}
+
+// RefreshRegistrationHour пересчитывает число регистраций за час, начинающийся в hour
+func RefreshRegistrationHour(hour time.Time) error {
+    query := `
+        INSERT INTO web_registration_stats (hour, registrations)
+        SELECT ?, COUNT(*) FROM account WHERE joindate >= ? AND joindate < ?
+        ON DUPLICATE KEY UPDATE registrations = VALUES(registrations)
+    `
+    
+    _, err := DB.Exec(query, hour, hour, hour.Add(time.Hour))
+    return err
+}
+
+// GetOnlineHistory усредняет снимки онлайна по корзинам размером bucket
+func GetOnlineHistory(realmID int, since time.Time, bucket time.Duration) ([]OnlinePoint, error) {
+    query := `
+        SELECT FLOOR(UNIX_TIMESTAMP(sampled_at) / ?) AS bucket,
+               AVG(online), MAX(online), AVG(alliance), AVG(horde)
+        FROM web_stats_samples
+        WHERE realm_id = ? AND sampled_at >= ?
+        GROUP BY bucket
+        ORDER BY bucket
+    `
+    
+    seconds := int64(bucket / time.Second)
+    rows, err := DB.Query(query, seconds, realmID, since)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var points []OnlinePoint
+    for rows.Next() {
+        var b int64
+        var p OnlinePoint
+        if err := rows.Scan(&b, &p.Online, &p.Peak, &p.Alliance, &p.Horde); err != nil {
+            return nil, err
+        }
+        p.Time = time.Unix(b*seconds, 0)
+        points = append(points, p)
+    }
+    return points, rows.Err()
+}
+
+// GetPeakHours — средний онлайн по часу суток
+func GetPeakHours(realmID int, since time.Time) ([]HourlyOnline, error) {
+    query := `
+        SELECT HOUR(sampled_at), AVG(online)
+        FROM web_stats_samples
+        WHERE realm_id = ? AND sampled_at >= ?
+        GROUP BY HOUR(sampled_at)
+        ORDER BY HOUR(sampled_at)
+    `
+    
+    rows, err := DB.Query(query, realmID, since)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var hours []HourlyOnline
+    for rows.Next() {
+        var h HourlyOnline
+        if err := rows.Scan(&h.Hour, &h.Online); err != nil {
+            return nil, err
+        }
+        hours = append(hours, h)
+    }
+    return hours, rows.Err()
+}
+
+func GetDailyRegistrations(since time.Time) ([]DailyRegistrations, error) {
+    query := `
+        SELECT DATE(hour) AS day, SUM(registrations)
+        FROM web_registration_stats
+        WHERE hour >= ?
+        GROUP BY day
+        ORDER BY day
+    `
+    
+    rows, err := DB.Query(query, since)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var days []DailyRegistrations
+    for rows.Next() {
+        var d DailyRegistrations
+        if err := rows.Scan(&d.Day, &d.Count); err != nil {
+            return nil, err
+        }
+        days = append(days, d)
+    }
+    return days, rows.Err()
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+    "sort"
+    "strconv"
+    "strings"
+    "wow-registration/internal/config"
+)
+
+const (
+    MetricPlaytime     = "playtime"
+    MetricWealth       = "wealth"
+    MetricAchievements = "achievements"
+    MetricFirstLevel   = "first-level"
+)
+
+type LeaderboardQuery struct {
+    Metric          string
+    Class           int
+    ExcludeAccounts []int
+    Limit           int
+    
+    // Для MetricFirstLevel — ID достижения "Level N"
+    Achievement int
+    // Для MetricAchievements — очки по ID достижения
+    AchievementPoints map[int]int
+}
+
+type LeaderboardEntry struct {
+    Character
+    Value int64
+}
+
+// GetGMAccountIDs — аккаунты с gmlevel > 0 на реалме или на всех реалмах
+func GetGMAccountIDs(realmID int) ([]int, error) {
+    query := "SELECT DISTINCT id FROM account_access WHERE gmlevel > 0 AND (RealmID = ? OR RealmID = -1)"
+    args := []interface{}{realmID}
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        query = "SELECT id FROM account WHERE gmlevel > 0"
+        args = nil
+    }
+    
+    rows, err := DB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var ids []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+func sqlPlaceholders(n int) string {
+    return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// achievementPointsSQL сворачивает справочник очков в SUM(CASE ...) по группам
+// с одинаковой стоимостью: справочник живет в auth базе, а JOIN между серверами невозможен
+func achievementPointsSQL(points map[int]int) (string, []interface{}) {
+    groups := make(map[int][]int)
+    for id, p := range points {
+        if p > 0 {
+            groups[p] = append(groups[p], id)
+        }
+    }
+    if len(groups) == 0 {
+        return "0", nil
+    }
+    
+    values := make([]int, 0, len(groups))
+    for p := range groups {
+        values = append(values, p)
+    }
+    sort.Ints(values)
+    
+    var b strings.Builder
+    var args []interface{}
+    b.WriteString("SUM(CASE")
+    for _, p := range values {
+        b.WriteString(" WHEN ca.achievement IN (" + sqlPlaceholders(len(groups[p])) + ") THEN " + strconv.Itoa(p))
+        for _, id := range groups[p] {
+            args = append(args, id)
+        }
+    }
+    b.WriteString(" ELSE 0 END)")
+    return b.String(), args
+}
+
+// GetLeaderboard строит таблицу лидеров по одной метрике прямо из базы персонажей
+func GetLeaderboard(realmID int, q LeaderboardQuery) ([]LeaderboardEntry, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    var valueSQL, fromSQL, groupSQL, order string
+    var args []interface{}
+    
+    switch q.Metric {
+    case MetricPlaytime:
+        valueSQL, fromSQL, order = "c.totaltime", "characters c", "DESC"
+    case MetricWealth:
+        valueSQL, fromSQL, order = "c.money", "characters c", "DESC"
+    case MetricAchievements:
+        var pointArgs []interface{}
+        valueSQL, pointArgs = achievementPointsSQL(q.AchievementPoints)
+        args = append(args, pointArgs...)
+        fromSQL = "characters c JOIN character_achievement ca ON ca.guid = c.guid"
+        groupSQL = "GROUP BY c.guid, c.name, c.race, c.class, c.gender, c.level"
+        order = "DESC"
+    case MetricFirstLevel:
+        valueSQL = "ca.date"
+        fromSQL = "characters c JOIN character_achievement ca ON ca.guid = c.guid AND ca.achievement = ?"
+        args = append(args, q.Achievement)
+        order = "ASC"
+    default:
+        return nil, nil
+    }
+    
+    where := []string{"c.account <> 0"}
+    if q.Class > 0 {
+        where = append(where, "c.class = ?")
+        args = append(args, q.Class)
+    }
+    if len(q.ExcludeAccounts) > 0 {
+        where = append(where, "c.account NOT IN ("+sqlPlaceholders(len(q.ExcludeAccounts))+")")
+        for _, id := range q.ExcludeAccounts {
+            args = append(args, id)
+        }
+    }
+    if q.Limit <= 0 {
+        q.Limit = 100
+    }
+    
+    query := `
+        SELECT c.guid, c.name, c.race, c.class, c.gender, c.level, ` + valueSQL + ` AS value
+        FROM ` + fromSQL + `
+        WHERE ` + strings.Join(where, " AND ") + `
+        ` + groupSQL + `
+        HAVING value > 0
+        ORDER BY value ` + order + `, c.name ASC
+        LIMIT ?
+    `
+    
+    rows, err := db.Query(query, append(args, q.Limit)...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var entries []LeaderboardEntry
+    for rows.Next() {
+        e := LeaderboardEntry{Character: Character{RealmID: realmID}}
+        if err := rows.Scan(&e.GUID, &e.Name, &e.Race, &e.Class, &e.Gender, &e.Level, &e.Value); err != nil {
+            return nil, err
+        }
+        entries = append(entries, e)
+    }
+    return entries, rows.Err()
+}
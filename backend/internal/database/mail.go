@@ -0,0 +1,108 @@
+package database
+
+import (
+    "errors"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+)
+
+// Лимит вложений в одном внутриигровом письме
+const MailMaxItems = 12
+
+const (
+    mailStationeryGM = 61
+    mailExpireDays   = 30
+)
+
+var ErrMailSQLUnsupported = errors.New("direct mail delivery is not supported for this core")
+
+type ItemStack struct {
+    Entry int
+    Count int
+}
+
+// SendMailSQL пишет письмо с деньгами и предметами прямо в базу персонажей.
+// worldserver раздает id писем и предметов из счетчиков, прочитанных при старте,
+// поэтому вызывать только пока мир выключен, иначе id пересекутся
+func SendMailSQL(realmID, receiver int, subject, body string, money int, items []ItemStack) error {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos хранит предметы блобом
+        return ErrMailSQLUnsupported
+    }
+    if len(items) > MailMaxItems {
+        return errors.New("too many items for one mail")
+    }
+    
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return err
+    }
+    
+    entries := make([]int, len(items))
+    for i, item := range items {
+        entries[i] = item.Entry
+    }
+    templates, err := GetItemTemplates(entries)
+    if err != nil {
+        return err
+    }
+    
+    tx, err := db.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    var mailID, itemGUID int
+    if err := tx.QueryRow("SELECT COALESCE(MAX(id), 0) + 1 FROM mail FOR UPDATE").Scan(&mailID); err != nil {
+        return err
+    }
+    if err := tx.QueryRow("SELECT COALESCE(MAX(guid), 0) + 1 FROM item_instance FOR UPDATE").Scan(&itemGUID); err != nil {
+        return err
+    }
+    
+    now := time.Now()
+    _, err = tx.Exec(`
+        INSERT INTO mail (id, messageType, stationery, mailTemplateId, sender, receiver, subject, body,
+                          has_items, expire_time, deliver_time, money, cod, checked)
+        VALUES (?, 0, ?, 0, 0, ?, ?, ?, ?, ?, ?, ?, 0, 0)
+    `, mailID, mailStationeryGM, receiver, subject, body, len(items) > 0,
+        now.AddDate(0, 0, mailExpireDays).Unix(), now.Unix(), money)
+    if err != nil {
+        return err
+    }
+    
+    enchantments := strings.TrimSpace(strings.Repeat("0 ", 36))
+    for _, item := range items {
+        _, err := tx.Exec(`
+            INSERT INTO item_instance (guid, itemEntry, owner_guid, creatorGuid, giftCreatorGuid, count,
+                                       duration, charges, flags, enchantments, randomPropertyId, durability, playedTime, text)
+            VALUES (?, ?, ?, 0, 0, ?, 0, '0 0 0 0 0 ', 0, ?, 0, ?, 0, '')
+        `, itemGUID, item.Entry, receiver, item.Count, enchantments, templates[item.Entry].MaxDurability)
+        if err != nil {
+            return err
+        }
+        if _, err := tx.Exec("INSERT INTO mail_items (mail_id, item_guid, receiver) VALUES (?, ?, ?)", mailID, itemGUID, receiver); err != nil {
+            return err
+        }
+        itemGUID++
+    }
+    
+    return tx.Commit()
+}
+
+// LearnSpells добавляет заклинания персонажу; персонаж должен быть оффлайн,
+// иначе сервер перезапишет character_spell при сохранении
+func LearnSpells(realmID, guid int, spells []int) error {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return err
+    }
+    
+    for _, spell := range spells {
+        if _, err := db.Exec("INSERT IGNORE INTO character_spell (guid, spell, active, disabled) VALUES (?, ?, 1, 0)", guid, spell); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+    "embed"
+    "fmt"
+    "log"
+    "sort"
+    "strings"
+)
+
+// Таблицы самого сайта живут в auth-базе с префиксом web_
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func Migrate() error {
+    _, err := DB.Exec(`
+        CREATE TABLE IF NOT EXISTS web_schema_migrations (
+            version    VARCHAR(255) NOT NULL PRIMARY KEY,
+            applied_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )
+    `)
+    if err != nil {
+        return fmt.Errorf("failed to create migrations table: %w", err)
+    }
+    
+    applied := make(map[string]bool)
+    rows, err := DB.Query("SELECT version FROM web_schema_migrations")
+    if err != nil {
+        return err
+    }
+    for rows.Next() {
+        var version string
+        if err := rows.Scan(&version); err != nil {
+            rows.Close()
+            return err
+        }
+        applied[version] = true
+    }
+    rows.Close()
+    
+    entries, err := migrationFiles.ReadDir("migrations")
+    if err != nil {
+        return err
+    }
+    names := make([]string, 0, len(entries))
+    for _, e := range entries {
+        names = append(names, e.Name())
+    }
+    sort.Strings(names)
+    
+    for _, name := range names {
+        if applied[name] {
+            continue
+        }
+        
+        content, err := migrationFiles.ReadFile("migrations/" + name)
+        if err != nil {
+            return err
+        }
+        
+        // Драйвер без multiStatements: выполняем по одному выражению
+        for _, stmt := range splitStatements(string(content)) {
+            if _, err := DB.Exec(stmt); err != nil {
+                return fmt.Errorf("migration %s failed: %w", name, err)
+            }
+        }
+        
+        if _, err := DB.Exec("INSERT INTO web_schema_migrations (version) VALUES (?)", name); err != nil {
+            return err
+        }
+        log.Printf("✅ Applied migration %s", name)
+    }
+    
+    return nil
+}
+
+func splitStatements(sql string) []string {
+    var statements []string
+    var current strings.Builder
+    for _, line := range strings.Split(sql, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+            continue
+        }
+        current.WriteString(line)
+        current.WriteString("\n")
+        if strings.HasSuffix(trimmed, ";") {
+            statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+            current.Reset()
+        }
+    }
+    if rest := strings.TrimSpace(current.String()); rest != "" {
+        statements = append(statements, rest)
+    }
+    return statements
+}
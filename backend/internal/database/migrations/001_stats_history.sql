@@ -0,0 +1,18 @@
+-- Снимки онлайна по реалмам для графиков на /status
+CREATE TABLE IF NOT EXISTS web_stats_samples (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    realm_id   INT UNSIGNED    NOT NULL,
+    sampled_at DATETIME        NOT NULL,
+    online     INT UNSIGNED    NOT NULL DEFAULT 0,
+    alliance   INT UNSIGNED    NOT NULL DEFAULT 0,
+    horde      INT UNSIGNED    NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    KEY idx_realm_time (realm_id, sampled_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Регистрации по часам
+CREATE TABLE IF NOT EXISTS web_registration_stats (
+    hour          DATETIME     NOT NULL,
+    registrations INT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (hour)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,17 @@
+-- Персонажи, скрытые владельцем из армори
+CREATE TABLE IF NOT EXISTS web_armory_privacy (
+    realm_id   INT UNSIGNED NOT NULL,
+    guid       INT UNSIGNED NOT NULL,
+    account_id INT UNSIGNED NOT NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (realm_id, guid),
+    KEY idx_account (account_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Очки достижений из Achievement.dbc (импорт: go run ./cmd/import-achievements)
+CREATE TABLE IF NOT EXISTS web_achievements (
+    id     INT UNSIGNED      NOT NULL,
+    name   VARCHAR(255)      NOT NULL DEFAULT '',
+    points SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+-- Что гильд-мастер разрешил показывать на публичной странице гильдии
+CREATE TABLE IF NOT EXISTS web_guild_settings (
+    realm_id   INT UNSIGNED NOT NULL,
+    guild_id   INT UNSIGNED NOT NULL,
+    show_motd  TINYINT(1)   NOT NULL DEFAULT 0,
+    show_info  TINYINT(1)   NOT NULL DEFAULT 1,
+    updated_by INT UNSIGNED NOT NULL,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (realm_id, guild_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
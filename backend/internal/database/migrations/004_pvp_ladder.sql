@@ -0,0 +1,52 @@
+-- Снимки арены по сезонам: текущий сезон перезаписывается по расписанию,
+-- прошлые остаются как архив
+CREATE TABLE IF NOT EXISTS web_arena_ladder (
+    realm_id     INT UNSIGNED      NOT NULL,
+    season       SMALLINT UNSIGNED NOT NULL,
+    bracket      TINYINT UNSIGNED  NOT NULL,
+    team_id      INT UNSIGNED      NOT NULL,
+    position     INT UNSIGNED      NOT NULL,
+    name         VARCHAR(24)       NOT NULL,
+    captain_guid INT UNSIGNED      NOT NULL,
+    captain_name VARCHAR(12)       NOT NULL DEFAULT '',
+    captain_race TINYINT UNSIGNED  NOT NULL DEFAULT 0,
+    rating       INT UNSIGNED      NOT NULL,
+    season_games INT UNSIGNED      NOT NULL DEFAULT 0,
+    season_wins  INT UNSIGNED      NOT NULL DEFAULT 0,
+    computed_at  DATETIME          NOT NULL,
+    PRIMARY KEY (realm_id, season, bracket, team_id),
+    KEY idx_position (realm_id, season, bracket, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS web_arena_ladder_members (
+    realm_id        INT UNSIGNED      NOT NULL,
+    season          SMALLINT UNSIGNED NOT NULL,
+    team_id         INT UNSIGNED      NOT NULL,
+    guid            INT UNSIGNED      NOT NULL,
+    name            VARCHAR(12)       NOT NULL,
+    race            TINYINT UNSIGNED  NOT NULL,
+    class           TINYINT UNSIGNED  NOT NULL,
+    gender          TINYINT UNSIGNED  NOT NULL,
+    level           TINYINT UNSIGNED  NOT NULL,
+    personal_rating INT UNSIGNED      NOT NULL DEFAULT 0,
+    season_games    INT UNSIGNED      NOT NULL DEFAULT 0,
+    season_wins     INT UNSIGNED      NOT NULL DEFAULT 0,
+    PRIMARY KEY (realm_id, season, team_id, guid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Топ по почетным победам и очкам чести (kind = kills | honor)
+CREATE TABLE IF NOT EXISTS web_honor_ladder (
+    realm_id     INT UNSIGNED     NOT NULL,
+    kind         VARCHAR(8)       NOT NULL,
+    position     INT UNSIGNED     NOT NULL,
+    guid         INT UNSIGNED     NOT NULL,
+    name         VARCHAR(12)      NOT NULL,
+    race         TINYINT UNSIGNED NOT NULL,
+    class        TINYINT UNSIGNED NOT NULL,
+    gender       TINYINT UNSIGNED NOT NULL,
+    level        TINYINT UNSIGNED NOT NULL,
+    total_kills  INT UNSIGNED     NOT NULL DEFAULT 0,
+    honor_points INT UNSIGNED     NOT NULL DEFAULT 0,
+    computed_at  DATETIME         NOT NULL,
+    PRIMARY KEY (realm_id, kind, position)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
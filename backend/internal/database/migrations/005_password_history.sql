@@ -0,0 +1,11 @@
+-- Прошлые пароли для PASSWORD_HISTORY_COUNT: хранится только соль и SHA-256
+-- от SRP6 verifier, сам пароль восстановить нельзя
+CREATE TABLE IF NOT EXISTS web_password_history (
+    id            INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    account_id    INT UNSIGNED NOT NULL,
+    salt          CHAR(64)     NOT NULL,
+    verifier_hash CHAR(64)     NOT NULL,
+    created_at    DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_account (account_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,34 @@
+-- Баланс поинтов сайта и журнал всех движений по нему
+CREATE TABLE IF NOT EXISTS web_points (
+    account_id INT UNSIGNED NOT NULL,
+    balance    INT          NOT NULL DEFAULT 0,
+    updated_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS web_points_ledger (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    account_id INT UNSIGNED    NOT NULL,
+    amount     INT             NOT NULL,
+    kind       VARCHAR(16)     NOT NULL,
+    reference  VARCHAR(64)     NOT NULL DEFAULT '',
+    created_at DATETIME        NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_account (account_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Действия над персонажами из личного кабинета; по ним же считаются кулдауны
+CREATE TABLE IF NOT EXISTS web_character_actions (
+    id         BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    account_id INT UNSIGNED     NOT NULL,
+    realm_id   INT UNSIGNED     NOT NULL,
+    guid       INT UNSIGNED     NOT NULL,
+    action     VARCHAR(16)      NOT NULL,
+    cost       INT UNSIGNED     NOT NULL DEFAULT 0,
+    status     VARCHAR(16)      NOT NULL,
+    result     VARCHAR(255)     NOT NULL DEFAULT '',
+    created_at DATETIME         NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_character (realm_id, guid, action, created_at),
+    KEY idx_account (account_id, action, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,22 @@
+-- Стартовый бонус: одна строка на аккаунт, каждая часть (золото, предметы,
+-- заклинания) помечается отдельно, чтобы перезапуск не выдал ее повторно.
+-- Предметы уходят несколькими письмами, items_sent — сколько уже отправлено.
+-- Очередь идет по кругу по next_check_at: обработанная строка уходит в конец,
+-- а аккаунты, которые давно не создают персонажа, проверяются раз в час
+CREATE TABLE IF NOT EXISTS web_registration_bonus (
+    account_id     INT UNSIGNED      NOT NULL,
+    status         VARCHAR(16)       NOT NULL DEFAULT 'waiting',
+    realm_id       INT UNSIGNED      NOT NULL DEFAULT 0,
+    guid           INT UNSIGNED      NOT NULL DEFAULT 0,
+    character_name VARCHAR(12)       NOT NULL DEFAULT '',
+    money_state    VARCHAR(8)        NOT NULL DEFAULT 'pending',
+    items_state    VARCHAR(8)        NOT NULL DEFAULT 'pending',
+    items_sent     SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+    spells_state   VARCHAR(8)        NOT NULL DEFAULT 'pending',
+    error          VARCHAR(255)      NOT NULL DEFAULT '',
+    created_at     DATETIME          NOT NULL,
+    next_check_at  DATETIME          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     DATETIME          NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (account_id),
+    KEY idx_status (status, next_check_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
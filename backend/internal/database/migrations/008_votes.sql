@@ -0,0 +1,25 @@
+-- Голоса на топах: pending создается при переходе на сайт топа, credited —
+-- после проверенного postback. По credited считаются кулдауны аккаунта и IP.
+-- Предмет за голос уходит письмом отдельно от поинтов: reward_state ведет
+-- очередь писем, которые повторяются, пока worldserver их не примет
+CREATE TABLE IF NOT EXISTS web_votes (
+    id              BIGINT UNSIGNED  NOT NULL AUTO_INCREMENT,
+    site            VARCHAR(32)      NOT NULL,
+    account_id      INT UNSIGNED     NOT NULL,
+    ip              VARCHAR(45)      NOT NULL,
+    token           CHAR(40)         NOT NULL,
+    realm_id        INT UNSIGNED     NOT NULL DEFAULT 0,
+    guid            INT UNSIGNED     NOT NULL DEFAULT 0,
+    status          VARCHAR(16)      NOT NULL DEFAULT 'pending',
+    created_at      DATETIME         NOT NULL,
+    credited_at     DATETIME         NULL,
+    reward_state    VARCHAR(16)      NOT NULL DEFAULT 'none',
+    reward_attempts TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME         NULL,
+    reward_error    VARCHAR(255)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uniq_token (token),
+    KEY idx_account (site, account_id, status, credited_at),
+    KEY idx_ip (site, ip, status, credited_at),
+    KEY idx_reward (reward_state, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
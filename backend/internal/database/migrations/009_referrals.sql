@@ -0,0 +1,26 @@
+-- Реферальные коды аккаунтов и приглашенные ими аккаунты
+CREATE TABLE IF NOT EXISTS web_referral_codes (
+    account_id INT UNSIGNED NOT NULL,
+    code       VARCHAR(16)  NOT NULL,
+    created_at DATETIME     NOT NULL,
+    PRIMARY KEY (account_id),
+    UNIQUE KEY uniq_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Награда выдается, когда приглашенный достигает цели; rejected — сработала
+-- проверка на мультоводство (общий IP или почта). Приглашения проверяются по
+-- кругу: проверенное уходит в конец очереди по next_check_at, и застрявшие на
+-- низком уровне аккаунты не загораживают остальные
+CREATE TABLE IF NOT EXISTS web_referrals (
+    referee_id  INT UNSIGNED NOT NULL,
+    referrer_id INT UNSIGNED NOT NULL,
+    status      VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    reason      VARCHAR(64)  NOT NULL DEFAULT '',
+    ip          VARCHAR(45)  NOT NULL DEFAULT '',
+    created_at  DATETIME     NOT NULL,
+    rewarded_at DATETIME     NULL,
+    next_check_at DATETIME   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (referee_id),
+    KEY idx_referrer (referrer_id, status),
+    KEY idx_status (status, next_check_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+-- Журнал поинтов только дополняется: каждая запись хранит баланс после операции,
+-- а ручные начисления — кто их сделал и почему
+ALTER TABLE web_points_ledger
+    ADD COLUMN balance_after INT          NOT NULL DEFAULT 0  AFTER amount,
+    ADD COLUMN reason        VARCHAR(255) NOT NULL DEFAULT '' AFTER reference,
+    ADD COLUMN actor_id      INT UNSIGNED NOT NULL DEFAULT 0  AFTER reason;
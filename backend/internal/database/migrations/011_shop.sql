@@ -0,0 +1,44 @@
+-- Каталог магазина. payload зависит от вида товара: "itemId:count,…" для item,
+-- золото для gold, действие (rename, faction…) для service, уровень для level
+CREATE TABLE IF NOT EXISTS web_shop_products (
+    id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    kind        VARCHAR(16)  NOT NULL,
+    name        VARCHAR(64)  NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    payload     VARCHAR(255) NOT NULL,
+    price       INT UNSIGNED NOT NULL,
+    sort_order  INT          NOT NULL DEFAULT 0,
+    enabled     TINYINT(1)   NOT NULL DEFAULT 1,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Заказы. Ключ идемпотентности уникален в пределах аккаунта, так что повторный
+-- запрос с тем же ключом вернет уже созданный заказ, а не спишет поинты еще раз.
+-- Товар копируется в заказ: правка каталога не меняет уже оплаченное
+CREATE TABLE IF NOT EXISTS web_shop_orders (
+    id              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    account_id      INT UNSIGNED    NOT NULL,
+    idempotency_key VARCHAR(64)     NOT NULL,
+    product_id      INT UNSIGNED    NOT NULL,
+    kind            VARCHAR(16)     NOT NULL,
+    name            VARCHAR(64)     NOT NULL,
+    payload         VARCHAR(255)    NOT NULL,
+    price           INT UNSIGNED    NOT NULL,
+    realm_id        INT UNSIGNED    NOT NULL,
+    guid            INT UNSIGNED    NOT NULL,
+    character_name  VARCHAR(12)     NOT NULL,
+    status          VARCHAR(16)     NOT NULL DEFAULT 'pending',
+    attempts        INT UNSIGNED    NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME        NULL,
+    error           VARCHAR(255)    NOT NULL DEFAULT '',
+    created_at      DATETIME        NOT NULL,
+    updated_at      DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uniq_idempotency (account_id, idempotency_key),
+    KEY idx_delivery (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Поиск списания по заказу при восстановлении после сбоя
+ALTER TABLE web_points_ledger ADD KEY idx_reference (reference);
@@ -0,0 +1,32 @@
+-- Пожертвования. reference — наш идентификатор, который провайдер возвращает
+-- в уведомлениях; payment_id — идентификатор платежа у провайдера
+CREATE TABLE IF NOT EXISTS web_donations (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    account_id INT UNSIGNED    NOT NULL,
+    provider   VARCHAR(32)     NOT NULL,
+    reference  CHAR(32)        NOT NULL,
+    payment_id VARCHAR(128)    NOT NULL DEFAULT '',
+    amount     BIGINT UNSIGNED NOT NULL,
+    currency   CHAR(3)         NOT NULL,
+    points     INT UNSIGNED    NOT NULL DEFAULT 0,
+    status     VARCHAR(16)     NOT NULL DEFAULT 'pending',
+    created_at DATETIME        NOT NULL,
+    updated_at DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE KEY uniq_reference (reference),
+    KEY idx_account (account_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Все смены статуса платежа вместе с тем, что прислал провайдер
+CREATE TABLE IF NOT EXISTS web_donation_events (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    donation_id BIGINT UNSIGNED NOT NULL,
+    from_status VARCHAR(16)     NOT NULL,
+    to_status   VARCHAR(16)     NOT NULL,
+    payment_id  VARCHAR(128)    NOT NULL DEFAULT '',
+    amount      BIGINT UNSIGNED NOT NULL,
+    points      INT             NOT NULL DEFAULT 0,
+    created_at  DATETIME        NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_donation (donation_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
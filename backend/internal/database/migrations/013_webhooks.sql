@@ -0,0 +1,34 @@
+-- Исходящие webhook'и. events — список подписок через запятую
+CREATE TABLE IF NOT EXISTS web_webhooks (
+    id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    url         VARCHAR(512) NOT NULL,
+    secret      VARCHAR(64)  NOT NULL,
+    events      VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    enabled     TINYINT(1)   NOT NULL DEFAULT 1,
+    created_at  DATETIME     NOT NULL,
+    updated_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Очередь и журнал доставок. Тело события сохраняется целиком, так что
+-- повторная отправка шлет ровно то же, что и первая; event_id общий у всех
+-- доставок одного события, по нему получатель отбрасывает дубли
+CREATE TABLE IF NOT EXISTS web_webhook_deliveries (
+    id              BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    webhook_id      INT UNSIGNED    NOT NULL,
+    event_id        CHAR(32)        NOT NULL,
+    event           VARCHAR(32)     NOT NULL,
+    payload         MEDIUMTEXT      NOT NULL,
+    status          VARCHAR(16)     NOT NULL DEFAULT 'pending',
+    attempts        INT UNSIGNED    NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME        NULL,
+    response_code   SMALLINT        NOT NULL DEFAULT 0,
+    error           VARCHAR(512)    NOT NULL DEFAULT '',
+    created_at      DATETIME        NOT NULL,
+    delivered_at    DATETIME        NULL,
+    updated_at      DATETIME        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    KEY idx_queue (status, next_attempt_at),
+    KEY idx_webhook (webhook_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,27 @@
+-- Заметки персонала к аккаунтам
+CREATE TABLE IF NOT EXISTS web_account_notes (
+    id          INT UNSIGNED NOT NULL AUTO_INCREMENT,
+    account_id  INT UNSIGNED NOT NULL,
+    author_id   INT UNSIGNED NOT NULL,
+    author_name VARCHAR(64)  NOT NULL,
+    note        TEXT         NOT NULL,
+    created_at  DATETIME     NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_account (account_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Журнал действий персонала. actor_id 0 — действие не из панели (Telegram);
+-- details — JSON с параметрами действия
+CREATE TABLE IF NOT EXISTS web_audit_log (
+    id                BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    actor_id          INT UNSIGNED    NOT NULL,
+    actor_name        VARCHAR(64)     NOT NULL,
+    action            VARCHAR(48)     NOT NULL,
+    target_account_id INT UNSIGNED    NOT NULL DEFAULT 0,
+    details           TEXT            NOT NULL,
+    ip                VARCHAR(45)     NOT NULL DEFAULT '',
+    created_at        DATETIME        NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_target (target_account_id, id),
+    KEY idx_actor (actor_id, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,25 @@
+-- Журнал аудита становится общим: события безопасности игроков, контекст
+-- запроса, изменения «до/после» и цепочка хешей, по которой видна правка истории
+ALTER TABLE web_audit_log
+    ADD COLUMN target     VARCHAR(128) NOT NULL DEFAULT '' AFTER target_account_id,
+    ADD COLUMN changes    TEXT         NOT NULL AFTER details,
+    ADD COLUMN user_agent VARCHAR(255) NOT NULL DEFAULT '' AFTER ip,
+    ADD COLUMN request_id VARCHAR(64)  NOT NULL DEFAULT '' AFTER user_agent,
+    ADD COLUMN prev_hash  CHAR(64)     NOT NULL DEFAULT '' AFTER request_id,
+    ADD COLUMN hash       CHAR(64)     NOT NULL DEFAULT '' AFTER prev_hash,
+    ADD KEY idx_action (action, id),
+    ADD KEY idx_created (created_at),
+    ADD KEY idx_request (request_id);
+
+-- Голова цепочки: хеш последней записи и граница удаленных по сроку хранения.
+-- Строка одна, блокировка на ней упорядочивает вставки
+CREATE TABLE IF NOT EXISTS web_audit_chain (
+    id          TINYINT UNSIGNED NOT NULL,
+    last_id     BIGINT UNSIGNED  NOT NULL DEFAULT 0,
+    last_hash   CHAR(64)         NOT NULL DEFAULT '',
+    pruned_id   BIGINT UNSIGNED  NOT NULL DEFAULT 0,
+    pruned_hash CHAR(64)         NOT NULL DEFAULT '',
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT IGNORE INTO web_audit_chain (id) VALUES (1);
@@ -0,0 +1,152 @@
+package database
+
+import (
+    "database/sql"
+    "errors"
+    "time"
+)
+
+var (
+    ErrInsufficientPoints = errors.New("not enough points")
+    ErrUnknownPointsKind  = errors.New("unknown points transaction kind")
+)
+
+// Виды движений по поинтам
+const (
+    PointsVote       = "vote"
+    PointsReferral   = "referral"
+    PointsAdminGrant = "admin-grant"
+    PointsPurchase   = "purchase"
+    PointsRefund     = "refund"
+    PointsDonation   = "donation"
+    PointsChargeback = "chargeback"
+)
+
+var pointsKinds = map[string]bool{
+    PointsVote:       true,
+    PointsReferral:   true,
+    PointsAdminGrant: true,
+    PointsPurchase:   true,
+    PointsRefund:     true,
+    PointsDonation:   true,
+    PointsChargeback: true,
+}
+
+// PointsEntry — запись журнала поинтов. Записи не меняются и не удаляются,
+// исправления делаются встречной операцией
+type PointsEntry struct {
+    ID           int64     `json:"id"`
+    AccountID    int       `json:"-"`
+    Amount       int       `json:"amount"`
+    BalanceAfter int       `json:"balance_after"`
+    Kind         string    `json:"kind"`
+    Reference    string    `json:"reference,omitempty"`
+    Reason       string    `json:"reason,omitempty"`
+    ActorID      int       `json:"-"`
+    CreatedAt    time.Time `json:"created_at"`
+    // AllowNegative разрешает уйти в минус: списание при возврате платежа
+    // нельзя отклонить только потому, что поинты уже потрачены
+    AllowNegative bool `json:"-"`
+}
+
+func GetPointsBalance(accountID int) (int, error) {
+    var balance int
+    err := DB.QueryRow("SELECT COALESCE(MAX(balance), 0) FROM web_points WHERE account_id = ?", accountID).Scan(&balance)
+    return balance, err
+}
+
+// AdjustPoints меняет баланс на amount с записью в журнал
+func AdjustPoints(accountID, amount int, kind, reference string) error {
+    return RecordPoints(&PointsEntry{AccountID: accountID, Amount: amount, Kind: kind, Reference: reference})
+}
+
+// RecordPoints меняет баланс и пишет запись в журнал в одной транзакции.
+// Строка баланса блокируется (FOR UPDATE), так что параллельные списания
+// не уведут баланс в минус; при нехватке возвращается ErrInsufficientPoints.
+// В entry заполняются ID, BalanceAfter и CreatedAt
+func RecordPoints(entry *PointsEntry) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    if err := recordPointsTx(tx, entry); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// recordPointsTx — то же внутри чужой транзакции, чтобы движение по поинтам
+// и смена статуса связанной записи (платежа, заказа) фиксировались вместе
+func recordPointsTx(tx *sql.Tx, entry *PointsEntry) error {
+    if !pointsKinds[entry.Kind] {
+        return ErrUnknownPointsKind
+    }
+    
+    if _, err := tx.Exec("INSERT IGNORE INTO web_points (account_id, balance) VALUES (?, 0)", entry.AccountID); err != nil {
+        return err
+    }
+    
+    var balance int
+    if err := tx.QueryRow("SELECT balance FROM web_points WHERE account_id = ? FOR UPDATE", entry.AccountID).Scan(&balance); err != nil {
+        return err
+    }
+    if balance+entry.Amount < 0 && entry.Amount < 0 && !entry.AllowNegative {
+        return ErrInsufficientPoints
+    }
+    
+    if _, err := tx.Exec("UPDATE web_points SET balance = balance + ? WHERE account_id = ?", entry.Amount, entry.AccountID); err != nil {
+        return err
+    }
+    
+    entry.BalanceAfter = balance + entry.Amount
+    entry.CreatedAt = time.Now()
+    result, err := tx.Exec(`
+        INSERT INTO web_points_ledger (account_id, amount, balance_after, kind, reference, reason, actor_id, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, entry.AccountID, entry.Amount, entry.BalanceAfter, entry.Kind, entry.Reference, entry.Reason, entry.ActorID, entry.CreatedAt)
+    if err != nil {
+        return err
+    }
+    entry.ID, err = result.LastInsertId()
+    return err
+}
+
+func CountPointsEntries(accountID int) (int, error) {
+    var count int
+    err := DB.QueryRow("SELECT COUNT(*) FROM web_points_ledger WHERE account_id = ?", accountID).Scan(&count)
+    return count, err
+}
+
+// GetPointsEntries — журнал аккаунта, новые записи первыми
+func GetPointsEntries(accountID, limit, offset int) ([]PointsEntry, error) {
+    rows, err := DB.Query(`
+        SELECT id, account_id, amount, balance_after, kind, reference, reason, actor_id, created_at
+        FROM web_points_ledger
+        WHERE account_id = ?
+        ORDER BY id DESC
+        LIMIT ? OFFSET ?
+    `, accountID, limit, offset)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var entries []PointsEntry
+    for rows.Next() {
+        var e PointsEntry
+        if err := rows.Scan(&e.ID, &e.AccountID, &e.Amount, &e.BalanceAfter, &e.Kind, &e.Reference, &e.Reason, &e.ActorID, &e.CreatedAt); err != nil {
+            return nil, err
+        }
+        entries = append(entries, e)
+    }
+    return entries, rows.Err()
+}
+
+// HasPointsEntry — есть ли в журнале запись такого вида с такой ссылкой
+func HasPointsEntry(kind, reference string) (bool, error) {
+    var exists bool
+    err := DB.QueryRow("SELECT EXISTS(SELECT 1 FROM web_points_ledger WHERE kind = ? AND reference = ?)", kind, reference).Scan(&exists)
+    return exists, err
+}
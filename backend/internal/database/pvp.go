@@ -0,0 +1,407 @@
+package database
+
+import (
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+)
+
+var ArenaBrackets = []int{2, 3, 5}
+
+type ArenaTeam struct {
+    ID          int
+    Bracket     int
+    Position    int
+    Name        string
+    CaptainGUID int
+    CaptainName string
+    CaptainRace int
+    Rating      int
+    SeasonGames int
+    SeasonWins  int
+    ComputedAt  time.Time
+}
+
+type ArenaTeamMember struct {
+    Character
+    TeamID         int
+    PersonalRating int
+    SeasonGames    int
+    SeasonWins     int
+}
+
+type HonorEntry struct {
+    Character
+    Position    int
+    TotalKills  int
+    HonorPoints int
+    ComputedAt  time.Time
+}
+
+// Колонки арены отличаются: у CMangos статистика команды вынесена в arena_team_stats
+func arenaTeamsQuery() string {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return `
+            SELECT t.arenateamid, t.name, t.captainguid, COALESCE(c.name, ''), COALESCE(c.race, 0),
+                   s.rating, s.games_season, s.wins_season
+            FROM arena_team t
+            JOIN arena_team_stats s ON s.arenateamid = t.arenateamid
+            LEFT JOIN characters c ON c.guid = t.captainguid
+            WHERE t.type = ? AND s.games_season > 0
+            ORDER BY s.rating DESC, s.wins_season DESC, t.name ASC
+            LIMIT ?
+        `
+    }
+    return `
+        SELECT t.arenaTeamId, t.name, t.captainGuid, COALESCE(c.name, ''), COALESCE(c.race, 0),
+               t.rating, t.seasonGames, t.seasonWins
+        FROM arena_team t
+        LEFT JOIN characters c ON c.guid = t.captainGuid
+        WHERE t.type = ? AND t.seasonGames > 0
+        ORDER BY t.rating DESC, t.seasonWins DESC, t.name ASC
+        LIMIT ?
+    `
+}
+
+func arenaMembersQuery(placeholders string) string {
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return `
+            SELECT m.arenateamid, c.guid, c.name, c.race, c.class, c.gender, c.level,
+                   m.personal_rating, m.played_season, m.wons_season
+            FROM arena_team_member m
+            JOIN characters c ON c.guid = m.guid
+            WHERE m.arenateamid IN (` + placeholders + `)
+        `
+    }
+    return `
+        SELECT m.arenaTeamId, c.guid, c.name, c.race, c.class, c.gender, c.level,
+               m.personalRating, m.seasonGames, m.seasonWins
+        FROM arena_team_member m
+        JOIN characters c ON c.guid = m.guid
+        WHERE m.arenaTeamId IN (` + placeholders + `)
+    `
+}
+
+// ReadArenaTeams читает живой рейтинг команд из базы персонажей
+func ReadArenaTeams(realmID, bracket, limit int) ([]ArenaTeam, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    rows, err := db.Query(arenaTeamsQuery(), bracket, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var teams []ArenaTeam
+    for rows.Next() {
+        t := ArenaTeam{Bracket: bracket, Position: len(teams) + 1}
+        if err := rows.Scan(
+            &t.ID, &t.Name, &t.CaptainGUID, &t.CaptainName, &t.CaptainRace,
+            &t.Rating, &t.SeasonGames, &t.SeasonWins,
+        ); err != nil {
+            return nil, err
+        }
+        teams = append(teams, t)
+    }
+    return teams, rows.Err()
+}
+
+func ReadArenaTeamMembers(realmID int, teamIDs []int) ([]ArenaTeamMember, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    if len(teamIDs) == 0 {
+        return nil, nil
+    }
+    
+    placeholders := make([]string, len(teamIDs))
+    args := make([]interface{}, len(teamIDs))
+    for i, id := range teamIDs {
+        placeholders[i] = "?"
+        args[i] = id
+    }
+    
+    rows, err := db.Query(arenaMembersQuery(strings.Join(placeholders, ", ")), args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var members []ArenaTeamMember
+    for rows.Next() {
+        m := ArenaTeamMember{Character: Character{RealmID: realmID}}
+        if err := rows.Scan(
+            &m.TeamID, &m.GUID, &m.Name, &m.Race, &m.Class, &m.Gender, &m.Level,
+            &m.PersonalRating, &m.SeasonGames, &m.SeasonWins,
+        ); err != nil {
+            return nil, err
+        }
+        members = append(members, m)
+    }
+    return members, rows.Err()
+}
+
+// Разрешенные виды рейтинга чести -> колонки characters
+var honorColumns = map[string]string{
+    "kills": "totalKills",
+    "honor": "totalHonorPoints",
+}
+
+// ReadHonorTop читает топ персонажей по почетным победам или очкам чести
+func ReadHonorTop(realmID int, kind string, limit int) ([]HonorEntry, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return nil, err
+    }
+    
+    column, ok := honorColumns[kind]
+    if !ok {
+        column = honorColumns["kills"]
+    }
+    
+    query := `
+        SELECT guid, name, race, class, gender, level, totalKills, totalHonorPoints
+        FROM characters
+        WHERE account <> 0 AND ` + column + ` > 0
+        ORDER BY ` + column + ` DESC, name ASC
+        LIMIT ?
+    `
+    
+    rows, err := db.Query(query, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var entries []HonorEntry
+    for rows.Next() {
+        e := HonorEntry{Character: Character{RealmID: realmID}, Position: len(entries) + 1}
+        if err := rows.Scan(&e.GUID, &e.Name, &e.Race, &e.Class, &e.Gender, &e.Level, &e.TotalKills, &e.HonorPoints); err != nil {
+            return nil, err
+        }
+        entries = append(entries, e)
+    }
+    return entries, rows.Err()
+}
+
+// SaveArenaSnapshot заменяет снимок сезона для одной сетки целиком
+func SaveArenaSnapshot(realmID, season, bracket int, teams []ArenaTeam, members []ArenaTeamMember, computedAt time.Time) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    _, err = tx.Exec(`
+        DELETE m FROM web_arena_ladder_members m
+        JOIN web_arena_ladder t ON t.realm_id = m.realm_id AND t.season = m.season AND t.team_id = m.team_id
+        WHERE t.realm_id = ? AND t.season = ? AND t.bracket = ?
+    `, realmID, season, bracket)
+    if err != nil {
+        return err
+    }
+    
+    _, err = tx.Exec("DELETE FROM web_arena_ladder WHERE realm_id = ? AND season = ? AND bracket = ?", realmID, season, bracket)
+    if err != nil {
+        return err
+    }
+    
+    for _, t := range teams {
+        _, err := tx.Exec(`
+            INSERT INTO web_arena_ladder (
+                realm_id, season, bracket, team_id, position, name,
+                captain_guid, captain_name, captain_race,
+                rating, season_games, season_wins, computed_at
+            ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        `, realmID, season, bracket, t.ID, t.Position, t.Name,
+            t.CaptainGUID, t.CaptainName, t.CaptainRace,
+            t.Rating, t.SeasonGames, t.SeasonWins, computedAt)
+        if err != nil {
+            return err
+        }
+    }
+    
+    for _, m := range members {
+        _, err := tx.Exec(`
+            INSERT INTO web_arena_ladder_members (
+                realm_id, season, team_id, guid, name, race, class, gender, level,
+                personal_rating, season_games, season_wins
+            ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        `, realmID, season, m.TeamID, m.GUID, m.Name, m.Race, m.Class, m.Gender, m.Level,
+            m.PersonalRating, m.SeasonGames, m.SeasonWins)
+        if err != nil {
+            return err
+        }
+    }
+    
+    return tx.Commit()
+}
+
+func SaveHonorSnapshot(realmID int, kind string, entries []HonorEntry, computedAt time.Time) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    if _, err := tx.Exec("DELETE FROM web_honor_ladder WHERE realm_id = ? AND kind = ?", realmID, kind); err != nil {
+        return err
+    }
+    
+    for _, e := range entries {
+        _, err := tx.Exec(`
+            INSERT INTO web_honor_ladder (
+                realm_id, kind, position, guid, name, race, class, gender, level,
+                total_kills, honor_points, computed_at
+            ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        `, realmID, kind, e.Position, e.GUID, e.Name, e.Race, e.Class, e.Gender, e.Level,
+            e.TotalKills, e.HonorPoints, computedAt)
+        if err != nil {
+            return err
+        }
+    }
+    
+    return tx.Commit()
+}
+
+// GetArenaSeasons — сезоны, для которых есть снимки, новые первыми
+func GetArenaSeasons(realmID int) ([]int, error) {
+    rows, err := DB.Query("SELECT DISTINCT season FROM web_arena_ladder WHERE realm_id = ? ORDER BY season DESC", realmID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var seasons []int
+    for rows.Next() {
+        var season int
+        if err := rows.Scan(&season); err != nil {
+            return nil, err
+        }
+        seasons = append(seasons, season)
+    }
+    return seasons, rows.Err()
+}
+
+const arenaLadderColumns = `
+    team_id, bracket, position, name, captain_guid, captain_name, captain_race,
+    rating, season_games, season_wins, computed_at
+`
+
+func scanArenaTeam(scanner interface{ Scan(...interface{}) error }) (ArenaTeam, error) {
+    var t ArenaTeam
+    err := scanner.Scan(
+        &t.ID, &t.Bracket, &t.Position, &t.Name, &t.CaptainGUID, &t.CaptainName, &t.CaptainRace,
+        &t.Rating, &t.SeasonGames, &t.SeasonWins, &t.ComputedAt,
+    )
+    return t, err
+}
+
+func GetArenaLadder(realmID, season, bracket, limit, offset int) ([]ArenaTeam, int, error) {
+    var total int
+    err := DB.QueryRow(
+        "SELECT COUNT(*) FROM web_arena_ladder WHERE realm_id = ? AND season = ? AND bracket = ?",
+        realmID, season, bracket,
+    ).Scan(&total)
+    if err != nil {
+        return nil, 0, err
+    }
+    
+    query := `
+        SELECT ` + arenaLadderColumns + `
+        FROM web_arena_ladder
+        WHERE realm_id = ? AND season = ? AND bracket = ?
+        ORDER BY position
+        LIMIT ? OFFSET ?
+    `
+    
+    rows, err := DB.Query(query, realmID, season, bracket, limit, offset)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+    
+    var teams []ArenaTeam
+    for rows.Next() {
+        t, err := scanArenaTeam(rows)
+        if err != nil {
+            return nil, 0, err
+        }
+        teams = append(teams, t)
+    }
+    return teams, total, rows.Err()
+}
+
+func GetArenaTeamSnapshot(realmID, season, teamID int) (*ArenaTeam, []ArenaTeamMember, error) {
+    query := `
+        SELECT ` + arenaLadderColumns + `
+        FROM web_arena_ladder
+        WHERE realm_id = ? AND season = ? AND team_id = ?
+    `
+    
+    team, err := scanArenaTeam(DB.QueryRow(query, realmID, season, teamID))
+    if err != nil {
+        return nil, nil, err
+    }
+    
+    rows, err := DB.Query(`
+        SELECT guid, name, race, class, gender, level, personal_rating, season_games, season_wins
+        FROM web_arena_ladder_members
+        WHERE realm_id = ? AND season = ? AND team_id = ?
+        ORDER BY personal_rating DESC, name ASC
+    `, realmID, season, teamID)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer rows.Close()
+    
+    var members []ArenaTeamMember
+    for rows.Next() {
+        m := ArenaTeamMember{Character: Character{RealmID: realmID}, TeamID: teamID}
+        if err := rows.Scan(
+            &m.GUID, &m.Name, &m.Race, &m.Class, &m.Gender, &m.Level,
+            &m.PersonalRating, &m.SeasonGames, &m.SeasonWins,
+        ); err != nil {
+            return nil, nil, err
+        }
+        members = append(members, m)
+    }
+    return &team, members, rows.Err()
+}
+
+func GetHonorLadder(realmID int, kind string, limit, offset int) ([]HonorEntry, int, error) {
+    var total int
+    err := DB.QueryRow("SELECT COUNT(*) FROM web_honor_ladder WHERE realm_id = ? AND kind = ?", realmID, kind).Scan(&total)
+    if err != nil {
+        return nil, 0, err
+    }
+    
+    rows, err := DB.Query(`
+        SELECT position, guid, name, race, class, gender, level, total_kills, honor_points, computed_at
+        FROM web_honor_ladder
+        WHERE realm_id = ? AND kind = ?
+        ORDER BY position
+        LIMIT ? OFFSET ?
+    `, realmID, kind, limit, offset)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer rows.Close()
+    
+    var entries []HonorEntry
+    for rows.Next() {
+        e := HonorEntry{Character: Character{RealmID: realmID}}
+        if err := rows.Scan(
+            &e.Position, &e.GUID, &e.Name, &e.Race, &e.Class, &e.Gender, &e.Level,
+            &e.TotalKills, &e.HonorPoints, &e.ComputedAt,
+        ); err != nil {
+            return nil, 0, err
+        }
+        entries = append(entries, e)
+    }
+    return entries, total, rows.Err()
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "log"
+    "sync"
+    "wow-registration/internal/config"
+)
+
+type Realm struct {
+    ID         int     `json:"id"`
+    Name       string  `json:"name"`
+    Address    string  `json:"address"`
+    Port       int     `json:"port"`
+    Icon       int     `json:"icon"`
+    Flag       int     `json:"flag"`
+    Timezone   int     `json:"timezone"`
+    Population float64 `json:"population"`
+}
+
+// Флаг REALM_FLAG_OFFLINE из realmlist
+const RealmFlagOffline = 0x02
+
+var (
+    realmsMu sync.RWMutex
+    realms   []Realm
+    charDBs  = make(map[int]*sql.DB)
+)
+
+// ConnectRealms читает auth.realmlist и открывает базу персонажей для каждого
+// реалма, у которого есть запись в config.Database.RealmChars.
+func ConnectRealms() error {
+    list, err := LoadRealms()
+    if err != nil {
+        return fmt.Errorf("failed to load realmlist: %w", err)
+    }
+    
+    cfg := config.AppConfig
+    dbs := make(map[int]*sql.DB)
+    var configured []Realm
+    for _, realm := range list {
+        dbCfg, ok := cfg.Database.RealmChars[realm.ID]
+        if !ok {
+            log.Printf("⚠️  Realm %d (%s) has no characters database configured, skipping", realm.ID, realm.Name)
+            continue
+        }
+        
+        db, err := openMySQL(dbCfg.Host, dbCfg.Port, dbCfg.User, dbCfg.Password, dbCfg.Name)
+        if err != nil {
+            for _, opened := range dbs {
+                opened.Close()
+            }
+            return fmt.Errorf("realm %d: %w", realm.ID, err)
+        }
+        dbs[realm.ID] = db
+        configured = append(configured, realm)
+    }
+    
+    realmsMu.Lock()
+    realms = configured
+    charDBs = dbs
+    realmsMu.Unlock()
+    
+    return nil
+}
+
+// LoadRealms читает список реалмов напрямую из auth.realmlist
+func LoadRealms() ([]Realm, error) {
+    flagColumn := "flag"
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        flagColumn = "realmflags"
+    }
+    
+    query := `
+        SELECT id, name, address, port, icon, ` + flagColumn + `, timezone, population
+        FROM realmlist
+        ORDER BY id
+    `
+    
+    rows, err := DB.Query(query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var list []Realm
+    for rows.Next() {
+        var r Realm
+        if err := rows.Scan(&r.ID, &r.Name, &r.Address, &r.Port, &r.Icon, &r.Flag, &r.Timezone, &r.Population); err != nil {
+            return nil, err
+        }
+        list = append(list, r)
+    }
+    
+    return list, rows.Err()
+}
+
+// GetRealms возвращает реалмы, для которых подключена база персонажей
+func GetRealms() []Realm {
+    realmsMu.RLock()
+    defer realmsMu.RUnlock()
+    
+    list := make([]Realm, len(realms))
+    copy(list, realms)
+    return list
+}
+
+func GetRealm(realmID int) (*Realm, bool) {
+    realmsMu.RLock()
+    defer realmsMu.RUnlock()
+    
+    for i := range realms {
+        if realms[i].ID == realmID {
+            realm := realms[i]
+            return &realm, true
+        }
+    }
+    return nil, false
+}
+
+// DefaultRealmID возвращает DEFAULT_REALM, если он подключен, иначе первый доступный реалм
+func DefaultRealmID() int {
+    realmsMu.RLock()
+    defer realmsMu.RUnlock()
+    
+    defaultID := config.AppConfig.Game.DefaultRealm
+    if _, ok := charDBs[defaultID]; ok || len(realms) == 0 {
+        return defaultID
+    }
+    return realms[0].ID
+}
+
+// CharsDB возвращает соединение с базой персонажей реалма
+func CharsDB(realmID int) (*sql.DB, error) {
+    realmsMu.RLock()
+    defer realmsMu.RUnlock()
+    
+    db, ok := charDBs[realmID]
+    if !ok {
+        return nil, fmt.Errorf("unknown realm %d", realmID)
+    }
+    return db, nil
+}
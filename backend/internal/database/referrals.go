@@ -0,0 +1,194 @@
+package database
+
+import (
+    "database/sql"
+    "time"
+)
+
+// Статусы web_referrals
+const (
+    ReferralPending  = "pending"
+    ReferralRewarded = "rewarded"
+    ReferralRejected = "rejected"
+)
+
+type Referral struct {
+    RefereeID   int
+    RefereeName string
+    ReferrerID  int
+    Status      string
+    Reason      string
+    IP          string
+    CreatedAt   time.Time
+    RewardedAt  sql.NullTime
+}
+
+func GetReferralCode(accountID int) (string, error) {
+    var code string
+    err := DB.QueryRow("SELECT code FROM web_referral_codes WHERE account_id = ?", accountID).Scan(&code)
+    return code, err
+}
+
+// CreateReferralCode — false, если код уже занят другим аккаунтом
+func CreateReferralCode(accountID int, code string) (bool, error) {
+    result, err := DB.Exec("INSERT IGNORE INTO web_referral_codes (account_id, code, created_at) VALUES (?, ?, NOW())", accountID, code)
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+func GetReferrerByCode(code string) (int, error) {
+    var accountID int
+    err := DB.QueryRow("SELECT account_id FROM web_referral_codes WHERE code = ?", code).Scan(&accountID)
+    return accountID, err
+}
+
+// CountReferrals — сколько приглашенных у аккаунта без учета отклоненных
+func CountReferrals(referrerID int) (int, error) {
+    var count int
+    err := DB.QueryRow("SELECT COUNT(*) FROM web_referrals WHERE referrer_id = ? AND status <> ?", referrerID, ReferralRejected).Scan(&count)
+    return count, err
+}
+
+func CreateReferral(r *Referral) error {
+    _, err := DB.Exec(`
+        INSERT IGNORE INTO web_referrals (referee_id, referrer_id, status, reason, ip, created_at)
+        VALUES (?, ?, ?, ?, ?, NOW())
+    `, r.RefereeID, r.ReferrerID, r.Status, r.Reason, r.IP)
+    return err
+}
+
+const referralColumns = `
+    r.referee_id, COALESCE(a.username, ''), r.referrer_id, r.status, r.reason, r.ip, r.created_at, r.rewarded_at
+`
+
+func scanReferrals(rows *sql.Rows) ([]Referral, error) {
+    defer rows.Close()
+    
+    var referrals []Referral
+    for rows.Next() {
+        var r Referral
+        if err := rows.Scan(&r.RefereeID, &r.RefereeName, &r.ReferrerID, &r.Status, &r.Reason, &r.IP, &r.CreatedAt, &r.RewardedAt); err != nil {
+            return nil, err
+        }
+        referrals = append(referrals, r)
+    }
+    return referrals, rows.Err()
+}
+
+func GetReferralsByReferrer(referrerID int) ([]Referral, error) {
+    rows, err := DB.Query(`
+        SELECT `+referralColumns+`
+        FROM web_referrals r
+        LEFT JOIN account a ON a.id = r.referee_id
+        WHERE r.referrer_id = ?
+        ORDER BY r.created_at DESC
+    `, referrerID)
+    if err != nil {
+        return nil, err
+    }
+    return scanReferrals(rows)
+}
+
+// GetPendingReferrals — приглашения, чья очередь проверки подошла
+func GetPendingReferrals(limit int) ([]Referral, error) {
+    rows, err := DB.Query(`
+        SELECT `+referralColumns+`
+        FROM web_referrals r
+        LEFT JOIN account a ON a.id = r.referee_id
+        WHERE r.status = ? AND r.next_check_at <= NOW()
+        ORDER BY r.next_check_at
+        LIMIT ?
+    `, ReferralPending, limit)
+    if err != nil {
+        return nil, err
+    }
+    return scanReferrals(rows)
+}
+
+// PostponeReferrals откладывает следующую проверку приглашений на delay
+func PostponeReferrals(refereeIDs []int, delay time.Duration) error {
+    if len(refereeIDs) == 0 {
+        return nil
+    }
+    
+    args := []interface{}{int64(delay / time.Second)}
+    for _, id := range refereeIDs {
+        args = append(args, id)
+    }
+    _, err := DB.Exec(`
+        UPDATE web_referrals SET next_check_at = NOW() + INTERVAL ? SECOND
+        WHERE referee_id IN (`+sqlPlaceholders(len(refereeIDs))+`)
+    `, args...)
+    return err
+}
+
+// FinishReferral переводит приглашение из pending; false — уже обработано.
+// points, если задан, начисляется пригласившему в той же транзакции
+func FinishReferral(refereeID int, status, reason string, points *PointsEntry) (bool, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return false, err
+    }
+    defer tx.Rollback()
+    
+    result, err := tx.Exec(`
+        UPDATE web_referrals SET status = ?, reason = ?, rewarded_at = IF(? = ?, NOW(), NULL)
+        WHERE referee_id = ? AND status = ?
+    `, status, reason, status, ReferralRewarded, refereeID, ReferralPending)
+    if err != nil {
+        return false, err
+    }
+    if n, err := result.RowsAffected(); err != nil || n != 1 {
+        return false, err
+    }
+    
+    if points != nil {
+        if err := recordPointsTx(tx, points); err != nil {
+            return false, err
+        }
+    }
+    return true, tx.Commit()
+}
+
+// GetAccountIPs — все известные IP аккаунта: последний вход и история входов, если есть
+func GetAccountIPs(accountID int) (map[string]bool, error) {
+    ips := make(map[string]bool)
+    
+    var lastIP string
+    if err := DB.QueryRow("SELECT last_ip FROM account WHERE id = ?", accountID).Scan(&lastIP); err != nil {
+        return nil, err
+    }
+    if lastIP != "" && lastIP != "0.0.0.0" && lastIP != "127.0.0.1" {
+        ips[lastIP] = true
+    }
+    
+    history, err := GetLoginHistory(accountID, 100)
+    if err == nil {
+        for _, record := range history {
+            ips[record.IP] = true
+        }
+    }
+    return ips, nil
+}
+
+func GetAccountEmail(accountID int) (string, error) {
+    var email string
+    err := DB.QueryRow("SELECT email FROM account WHERE id = ?", accountID).Scan(&email)
+    return email, err
+}
+
+// GetAccountProgress — лучший уровень и суммарное /played аккаунта на реалме
+func GetAccountProgress(realmID, accountID int) (int, int64, error) {
+    db, err := CharsDB(realmID)
+    if err != nil {
+        return 0, 0, err
+    }
+    
+    var level int
+    var played int64
+    err = db.QueryRow("SELECT COALESCE(MAX(level), 0), COALESCE(SUM(totaltime), 0) FROM characters WHERE account = ?", accountID).Scan(&level, &played)
+    return level, played, err
+}
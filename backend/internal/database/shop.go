@@ -0,0 +1,315 @@
+package database
+
+import (
+    "database/sql"
+    "time"
+)
+
+// Виды товаров магазина
+const (
+    ProductItem    = "item"
+    ProductGold    = "gold"
+    ProductService = "service"
+    ProductLevel   = "level"
+)
+
+// Статусы web_shop_orders. sending ставится до отправки команды: если ответа
+// не дождались, заказ уходит в review, а не отправляется второй раз
+const (
+    OrderPending  = "pending"
+    OrderFailed   = "failed"
+    OrderPaid     = "paid"
+    OrderSending  = "sending"
+    OrderDone     = "done"
+    OrderRefunded = "refunded"
+    OrderReview   = "review"
+)
+
+type ShopProduct struct {
+    ID          int    `json:"id"`
+    Kind        string `json:"kind"`
+    Name        string `json:"name"`
+    Description string `json:"description"`
+    Payload     string `json:"payload"`
+    Price       int    `json:"price"`
+    SortOrder   int    `json:"sort_order"`
+    Enabled     bool   `json:"enabled"`
+}
+
+type ShopOrder struct {
+    ID             int64     `json:"id"`
+    AccountID      int       `json:"-"`
+    IdempotencyKey string    `json:"-"`
+    ProductID      int       `json:"product_id"`
+    Kind           string    `json:"kind"`
+    Name           string    `json:"name"`
+    Payload        string    `json:"-"`
+    Price          int       `json:"price"`
+    RealmID        int       `json:"realm_id"`
+    GUID           int       `json:"guid"`
+    CharacterName  string    `json:"character"`
+    Status         string    `json:"status"`
+    Attempts       int       `json:"attempts"`
+    Error          string    `json:"-"`
+    CreatedAt      time.Time `json:"created_at"`
+}
+
+const shopProductColumns = "id, kind, name, description, payload, price, sort_order, enabled"
+
+func scanShopProducts(rows *sql.Rows) ([]ShopProduct, error) {
+    defer rows.Close()
+    
+    var products []ShopProduct
+    for rows.Next() {
+        var p ShopProduct
+        if err := rows.Scan(&p.ID, &p.Kind, &p.Name, &p.Description, &p.Payload, &p.Price, &p.SortOrder, &p.Enabled); err != nil {
+            return nil, err
+        }
+        products = append(products, p)
+    }
+    return products, rows.Err()
+}
+
+// GetShopProducts — каталог; onlyEnabled скрывает снятые с продажи товары
+func GetShopProducts(onlyEnabled bool) ([]ShopProduct, error) {
+    query := "SELECT " + shopProductColumns + " FROM web_shop_products"
+    if onlyEnabled {
+        query += " WHERE enabled = 1"
+    }
+    rows, err := DB.Query(query + " ORDER BY sort_order, id")
+    if err != nil {
+        return nil, err
+    }
+    return scanShopProducts(rows)
+}
+
+func GetShopProduct(id int) (*ShopProduct, error) {
+    p := &ShopProduct{}
+    err := DB.QueryRow("SELECT "+shopProductColumns+" FROM web_shop_products WHERE id = ?", id).Scan(
+        &p.ID, &p.Kind, &p.Name, &p.Description, &p.Payload, &p.Price, &p.SortOrder, &p.Enabled,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return p, nil
+}
+
+// SaveShopProduct создает товар при ID == 0, иначе обновляет существующий
+func SaveShopProduct(p *ShopProduct) error {
+    if p.ID == 0 {
+        result, err := DB.Exec(`
+            INSERT INTO web_shop_products (kind, name, description, payload, price, sort_order, enabled, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, NOW())
+        `, p.Kind, p.Name, p.Description, p.Payload, p.Price, p.SortOrder, p.Enabled)
+        if err != nil {
+            return err
+        }
+        id, err := result.LastInsertId()
+        p.ID = int(id)
+        return err
+    }
+    
+    result, err := DB.Exec(`
+        UPDATE web_shop_products
+        SET kind = ?, name = ?, description = ?, payload = ?, price = ?, sort_order = ?, enabled = ?
+        WHERE id = ?
+    `, p.Kind, p.Name, p.Description, p.Payload, p.Price, p.SortOrder, p.Enabled, p.ID)
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        // Без изменений MySQL тоже вернет 0, поэтому проверяем, что строка есть
+        if _, err := GetShopProduct(p.ID); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// CreateShopOrder создает заказ со статусом pending. Если заказ с таким ключом
+// у аккаунта уже есть, возвращается false и ничего не создается
+func CreateShopOrder(o *ShopOrder) (bool, error) {
+    o.Status = OrderPending
+    o.CreatedAt = time.Now()
+    result, err := DB.Exec(`
+        INSERT IGNORE INTO web_shop_orders
+            (account_id, idempotency_key, product_id, kind, name, payload, price, realm_id, guid, character_name, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, o.AccountID, o.IdempotencyKey, o.ProductID, o.Kind, o.Name, o.Payload, o.Price, o.RealmID, o.GUID, o.CharacterName, o.Status, o.CreatedAt)
+    if err != nil {
+        return false, err
+    }
+    if n, err := result.RowsAffected(); err != nil || n == 0 {
+        return false, err
+    }
+    o.ID, err = result.LastInsertId()
+    return true, err
+}
+
+const shopOrderColumns = `
+    id, account_id, idempotency_key, product_id, kind, name, payload, price,
+    realm_id, guid, character_name, status, attempts, error, created_at
+`
+
+func scanShopOrder(row interface{ Scan(...interface{}) error }) (*ShopOrder, error) {
+    o := &ShopOrder{}
+    err := row.Scan(
+        &o.ID, &o.AccountID, &o.IdempotencyKey, &o.ProductID, &o.Kind, &o.Name, &o.Payload, &o.Price,
+        &o.RealmID, &o.GUID, &o.CharacterName, &o.Status, &o.Attempts, &o.Error, &o.CreatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return o, nil
+}
+
+func queryShopOrders(query string, args ...interface{}) ([]ShopOrder, error) {
+    rows, err := DB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var orders []ShopOrder
+    for rows.Next() {
+        o, err := scanShopOrder(rows)
+        if err != nil {
+            return nil, err
+        }
+        orders = append(orders, *o)
+    }
+    return orders, rows.Err()
+}
+
+func GetShopOrderByKey(accountID int, key string) (*ShopOrder, error) {
+    row := DB.QueryRow("SELECT "+shopOrderColumns+" FROM web_shop_orders WHERE account_id = ? AND idempotency_key = ?", accountID, key)
+    return scanShopOrder(row)
+}
+
+// GetAccountShopOrders — последние заказы аккаунта
+func GetAccountShopOrders(accountID, limit int) ([]ShopOrder, error) {
+    return queryShopOrders("SELECT "+shopOrderColumns+" FROM web_shop_orders WHERE account_id = ? ORDER BY id DESC LIMIT ?", accountID, limit)
+}
+
+// GetDueShopOrders — оплаченные заказы, которым пора повторить доставку
+func GetDueShopOrders(limit int) ([]ShopOrder, error) {
+    return queryShopOrders(`
+        SELECT `+shopOrderColumns+` FROM web_shop_orders
+        WHERE status = ? AND next_attempt_at <= NOW()
+        ORDER BY next_attempt_at
+        LIMIT ?
+    `, OrderPaid, limit)
+}
+
+// GetStaleShopOrders — заказы, застрявшие в статусе дольше age (процесс упал
+// посередине). updated_at ставит MySQL, с ним и сравниваем
+func GetStaleShopOrders(status string, age time.Duration, limit int) ([]ShopOrder, error) {
+    return queryShopOrders(`
+        SELECT `+shopOrderColumns+` FROM web_shop_orders
+        WHERE status = ? AND updated_at < NOW() - INTERVAL ? SECOND
+        ORDER BY id
+        LIMIT ?
+    `, status, int64(age/time.Second), limit)
+}
+
+// SetShopOrderStatus переводит заказ из from в to; false — статус уже сменил
+// кто-то другой
+func SetShopOrderStatus(id int64, from, to, message string) (bool, error) {
+    message = TruncateText(message, 255)
+    result, err := DB.Exec("UPDATE web_shop_orders SET status = ?, error = ? WHERE id = ? AND status = ?", to, message, id, from)
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+// ClaimShopOrder забирает оплаченный заказ на попытку доставки
+func ClaimShopOrder(id int64) (bool, error) {
+    result, err := DB.Exec(
+        "UPDATE web_shop_orders SET status = ?, attempts = attempts + 1 WHERE id = ? AND status = ?",
+        OrderSending, id, OrderPaid,
+    )
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+// MarkShopOrderPaid — поинты списаны, заказ встает в очередь доставки
+func MarkShopOrderPaid(id int64) (bool, error) {
+    result, err := DB.Exec(
+        "UPDATE web_shop_orders SET status = ?, next_attempt_at = NOW() WHERE id = ? AND status = ?",
+        OrderPaid, id, OrderPending,
+    )
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+// PayShopOrder списывает цену заказа и ставит его в очередь доставки одной
+// транзакцией: заказ не окажется оплаченным без списания и наоборот.
+// false — заказ уже не pending
+func PayShopOrder(id int64, points *PointsEntry) (bool, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return false, err
+    }
+    defer tx.Rollback()
+    
+    result, err := tx.Exec(
+        "UPDATE web_shop_orders SET status = ?, next_attempt_at = NOW() WHERE id = ? AND status = ?",
+        OrderPaid, id, OrderPending,
+    )
+    if err != nil {
+        return false, err
+    }
+    if n, err := result.RowsAffected(); err != nil || n != 1 {
+        return false, err
+    }
+    
+    if err := recordPointsTx(tx, points); err != nil {
+        return false, err
+    }
+    return true, tx.Commit()
+}
+
+// RefundShopOrder переводит заказ из sending в refunded и возвращает поинты
+// в одной транзакции. false — заказ уже ушел из sending, ничего не сделано
+func RefundShopOrder(id int64, message string, points *PointsEntry) (bool, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return false, err
+    }
+    defer tx.Rollback()
+    
+    result, err := tx.Exec(
+        "UPDATE web_shop_orders SET status = ?, error = ? WHERE id = ? AND status = ?",
+        OrderRefunded, TruncateText(message, 255), id, OrderSending,
+    )
+    if err != nil {
+        return false, err
+    }
+    if n, err := result.RowsAffected(); err != nil || n != 1 {
+        return false, err
+    }
+    
+    if err := recordPointsTx(tx, points); err != nil {
+        return false, err
+    }
+    return true, tx.Commit()
+}
+
+// RetryShopOrder возвращает заказ в очередь на delay. Срок считает MySQL:
+// очередь выбирается по next_attempt_at <= NOW(), и часы должны быть одни
+func RetryShopOrder(id int64, delay time.Duration, message string) error {
+    message = TruncateText(message, 255)
+    _, err := DB.Exec(
+        "UPDATE web_shop_orders SET status = ?, next_attempt_at = NOW() + INTERVAL ? SECOND, error = ? WHERE id = ? AND status = ?",
+        OrderPaid, int64(delay/time.Second), message, id, OrderSending,
+    )
+    return err
+}
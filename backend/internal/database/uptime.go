@@ -0,0 +1,39 @@
+package database
+
+import (
+    "database/sql"
+    "time"
+)
+
+// RealmUptime — последняя запись ядра в auth.uptime для реалма
+type RealmUptime struct {
+    StartedAt  time.Time     `json:"started_at"`
+    Uptime     time.Duration `json:"uptime"`
+    MaxPlayers int           `json:"max_players"`
+}
+
+func GetRealmUptime(realmID int) (*RealmUptime, error) {
+    query := `
+        SELECT starttime, uptime, maxplayers
+        FROM uptime
+        WHERE realmid = ?
+        ORDER BY starttime DESC
+        LIMIT 1
+    `
+    
+    var startTime, uptime int64
+    var maxPlayers int
+    err := DB.QueryRow(query, realmID).Scan(&startTime, &uptime, &maxPlayers)
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    
+    return &RealmUptime{
+        StartedAt:  time.Unix(startTime, 0),
+        Uptime:     time.Duration(uptime) * time.Second,
+        MaxPlayers: maxPlayers,
+    }, nil
+}
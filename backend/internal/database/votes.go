@@ -0,0 +1,199 @@
+package database
+
+import (
+    "database/sql"
+    "time"
+)
+
+// Статусы web_votes
+const (
+    VotePending  = "pending"
+    VoteCredited = "credited"
+    VoteRejected = "rejected"
+)
+
+// Состояния предметной награды за голос
+const (
+    RewardNone    = "none"
+    RewardQueued  = "queued"
+    RewardSending = "sending"
+    RewardSent    = "sent"
+    RewardFailed  = "failed"
+    RewardReview  = "review"
+)
+
+type Vote struct {
+    ID        int64
+    Site      string
+    AccountID int
+    IP        string
+    Token     string
+    RealmID   int
+    GUID      int
+    Status    string
+    CreatedAt time.Time
+    
+    RewardAttempts int
+}
+
+func CreateVote(v *Vote) error {
+    result, err := DB.Exec(`
+        INSERT INTO web_votes (site, account_id, ip, token, realm_id, guid, status, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, NOW())
+    `, v.Site, v.AccountID, v.IP, v.Token, v.RealmID, v.GUID, VotePending)
+    if err != nil {
+        return err
+    }
+    v.ID, err = result.LastInsertId()
+    return err
+}
+
+func GetVoteByToken(site, token string) (*Vote, error) {
+    v := &Vote{}
+    err := DB.QueryRow(`
+        SELECT id, site, account_id, ip, token, realm_id, guid, status, created_at
+        FROM web_votes WHERE site = ? AND token = ?
+    `, site, token).Scan(&v.ID, &v.Site, &v.AccountID, &v.IP, &v.Token, &v.RealmID, &v.GUID, &v.Status, &v.CreatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return v, nil
+}
+
+// VoteCooldown — сколько еще ждать до голоса на топе с аккаунта или IP.
+// credited_at ставит NOW(), поэтому и остаток считает MySQL по своим часам
+func VoteCooldown(site string, accountID int, ip string, cooldown time.Duration) (time.Duration, error) {
+    return voteCooldown(DB.QueryRow(voteCooldownQuery, VoteCredited, int64(cooldown/time.Second), site, accountID, ip))
+}
+
+const voteCooldownQuery = `
+    SELECT TIMESTAMPDIFF(SECOND, NOW(), MAX(IF(status = ?, credited_at, NULL)) + INTERVAL ? SECOND) FROM web_votes
+    WHERE site = ? AND (account_id = ? OR ip = ?)
+`
+
+func voteCooldown(row *sql.Row) (time.Duration, error) {
+    var remaining sql.NullInt64
+    if err := row.Scan(&remaining); err != nil || !remaining.Valid || remaining.Int64 <= 0 {
+        return 0, err
+    }
+    return time.Duration(remaining.Int64) * time.Second, nil
+}
+
+// FinishVote засчитывает pending-голос или отклоняет его, если кулдаун еще
+// идет. Голоса аккаунта и IP на топе блокируются (среди них всегда есть сам
+// голос), так что два postback не засчитают оба голоса. Поинты начисляются в
+// той же транзакции; reward ставит письмо с предметом в очередь.
+// Возвращает остаток кулдауна для отклоненного голоса; false — голос уже
+// обработал другой postback
+func FinishVote(v *Vote, cooldown time.Duration, points *PointsEntry, reward bool) (time.Duration, bool, error) {
+    tx, err := DB.Begin()
+    if err != nil {
+        return 0, false, err
+    }
+    defer tx.Rollback()
+    
+    remaining, err := voteCooldown(tx.QueryRow(voteCooldownQuery+" FOR UPDATE",
+        VoteCredited, int64(cooldown/time.Second), v.Site, v.AccountID, v.IP))
+    if err != nil {
+        return 0, false, err
+    }
+    
+    var result sql.Result
+    if remaining > 0 {
+        result, err = tx.Exec("UPDATE web_votes SET status = ? WHERE id = ? AND status = ?", VoteRejected, v.ID, VotePending)
+    } else {
+        state := RewardNone
+        if reward {
+            state = RewardQueued
+        }
+        result, err = tx.Exec(`
+            UPDATE web_votes SET status = ?, credited_at = NOW(), reward_state = ?, next_attempt_at = NOW()
+            WHERE id = ? AND status = ?
+        `, VoteCredited, state, v.ID, VotePending)
+    }
+    if err != nil {
+        return 0, false, err
+    }
+    if n, err := result.RowsAffected(); err != nil || n != 1 {
+        return 0, false, err
+    }
+    
+    if remaining <= 0 && points != nil {
+        if err := recordPointsTx(tx, points); err != nil {
+            return 0, false, err
+        }
+    }
+    return remaining, true, tx.Commit()
+}
+
+const voteRewardColumns = "id, site, account_id, ip, token, realm_id, guid, status, created_at, reward_attempts"
+
+func queryVotes(query string, args ...interface{}) ([]Vote, error) {
+    rows, err := DB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var votes []Vote
+    for rows.Next() {
+        var v Vote
+        if err := rows.Scan(&v.ID, &v.Site, &v.AccountID, &v.IP, &v.Token, &v.RealmID, &v.GUID, &v.Status, &v.CreatedAt, &v.RewardAttempts); err != nil {
+            return nil, err
+        }
+        votes = append(votes, v)
+    }
+    return votes, rows.Err()
+}
+
+// GetDueVoteRewards — голоса, письмо за которые пора отправить
+func GetDueVoteRewards(limit int) ([]Vote, error) {
+    return queryVotes(`
+        SELECT `+voteRewardColumns+` FROM web_votes
+        WHERE reward_state = ? AND next_attempt_at <= NOW()
+        ORDER BY next_attempt_at
+        LIMIT ?
+    `, RewardQueued, limit)
+}
+
+// GetStaleVoteRewards — письма, отправка которых прервалась дольше age назад
+func GetStaleVoteRewards(age time.Duration, limit int) ([]Vote, error) {
+    return queryVotes(`
+        SELECT `+voteRewardColumns+` FROM web_votes
+        WHERE reward_state = ? AND next_attempt_at < NOW() - INTERVAL ? SECOND
+        ORDER BY id
+        LIMIT ?
+    `, RewardSending, int64(age/time.Second), limit)
+}
+
+// ClaimVoteReward забирает письмо на попытку отправки; next_attempt_at
+// запоминает начало попытки, по нему находятся прерванные
+func ClaimVoteReward(id int64) (bool, error) {
+    result, err := DB.Exec(`
+        UPDATE web_votes SET reward_state = ?, reward_attempts = reward_attempts + 1, next_attempt_at = NOW()
+        WHERE id = ? AND reward_state = ?
+    `, RewardSending, id, RewardQueued)
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+// SetVoteRewardState переводит письмо из sending в state
+func SetVoteRewardState(id int64, state, message string) error {
+    _, err := DB.Exec(
+        "UPDATE web_votes SET reward_state = ?, reward_error = ? WHERE id = ? AND reward_state = ?",
+        state, TruncateText(message, 255), id, RewardSending,
+    )
+    return err
+}
+
+// RetryVoteReward возвращает письмо в очередь на delay
+func RetryVoteReward(id int64, delay time.Duration, message string) error {
+    _, err := DB.Exec(`
+        UPDATE web_votes SET reward_state = ?, next_attempt_at = NOW() + INTERVAL ? SECOND, reward_error = ?
+        WHERE id = ? AND reward_state = ?
+    `, RewardQueued, int64(delay/time.Second), TruncateText(message, 255), id, RewardSending)
+    return err
+}
@@ -0,0 +1,282 @@
+package database
+
+import (
+    "database/sql"
+    "strings"
+    "time"
+)
+
+// Статусы web_webhook_deliveries. sending ставится на время запроса: если
+// процесс упал посередине, доставка возвращается в очередь
+const (
+    DeliveryPending = "pending"
+    DeliverySending = "sending"
+    DeliveryDone    = "done"
+    DeliveryFailed  = "failed"
+)
+
+type Webhook struct {
+    ID          int       `json:"id"`
+    URL         string    `json:"url"`
+    Secret      string    `json:"-"`
+    Events      []string  `json:"events"`
+    Description string    `json:"description"`
+    Enabled     bool      `json:"enabled"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+// Subscribed — подписан ли webhook на событие
+func (w Webhook) Subscribed(event string) bool {
+    for _, e := range w.Events {
+        if e == event {
+            return true
+        }
+    }
+    return false
+}
+
+type WebhookDelivery struct {
+    ID           int64      `json:"id"`
+    WebhookID    int        `json:"webhook_id"`
+    EventID      string     `json:"event_id"`
+    Event        string     `json:"event"`
+    Payload      string     `json:"payload"`
+    Status       string     `json:"status"`
+    Attempts     int        `json:"attempts"`
+    ResponseCode int        `json:"response_code"`
+    Error        string     `json:"error"`
+    CreatedAt    time.Time  `json:"created_at"`
+    DeliveredAt  *time.Time `json:"delivered_at,omitempty"`
+}
+
+const webhookColumns = "id, url, secret, events, description, enabled, created_at"
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*Webhook, error) {
+    w := &Webhook{}
+    var events string
+    if err := row.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.Description, &w.Enabled, &w.CreatedAt); err != nil {
+        return nil, err
+    }
+    if events != "" {
+        w.Events = strings.Split(events, ",")
+    }
+    return w, nil
+}
+
+// GetWebhooks — все webhook'и; onlyEnabled — только включенные
+func GetWebhooks(onlyEnabled bool) ([]Webhook, error) {
+    query := "SELECT " + webhookColumns + " FROM web_webhooks"
+    if onlyEnabled {
+        query += " WHERE enabled = 1"
+    }
+    rows, err := DB.Query(query + " ORDER BY id")
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var hooks []Webhook
+    for rows.Next() {
+        w, err := scanWebhook(rows)
+        if err != nil {
+            return nil, err
+        }
+        hooks = append(hooks, *w)
+    }
+    return hooks, rows.Err()
+}
+
+func GetWebhook(id int) (*Webhook, error) {
+    return scanWebhook(DB.QueryRow("SELECT "+webhookColumns+" FROM web_webhooks WHERE id = ?", id))
+}
+
+// SaveWebhook создает webhook при ID == 0, иначе обновляет существующий.
+// Секрет задается только при создании
+func SaveWebhook(w *Webhook) error {
+    events := strings.Join(w.Events, ",")
+    if w.ID == 0 {
+        w.CreatedAt = time.Now()
+        result, err := DB.Exec(`
+            INSERT INTO web_webhooks (url, secret, events, description, enabled, created_at)
+            VALUES (?, ?, ?, ?, ?, ?)
+        `, w.URL, w.Secret, events, w.Description, w.Enabled, w.CreatedAt)
+        if err != nil {
+            return err
+        }
+        id, err := result.LastInsertId()
+        w.ID = int(id)
+        return err
+    }
+    
+    result, err := DB.Exec(
+        "UPDATE web_webhooks SET url = ?, events = ?, description = ?, enabled = ? WHERE id = ?",
+        w.URL, events, w.Description, w.Enabled, w.ID,
+    )
+    if err != nil {
+        return err
+    }
+    if n, err := result.RowsAffected(); err == nil && n == 0 {
+        // Без изменений MySQL тоже вернет 0, поэтому проверяем, что строка есть
+        if _, err := GetWebhook(w.ID); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// SetWebhookSecret меняет секрет подписи
+func SetWebhookSecret(id int, secret string) error {
+    _, err := DB.Exec("UPDATE web_webhooks SET secret = ? WHERE id = ?", secret, id)
+    return err
+}
+
+// DeleteWebhook удаляет webhook вместе с журналом доставок
+func DeleteWebhook(id int) error {
+    tx, err := DB.Begin()
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    if _, err := tx.Exec("DELETE FROM web_webhook_deliveries WHERE webhook_id = ?", id); err != nil {
+        return err
+    }
+    if _, err := tx.Exec("DELETE FROM web_webhooks WHERE id = ?", id); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// CreateWebhookDelivery ставит событие в очередь на немедленную отправку.
+// Все сроки очереди пишет и сравнивает MySQL через NOW(), часы Go в них не участвуют
+func CreateWebhookDelivery(d *WebhookDelivery) error {
+    d.Status = DeliveryPending
+    d.CreatedAt = time.Now()
+    result, err := DB.Exec(`
+        INSERT INTO web_webhook_deliveries (webhook_id, event_id, event, payload, status, next_attempt_at, created_at)
+        VALUES (?, ?, ?, ?, ?, NOW(), NOW())
+    `, d.WebhookID, d.EventID, d.Event, d.Payload, d.Status)
+    if err != nil {
+        return err
+    }
+    d.ID, err = result.LastInsertId()
+    return err
+}
+
+const webhookDeliveryColumns = `
+    id, webhook_id, event_id, event, payload, status, attempts, response_code, error, created_at, delivered_at
+`
+
+func queryWebhookDeliveries(query string, args ...interface{}) ([]WebhookDelivery, error) {
+    rows, err := DB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var deliveries []WebhookDelivery
+    for rows.Next() {
+        var d WebhookDelivery
+        var delivered sql.NullTime
+        if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+            &d.ResponseCode, &d.Error, &d.CreatedAt, &delivered); err != nil {
+            return nil, err
+        }
+        if delivered.Valid {
+            d.DeliveredAt = &delivered.Time
+        }
+        deliveries = append(deliveries, d)
+    }
+    return deliveries, rows.Err()
+}
+
+func GetWebhookDelivery(id int64) (*WebhookDelivery, error) {
+    deliveries, err := queryWebhookDeliveries("SELECT "+webhookDeliveryColumns+" FROM web_webhook_deliveries WHERE id = ?", id)
+    if err != nil {
+        return nil, err
+    }
+    if len(deliveries) == 0 {
+        return nil, sql.ErrNoRows
+    }
+    return &deliveries[0], nil
+}
+
+// GetWebhookDeliveries — журнал доставок, новые сверху; webhookID 0 — всех webhook'ов
+func GetWebhookDeliveries(webhookID, limit int) ([]WebhookDelivery, error) {
+    if webhookID > 0 {
+        return queryWebhookDeliveries(
+            "SELECT "+webhookDeliveryColumns+" FROM web_webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?",
+            webhookID, limit,
+        )
+    }
+    return queryWebhookDeliveries("SELECT "+webhookDeliveryColumns+" FROM web_webhook_deliveries ORDER BY id DESC LIMIT ?", limit)
+}
+
+// GetDueWebhookDeliveries — доставки, которым пора уйти
+func GetDueWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+    return queryWebhookDeliveries(`
+        SELECT `+webhookDeliveryColumns+` FROM web_webhook_deliveries
+        WHERE status = ? AND next_attempt_at <= NOW()
+        ORDER BY next_attempt_at
+        LIMIT ?
+    `, DeliveryPending, limit)
+}
+
+// ClaimWebhookDelivery забирает доставку на попытку отправки
+func ClaimWebhookDelivery(id int64) (bool, error) {
+    result, err := DB.Exec(
+        "UPDATE web_webhook_deliveries SET status = ?, attempts = attempts + 1 WHERE id = ? AND status = ?",
+        DeliverySending, id, DeliveryPending,
+    )
+    if err != nil {
+        return false, err
+    }
+    n, err := result.RowsAffected()
+    return n == 1, err
+}
+
+func trimDeliveryError(message string) string {
+    return TruncateText(message, 512)
+}
+
+// FinishWebhookDelivery — попытка завершена: done при успехе, иначе failed
+func FinishWebhookDelivery(id int64, status string, code int, message string) error {
+    query := "UPDATE web_webhook_deliveries SET status = ?, response_code = ?, error = ?, next_attempt_at = NULL WHERE id = ? AND status = ?"
+    if status == DeliveryDone {
+        query = "UPDATE web_webhook_deliveries SET status = ?, response_code = ?, error = ?, next_attempt_at = NULL, delivered_at = NOW() WHERE id = ? AND status = ?"
+    }
+    _, err := DB.Exec(query, status, code, trimDeliveryError(message), id, DeliverySending)
+    return err
+}
+
+// RetryWebhookDelivery возвращает доставку в очередь на delay
+func RetryWebhookDelivery(id int64, delay time.Duration, code int, message string) error {
+    _, err := DB.Exec(
+        "UPDATE web_webhook_deliveries SET status = ?, next_attempt_at = NOW() + INTERVAL ? SECOND, response_code = ?, error = ? WHERE id = ? AND status = ?",
+        DeliveryPending, int64(delay/time.Second), code, trimDeliveryError(message), id, DeliverySending,
+    )
+    return err
+}
+
+// RequeueStaleWebhookDeliveries возвращает в очередь доставки, зависшие в
+// sending дольше age. Получатель может увидеть событие дважды — для этого
+// у него есть event_id
+func RequeueStaleWebhookDeliveries(age time.Duration) (int64, error) {
+    result, err := DB.Exec(
+        "UPDATE web_webhook_deliveries SET status = ?, next_attempt_at = NOW() WHERE status = ? AND updated_at < NOW() - INTERVAL ? SECOND",
+        DeliveryPending, DeliverySending, int64(age/time.Second),
+    )
+    if err != nil {
+        return 0, err
+    }
+    return result.RowsAffected()
+}
+
+// PruneWebhookDeliveries удаляет завершенные доставки старше age
+func PruneWebhookDeliveries(age time.Duration) error {
+    _, err := DB.Exec(
+        "DELETE FROM web_webhook_deliveries WHERE status IN (?, ?) AND created_at < NOW() - INTERVAL ? SECOND",
+        DeliveryDone, DeliveryFailed, int64(age/time.Second),
+    )
+    return err
+}
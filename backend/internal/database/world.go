@@ -0,0 +1,52 @@
+package database
+
+import (
+    "strings"
+)
+
+type ItemTemplate struct {
+    Entry         int    `json:"entry"`
+    Name          string `json:"name"`
+    Quality       int    `json:"quality"`
+    ItemLevel     int    `json:"item_level"`
+    InventoryType int    `json:"inventory_type"`
+    MaxDurability int    `json:"-"`
+    Stackable     int    `json:"-"`
+}
+
+// GetItemTemplates загружает шаблоны предметов из world.item_template
+func GetItemTemplates(entries []int) (map[int]ItemTemplate, error) {
+    items := make(map[int]ItemTemplate)
+    if WorldDB == nil || len(entries) == 0 {
+        return items, nil
+    }
+    
+    placeholders := make([]string, len(entries))
+    args := make([]interface{}, len(entries))
+    for i, entry := range entries {
+        placeholders[i] = "?"
+        args[i] = entry
+    }
+    
+    query := `
+        SELECT entry, name, Quality, ItemLevel, InventoryType, MaxDurability, stackable
+        FROM item_template
+        WHERE entry IN (` + strings.Join(placeholders, ", ") + `)
+    `
+    
+    rows, err := WorldDB.Query(query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    for rows.Next() {
+        var item ItemTemplate
+        if err := rows.Scan(&item.Entry, &item.Name, &item.Quality, &item.ItemLevel, &item.InventoryType,
+            &item.MaxDurability, &item.Stackable); err != nil {
+            return nil, err
+        }
+        items[item.Entry] = item
+    }
+    return items, rows.Err()
+}
@@ -0,0 +1,67 @@
+// Package discordtest — фейковый Discord для проверки уведомлений: принимает
+// сообщения webhook'ов и умеет отвечать 429, как настоящий
+package discordtest
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "wow-registration/internal/discord"
+)
+
+type Server struct {
+    *httptest.Server
+    
+    mu          sync.Mutex
+    messages    []discord.Message
+    rateLimited int
+    retryAfter  float64
+}
+
+func NewServer() *Server {
+    s := &Server{}
+    s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+    return s
+}
+
+// WebhookURL — адрес webhook'а на этом сервере
+func (s *Server) WebhookURL() string {
+    return s.URL + "/api/webhooks/1/test-token"
+}
+
+// RateLimit — следующие n запросов получат 429 с retry_after в секундах
+func (s *Server) RateLimit(n int, retryAfter float64) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.rateLimited, s.retryAfter = n, retryAfter
+}
+
+// Messages — принятые сообщения по порядку
+func (s *Server) Messages() []discord.Message {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]discord.Message(nil), s.messages...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    if s.rateLimited > 0 {
+        s.rateLimited--
+        w.Header().Set("Content-Type", "application/json")
+        w.WriteHeader(http.StatusTooManyRequests)
+        fmt.Fprintf(w, `{"message": "You are being rate limited.", "retry_after": %g, "global": false}`, s.retryAfter)
+        return
+    }
+    
+    var msg discord.Message
+    if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+        http.Error(w, `{"message": "Cannot send an empty message", "code": 50006}`, http.StatusBadRequest)
+        return
+    }
+    s.messages = append(s.messages, msg)
+    w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,183 @@
+// Package discord — отправка сообщений в канал Discord через webhook. Сообщения
+// уходят из очереди в отдельной горутине, ответы 429 выдерживаются по retry_after
+package discord
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+    "time"
+)
+
+const (
+    maxAttempts   = 3
+    maxRetryAfter = time.Minute
+)
+
+var ErrQueueFull = errors.New("discord: queue is full")
+
+type Message struct {
+    Content  string  `json:"content,omitempty"`
+    Username string  `json:"username,omitempty"`
+    Embeds   []Embed `json:"embeds,omitempty"`
+}
+
+type Embed struct {
+    Title       string     `json:"title,omitempty"`
+    Description string     `json:"description,omitempty"`
+    URL         string     `json:"url,omitempty"`
+    Color       int        `json:"color,omitempty"`
+    Fields      []Field    `json:"fields,omitempty"`
+    Footer      *Footer    `json:"footer,omitempty"`
+    Timestamp   *time.Time `json:"timestamp,omitempty"`
+}
+
+type Field struct {
+    Name   string `json:"name"`
+    Value  string `json:"value"`
+    Inline bool   `json:"inline,omitempty"`
+}
+
+type Footer struct {
+    Text string `json:"text"`
+}
+
+// Notifier — webhook с ограниченной очередью: при переполнении сообщения
+// отбрасываются, а не копятся в памяти и не тормозят запросы игроков
+type Notifier struct {
+    webhookURL string
+    queue      chan Message
+    httpClient *http.Client
+}
+
+// NewNotifier создает отправителя. baseURL, если задан, заменяет схему и хост
+// webhook'а — так сообщения можно направить на локальный фейк
+func NewNotifier(webhookURL, baseURL string, queueSize int) (*Notifier, error) {
+    target, err := url.Parse(webhookURL)
+    if err != nil || target.Host == "" {
+        return nil, fmt.Errorf("discord: invalid webhook url")
+    }
+    if baseURL != "" {
+        base, err := url.Parse(baseURL)
+        if err != nil || base.Host == "" {
+            return nil, fmt.Errorf("discord: invalid base url %q", baseURL)
+        }
+        target.Scheme, target.Host = base.Scheme, base.Host
+    }
+    if queueSize <= 0 {
+        queueSize = 100
+    }
+    
+    return &Notifier{
+        webhookURL: target.String(),
+        queue:      make(chan Message, queueSize),
+        httpClient: &http.Client{Timeout: 10 * time.Second},
+    }, nil
+}
+
+// Enqueue ставит сообщение в очередь без ожидания
+func (n *Notifier) Enqueue(msg Message) error {
+    select {
+    case n.queue <- msg:
+        return nil
+    default:
+        return ErrQueueFull
+    }
+}
+
+// Run отправляет сообщения по одному, пока не отменен ctx
+func (n *Notifier) Run(ctx context.Context) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case msg := <-n.queue:
+            if err := n.deliver(ctx, msg); err != nil {
+                log.Printf("discord: %v", err)
+            }
+        }
+    }
+}
+
+func (n *Notifier) deliver(ctx context.Context, msg Message) error {
+    body, err := json.Marshal(msg)
+    if err != nil {
+        return err
+    }
+    
+    for attempt := 1; ; attempt++ {
+        wait, err := n.post(ctx, body)
+        if err == nil || wait == 0 || attempt == maxAttempts {
+            return err
+        }
+        
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case <-time.After(wait):
+        }
+    }
+}
+
+// post отправляет сообщение; wait > 0 — Discord просит повторить через wait
+func (n *Notifier) post(ctx context.Context, body []byte) (time.Duration, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+    if err != nil {
+        return 0, err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    
+    resp, err := n.httpClient.Do(req)
+    if err != nil {
+        return time.Second, err
+    }
+    defer resp.Body.Close()
+    data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+    
+    if resp.StatusCode == http.StatusTooManyRequests {
+        return retryAfter(resp, data), fmt.Errorf("rate limited")
+    }
+    if resp.StatusCode >= 300 {
+        if resp.StatusCode >= 500 {
+            return time.Second, fmt.Errorf("webhook: %d %s", resp.StatusCode, data)
+        }
+        return 0, fmt.Errorf("webhook: %d %s", resp.StatusCode, data)
+    }
+    
+    // Бакет исчерпан: следующий запрос все равно получит 429, ждем заранее
+    if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+        if reset, err := strconv.ParseFloat(resp.Header.Get("X-RateLimit-Reset-After"), 64); err == nil {
+            time.Sleep(clampWait(reset))
+        }
+    }
+    return 0, nil
+}
+
+// retryAfter — пауза из тела 429 (retry_after в секундах) или заголовка Retry-After
+func retryAfter(resp *http.Response, body []byte) time.Duration {
+    var payload struct {
+        RetryAfter float64 `json:"retry_after"`
+    }
+    if json.Unmarshal(body, &payload) == nil && payload.RetryAfter > 0 {
+        return clampWait(payload.RetryAfter)
+    }
+    if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+        return clampWait(seconds)
+    }
+    return time.Second
+}
+
+func clampWait(seconds float64) time.Duration {
+    wait := time.Duration(seconds * float64(time.Second))
+    if wait > maxRetryAfter {
+        return maxRetryAfter
+    }
+    return wait
+}
@@ -0,0 +1,51 @@
+package discord_test
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "wow-registration/internal/discord"
+    "wow-registration/internal/discord/discordtest"
+)
+
+// Сообщение уходит на фейк через baseURL и доходит после ответа 429
+func TestNotifierRetriesRateLimit(t *testing.T) {
+    server := discordtest.NewServer()
+    defer server.Close()
+    server.RateLimit(1, 0.05)
+    
+    notifier, err := discord.NewNotifier("https://discord.com/api/webhooks/1/test-token", server.URL, 10)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    go notifier.Run(ctx)
+    
+    if err := notifier.Enqueue(discord.Message{Content: "Realm is online"}); err != nil {
+        t.Fatal(err)
+    }
+    
+    deadline := time.Now().Add(2 * time.Second)
+    for len(server.Messages()) == 0 && time.Now().Before(deadline) {
+        time.Sleep(10 * time.Millisecond)
+    }
+    messages := server.Messages()
+    if len(messages) != 1 || messages[0].Content != "Realm is online" {
+        t.Fatalf("messages: %+v", messages)
+    }
+}
+
+func TestNotifierQueueFull(t *testing.T) {
+    notifier, err := discord.NewNotifier("https://discord.com/api/webhooks/1/test-token", "", 1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := notifier.Enqueue(discord.Message{Content: "first"}); err != nil {
+        t.Fatal(err)
+    }
+    if err := notifier.Enqueue(discord.Message{Content: "second"}); !errors.Is(err, discord.ErrQueueFull) {
+        t.Fatalf("Enqueue: got %v, want ErrQueueFull", err)
+    }
+}
@@ -0,0 +1,72 @@
+package gamedata
+
+const (
+    ExpansionClassic   = 0
+    ExpansionTBC       = 1
+    ExpansionWotLK     = 2
+    ExpansionCataclysm = 3
+    ExpansionMoP       = 4
+)
+
+type Class struct {
+    ID        int
+    Name      string
+    Slug      string
+    Color     string
+    Expansion int
+}
+
+var classes = []Class{
+    {ID: 1, Name: "Warrior", Slug: "warrior", Color: "#C79C6E", Expansion: ExpansionClassic},
+    {ID: 2, Name: "Paladin", Slug: "paladin", Color: "#F58CBA", Expansion: ExpansionClassic},
+    {ID: 3, Name: "Hunter", Slug: "hunter", Color: "#ABD473", Expansion: ExpansionClassic},
+    {ID: 4, Name: "Rogue", Slug: "rogue", Color: "#FFF569", Expansion: ExpansionClassic},
+    {ID: 5, Name: "Priest", Slug: "priest", Color: "#FFFFFF", Expansion: ExpansionClassic},
+    {ID: 6, Name: "Death Knight", Slug: "deathknight", Color: "#C41F3B", Expansion: ExpansionWotLK},
+    {ID: 7, Name: "Shaman", Slug: "shaman", Color: "#0070DE", Expansion: ExpansionClassic},
+    {ID: 8, Name: "Mage", Slug: "mage", Color: "#69CCF0", Expansion: ExpansionClassic},
+    {ID: 9, Name: "Warlock", Slug: "warlock", Color: "#9482C9", Expansion: ExpansionClassic},
+    {ID: 10, Name: "Monk", Slug: "monk", Color: "#00FF96", Expansion: ExpansionMoP},
+    {ID: 11, Name: "Druid", Slug: "druid", Color: "#FF7D0A", Expansion: ExpansionClassic},
+}
+
+func GetClass(id int) (Class, bool) {
+    for _, c := range classes {
+        if c.ID == id {
+            return c, true
+        }
+    }
+    return Class{}, false
+}
+
+// Classes возвращает классы, доступные в указанном дополнении
+func Classes(expansion int) []Class {
+    var list []Class
+    for _, c := range classes {
+        if c.Expansion <= expansion {
+            list = append(list, c)
+        }
+    }
+    return list
+}
+
+func ClassName(class int) string {
+    if c, ok := GetClass(class); ok {
+        return c.Name
+    }
+    return "Unknown"
+}
+
+func ClassColor(class int) string {
+    if c, ok := GetClass(class); ok {
+        return c.Color
+    }
+    return "#9CA3AF"
+}
+
+func ClassIcon(class int) string {
+    if c, ok := GetClass(class); ok {
+        return IconBaseURL + "classicon_" + c.Slug + ".jpg"
+    }
+    return IconBaseURL + "inv_misc_questionmark.jpg"
+}
@@ -0,0 +1,121 @@
+package gamedata
+
+import (
+    "strconv"
+)
+
+type ReputationRank struct {
+    Name  string
+    Min   int
+    Max   int
+    Color string
+}
+
+// Пороги репутации относительно нуля (Neutral = 0..2999)
+var reputationRanks = []ReputationRank{
+    {Name: "Hated", Min: -42000, Max: -6000, Color: "#CC2222"},
+    {Name: "Hostile", Min: -6000, Max: -3000, Color: "#FF0000"},
+    {Name: "Unfriendly", Min: -3000, Max: 0, Color: "#EE6622"},
+    {Name: "Neutral", Min: 0, Max: 3000, Color: "#FFFF00"},
+    {Name: "Friendly", Min: 3000, Max: 9000, Color: "#00FF00"},
+    {Name: "Honored", Min: 9000, Max: 21000, Color: "#00FF88"},
+    {Name: "Revered", Min: 21000, Max: 42000, Color: "#00FFCC"},
+    {Name: "Exalted", Min: 42000, Max: 42999, Color: "#00FFFF"},
+}
+
+// Основные фракции по Faction.dbc (Classic — WotLK)
+var factions = map[int]string{
+    // Alliance
+    47:  "Ironforge",
+    54:  "Gnomeregan Exiles",
+    69:  "Darnassus",
+    72:  "Stormwind",
+    930: "Exodar",
+    // Horde
+    68:  "Undercity",
+    76:  "Orgrimmar",
+    81:  "Thunder Bluff",
+    530: "Darkspear Trolls",
+    911: "Silvermoon City",
+    // Classic
+    21:  "Booty Bay",
+    59:  "Thorium Brotherhood",
+    87:  "Bloodsail Buccaneers",
+    270: "Zandalar Tribe",
+    349: "Ravenholdt",
+    369: "Gadgetzan",
+    470: "Ratchet",
+    509: "The League of Arathor",
+    510: "The Defilers",
+    529: "Argent Dawn",
+    576: "Timbermaw Hold",
+    577: "Everlook",
+    609: "Cenarion Circle",
+    729: "Frostwolf Clan",
+    730: "Stormpike Guard",
+    749: "Hydraxian Waterlords",
+    809: "Shen'dralar",
+    889: "Warsong Outriders",
+    890: "Silverwing Sentinels",
+    909: "Darkmoon Faire",
+    910: "Brood of Nozdormu",
+    // The Burning Crusade
+    932:  "The Aldor",
+    933:  "The Consortium",
+    934:  "The Scryers",
+    935:  "The Sha'tar",
+    941:  "The Mag'har",
+    942:  "Cenarion Expedition",
+    946:  "Honor Hold",
+    947:  "Thrallmar",
+    967:  "The Violet Eye",
+    970:  "Sporeggar",
+    978:  "Kurenai",
+    989:  "Keepers of Time",
+    990:  "The Scale of the Sands",
+    1011: "Lower City",
+    1012: "Ashtongue Deathsworn",
+    1015: "Netherwing",
+    1031: "Sha'tari Skyguard",
+    1038: "Ogri'la",
+    1077: "Shattered Sun Offensive",
+    // Wrath of the Lich King
+    1037: "Alliance Vanguard",
+    1050: "Valiance Expedition",
+    1052: "Horde Expedition",
+    1064: "The Taunka",
+    1067: "The Hand of Vengeance",
+    1068: "Explorers' League",
+    1073: "The Kalu'ak",
+    1085: "Warsong Offensive",
+    1090: "Kirin Tor",
+    1091: "The Wyrmrest Accord",
+    1094: "The Silver Covenant",
+    1098: "Knights of the Ebon Blade",
+    1104: "Frenzyheart Tribe",
+    1105: "The Oracles",
+    1106: "Argent Crusade",
+    1119: "The Sons of Hodir",
+    1124: "The Sunreavers",
+    1126: "The Frostborn",
+    1156: "The Ashen Verdict",
+}
+
+func FactionName(id int) string {
+    if name, ok := factions[id]; ok {
+        return name
+    }
+    return "Faction #" + strconv.Itoa(id)
+}
+
+// ReputationStanding переводит накопленное значение в ранг и прогресс внутри него.
+// Базовая репутация расы в character_reputation не хранится; для большинства фракций она равна нулю.
+func ReputationStanding(standing int) (rank ReputationRank, value, max int) {
+    for _, r := range reputationRanks {
+        if standing < r.Max {
+            return r, standing - r.Min, r.Max - r.Min
+        }
+    }
+    r := reputationRanks[len(reputationRanks)-1]
+    return r, r.Max - r.Min, r.Max - r.Min
+}
@@ -0,0 +1,63 @@
+package gamedata
+
+// Слоты экипировки (EquipmentSlots в ядре)
+var equipmentSlots = []string{
+    "Head",
+    "Neck",
+    "Shoulder",
+    "Shirt",
+    "Chest",
+    "Waist",
+    "Legs",
+    "Feet",
+    "Wrist",
+    "Hands",
+    "Finger",
+    "Finger",
+    "Trinket",
+    "Trinket",
+    "Back",
+    "Main Hand",
+    "Off Hand",
+    "Ranged",
+    "Tabard",
+}
+
+const EquipmentSlotCount = 19
+
+type ItemQuality struct {
+    Name  string
+    Color string
+}
+
+var itemQualities = []ItemQuality{
+    {Name: "Poor", Color: "#9D9D9D"},
+    {Name: "Common", Color: "#FFFFFF"},
+    {Name: "Uncommon", Color: "#1EFF00"},
+    {Name: "Rare", Color: "#0070DD"},
+    {Name: "Epic", Color: "#A335EE"},
+    {Name: "Legendary", Color: "#FF8000"},
+    {Name: "Artifact", Color: "#E6CC80"},
+    {Name: "Heirloom", Color: "#E6CC80"},
+}
+
+func SlotName(slot int) string {
+    if slot >= 0 && slot < len(equipmentSlots) {
+        return equipmentSlots[slot]
+    }
+    return "Unknown"
+}
+
+func QualityName(quality int) string {
+    if quality >= 0 && quality < len(itemQualities) {
+        return itemQualities[quality].Name
+    }
+    return "Unknown"
+}
+
+func QualityColor(quality int) string {
+    if quality >= 0 && quality < len(itemQualities) {
+        return itemQualities[quality].Color
+    }
+    return itemQualities[1].Color
+}
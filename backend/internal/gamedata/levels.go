@@ -0,0 +1,50 @@
+package gamedata
+
+// Достижения "Level N" из Achievement.dbc — по их дате считаем, кто первым взял уровень
+var levelAchievements = map[int]int{
+    10: 6,
+    20: 7,
+    30: 8,
+    40: 9,
+    50: 10,
+    60: 11,
+    70: 12,
+    80: 13,
+}
+
+func LevelAchievement(level int) (int, bool) {
+    id, ok := levelAchievements[level]
+    return id, ok
+}
+
+func MaxLevel(expansion int) int {
+    switch expansion {
+    case ExpansionClassic:
+        return 60
+    case ExpansionTBC:
+        return 70
+    case ExpansionWotLK:
+        return 80
+    case ExpansionCataclysm:
+        return 85
+    default:
+        return 90
+    }
+}
+
+func ExpansionName(expansion int) string {
+    switch expansion {
+    case ExpansionClassic:
+        return "Classic"
+    case ExpansionTBC:
+        return "The Burning Crusade"
+    case ExpansionWotLK:
+        return "Wrath of the Lich King"
+    case ExpansionCataclysm:
+        return "Cataclysm"
+    case ExpansionMoP:
+        return "Mists of Pandaria"
+    default:
+        return "Unknown"
+    }
+}
@@ -0,0 +1,99 @@
+package gamedata
+
+type Faction string
+
+const (
+    FactionAlliance Faction = "alliance"
+    FactionHorde    Faction = "horde"
+    FactionNeutral  Faction = "neutral"
+)
+
+// Иконки берем с CDN Wowhead, у них стабильные имена файлов
+const IconBaseURL = "https://wow.zamimg.com/images/wow/icons/small/"
+
+type Race struct {
+    ID        int
+    Name      string
+    Slug      string
+    Faction   Faction
+    Expansion int
+}
+
+var races = []Race{
+    {ID: 1, Name: "Human", Slug: "human", Faction: FactionAlliance, Expansion: ExpansionClassic},
+    {ID: 2, Name: "Orc", Slug: "orc", Faction: FactionHorde, Expansion: ExpansionClassic},
+    {ID: 3, Name: "Dwarf", Slug: "dwarf", Faction: FactionAlliance, Expansion: ExpansionClassic},
+    {ID: 4, Name: "Night Elf", Slug: "nightelf", Faction: FactionAlliance, Expansion: ExpansionClassic},
+    {ID: 5, Name: "Undead", Slug: "scourge", Faction: FactionHorde, Expansion: ExpansionClassic},
+    {ID: 6, Name: "Tauren", Slug: "tauren", Faction: FactionHorde, Expansion: ExpansionClassic},
+    {ID: 7, Name: "Gnome", Slug: "gnome", Faction: FactionAlliance, Expansion: ExpansionClassic},
+    {ID: 8, Name: "Troll", Slug: "troll", Faction: FactionHorde, Expansion: ExpansionClassic},
+    {ID: 9, Name: "Goblin", Slug: "goblin", Faction: FactionHorde, Expansion: ExpansionCataclysm},
+    {ID: 10, Name: "Blood Elf", Slug: "bloodelf", Faction: FactionHorde, Expansion: ExpansionTBC},
+    {ID: 11, Name: "Draenei", Slug: "draenei", Faction: FactionAlliance, Expansion: ExpansionTBC},
+    {ID: 22, Name: "Worgen", Slug: "worgen", Faction: FactionAlliance, Expansion: ExpansionCataclysm},
+    {ID: 24, Name: "Pandaren", Slug: "pandaren", Faction: FactionNeutral, Expansion: ExpansionMoP},
+    {ID: 25, Name: "Pandaren", Slug: "pandaren", Faction: FactionAlliance, Expansion: ExpansionMoP},
+    {ID: 26, Name: "Pandaren", Slug: "pandaren", Faction: FactionHorde, Expansion: ExpansionMoP},
+}
+
+func GetRace(id int) (Race, bool) {
+    for _, r := range races {
+        if r.ID == id {
+            return r, true
+        }
+    }
+    return Race{}, false
+}
+
+// Races возвращает расы, доступные в указанном дополнении
+func Races(expansion int) []Race {
+    var list []Race
+    for _, r := range races {
+        if r.Expansion <= expansion {
+            list = append(list, r)
+        }
+    }
+    return list
+}
+
+// FactionRaces — ID рас фракции для фильтров в SQL
+func FactionRaces(faction Faction, expansion int) []int {
+    var ids []int
+    for _, r := range Races(expansion) {
+        if r.Faction == faction {
+            ids = append(ids, r.ID)
+        }
+    }
+    return ids
+}
+
+func RaceFaction(race int) Faction {
+    if r, ok := GetRace(race); ok {
+        return r.Faction
+    }
+    return FactionNeutral
+}
+
+func RaceName(race int) string {
+    if r, ok := GetRace(race); ok {
+        return r.Name
+    }
+    return "Unknown"
+}
+
+// RaceIcon — иконка расы с учетом пола (0 — мужской, 1 — женский)
+func RaceIcon(race, gender int) string {
+    r, ok := GetRace(race)
+    if !ok {
+        return IconBaseURL + "inv_misc_questionmark.jpg"
+    }
+    return IconBaseURL + "race_" + r.Slug + "_" + GenderName(gender) + ".jpg"
+}
+
+func GenderName(gender int) string {
+    if gender == 1 {
+        return "female"
+    }
+    return "male"
+}
@@ -0,0 +1,42 @@
+package gamedata
+
+type Profession struct {
+    ID        int
+    Name      string
+    Secondary bool
+}
+
+// Профессии по SkillLine.dbc
+var professions = []Profession{
+    {ID: 164, Name: "Blacksmithing"},
+    {ID: 165, Name: "Leatherworking"},
+    {ID: 171, Name: "Alchemy"},
+    {ID: 182, Name: "Herbalism"},
+    {ID: 186, Name: "Mining"},
+    {ID: 197, Name: "Tailoring"},
+    {ID: 202, Name: "Engineering"},
+    {ID: 333, Name: "Enchanting"},
+    {ID: 393, Name: "Skinning"},
+    {ID: 755, Name: "Jewelcrafting"},
+    {ID: 773, Name: "Inscription"},
+    {ID: 129, Name: "First Aid", Secondary: true},
+    {ID: 185, Name: "Cooking", Secondary: true},
+    {ID: 356, Name: "Fishing", Secondary: true},
+}
+
+func GetProfession(id int) (Profession, bool) {
+    for _, p := range professions {
+        if p.ID == id {
+            return p, true
+        }
+    }
+    return Profession{}, false
+}
+
+func ProfessionIDs() []int {
+    ids := make([]int, len(professions))
+    for i, p := range professions {
+        ids[i] = p.ID
+    }
+    return ids
+}
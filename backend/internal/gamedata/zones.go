@@ -0,0 +1,132 @@
+package gamedata
+
+import (
+    "strconv"
+)
+
+// Основные зоны и города по AreaTable.dbc (Classic — WotLK)
+var zones = map[int]string{
+    // Eastern Kingdoms
+    1:    "Dun Morogh",
+    3:    "Badlands",
+    4:    "Blasted Lands",
+    8:    "Swamp of Sorrows",
+    10:   "Duskwood",
+    11:   "Wetlands",
+    12:   "Elwynn Forest",
+    25:   "Blackrock Mountain",
+    28:   "Western Plaguelands",
+    33:   "Stranglethorn Vale",
+    36:   "Alterac Mountains",
+    38:   "Loch Modan",
+    40:   "Westfall",
+    41:   "Deadwind Pass",
+    44:   "Redridge Mountains",
+    45:   "Arathi Highlands",
+    46:   "Burning Steppes",
+    47:   "The Hinterlands",
+    51:   "Searing Gorge",
+    85:   "Tirisfal Glades",
+    130:  "Silverpine Forest",
+    139:  "Eastern Plaguelands",
+    267:  "Hillsbrad Foothills",
+    1497: "Undercity",
+    1519: "Stormwind City",
+    1537: "Ironforge",
+    3430: "Eversong Woods",
+    3433: "Ghostlands",
+    3487: "Silvermoon City",
+    4080: "Isle of Quel'Danas",
+    
+    // Kalimdor
+    14:   "Durotar",
+    15:   "Dustwallow Marsh",
+    16:   "Azshara",
+    17:   "The Barrens",
+    141:  "Teldrassil",
+    148:  "Darkshore",
+    215:  "Mulgore",
+    331:  "Ashenvale",
+    357:  "Feralas",
+    361:  "Felwood",
+    400:  "Thousand Needles",
+    405:  "Desolace",
+    406:  "Stonetalon Mountains",
+    440:  "Tanaris",
+    490:  "Un'Goro Crater",
+    493:  "Moonglade",
+    618:  "Winterspring",
+    1377: "Silithus",
+    1637: "Orgrimmar",
+    1638: "Thunder Bluff",
+    1657: "Darnassus",
+    3524: "Azuremyst Isle",
+    3525: "Bloodmyst Isle",
+    3557: "The Exodar",
+    
+    // Outland
+    3483: "Hellfire Peninsula",
+    3518: "Nagrand",
+    3519: "Terokkar Forest",
+    3520: "Shadowmoon Valley",
+    3521: "Zangarmarsh",
+    3522: "Blade's Edge Mountains",
+    3523: "Netherstorm",
+    3703: "Shattrath City",
+    
+    // Northrend
+    65:   "Dragonblight",
+    66:   "Zul'Drak",
+    67:   "The Storm Peaks",
+    210:  "Icecrown",
+    394:  "Grizzly Hills",
+    495:  "Howling Fjord",
+    2817: "Crystalsong Forest",
+    3537: "Borean Tundra",
+    3711: "Sholazar Basin",
+    4197: "Wintergrasp",
+    4395: "Dalaran",
+    4742: "Hrothgar's Landing",
+    
+    // Battlegrounds and arenas
+    2597: "Alterac Valley",
+    3277: "Warsong Gulch",
+    3358: "Arathi Basin",
+    3820: "Eye of the Storm",
+    4384: "Strand of the Ancients",
+    4710: "Isle of Conquest",
+    3698: "Nagrand Arena",
+    3702: "Blade's Edge Arena",
+    3968: "Ruins of Lordaeron",
+    4378: "Dalaran Sewers",
+    4406: "The Ring of Valor",
+    
+    // Raids
+    2159: "Onyxia's Lair",
+    2677: "Blackwing Lair",
+    2717: "Molten Core",
+    3428: "Ahn'Qiraj",
+    3456: "Naxxramas",
+    3606: "Hyjal Summit",
+    3607: "Serpentshrine Cavern",
+    3805: "Zul'Aman",
+    3845: "Tempest Keep",
+    3959: "Black Temple",
+    4075: "Sunwell Plateau",
+    4273: "Ulduar",
+    4493: "The Obsidian Sanctum",
+    4500: "The Eye of Eternity",
+    4603: "Vault of Archavon",
+    4722: "Trial of the Crusader",
+    4812: "Icecrown Citadel",
+    4987: "The Ruby Sanctum",
+    
+    876: "GM Island",
+}
+
+func ZoneName(zone int) string {
+    if name, ok := zones[zone]; ok {
+        return name
+    }
+    return "Zone #" + strconv.Itoa(zone)
+}
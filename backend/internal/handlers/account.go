@@ -0,0 +1,238 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" form:"current_password"`
+    NewPassword     string `json:"new_password" form:"new_password"`
+    ConfirmPassword string `json:"confirm_password" form:"confirm_password"`
+}
+
+type ChangeEmailRequest struct {
+    Password string `json:"password" form:"password"`
+    Email    string `json:"email" form:"email"`
+}
+
+type PasswordResetRequest struct {
+    Token           string `json:"token" form:"token"`
+    NewPassword     string `json:"new_password" form:"new_password"`
+    ConfirmPassword string `json:"confirm_password" form:"confirm_password"`
+}
+
+type PasswordResetPageData struct {
+    PageData
+    Token string
+}
+
+type EmailConfirmPageData struct {
+    PageData
+    Done    bool
+    Message string
+}
+
+type AccountPageData struct {
+    PageData
+    Account *services.AccountOverview
+}
+
+func AccountHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    overview, err := services.GetAccountOverview(session.Username)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load account")
+    }
+    
+    return c.Render(http.StatusOK, "account.html", AccountPageData{
+        PageData: PageData{
+            Title:       "My Account",
+            Description: "Your account and characters",
+            Config:      config.AppConfig,
+        },
+        Account: overview,
+    })
+}
+
+func AccountAPIHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    overview, err := services.GetAccountOverview(session.Username)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load account"})
+    }
+    
+    return c.JSON(http.StatusOK, overview)
+}
+
+func ChangePasswordHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var req ChangePasswordRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    if req.NewPassword != req.ConfirmPassword {
+        return formError(c, http.StatusBadRequest, "Passwords do not match")
+    }
+    if err := services.ValidatePassword(req.NewPassword); err != nil {
+        return formError(c, http.StatusBadRequest, err.Error())
+    }
+    
+    err := services.ChangePassword(c.Request().Context(), session.Username, req.CurrentPassword, req.NewPassword)
+    if errors.Is(err, services.ErrWrongPassword) {
+        return formError(c, http.StatusUnauthorized, err.Error())
+    }
+    if errors.Is(err, services.ErrPasswordReused) {
+        return formError(c, http.StatusConflict, err.Error())
+    }
+    if err != nil {
+        return formError(c, http.StatusInternalServerError, "Failed to change password")
+    }
+    services.Audit(requestActor(c), services.AuditPasswordChange, session.AccountID, nil)
+    
+    // Все сессии, включая текущую, уже завершены
+    setSessionCookie(c, "", -time.Second)
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/login?next=/account")
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "message": "Password changed, please log in again",
+    })
+}
+
+func ChangeEmailHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var req ChangeEmailRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    if err := services.ValidateEmail(req.Email); err != nil {
+        return formError(c, http.StatusBadRequest, err.Error())
+    }
+    
+    err := services.RequestEmailChange(c.Request().Context(), session.Username, req.Password, req.Email)
+    switch {
+    case errors.Is(err, services.ErrWrongPassword):
+        return formError(c, http.StatusUnauthorized, err.Error())
+    case errors.Is(err, services.ErrEmailInUse):
+        return formError(c, http.StatusConflict, err.Error())
+    case errors.Is(err, services.ErrEmailUnchanged), errors.Is(err, services.ErrEmailBlacklisted):
+        return formError(c, http.StatusBadRequest, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to send confirmation emails")
+    }
+    
+    message := "Check both your current and new mailboxes and open the confirmation links"
+    if isHTMX(c) {
+        return c.HTML(http.StatusOK, `<div class="text-green-500 text-sm">`+message+`</div>`)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "message": message,
+    })
+}
+
+// EmailConfirmHandler открывается по ссылке из письма, поэтому без авторизации:
+// токен сам по себе подтверждает владение ящиком
+func EmailConfirmHandler(c echo.Context) error {
+    done, err := services.ConfirmEmailChange(c.Request().Context(), requestActor(c), c.QueryParam("token"))
+    
+    message := "Link confirmed. The email will change once the other address is confirmed too."
+    switch {
+    case errors.Is(err, services.ErrEmailTokenNotFound):
+        message = err.Error()
+    case err != nil:
+        message = "Failed to confirm the email change, try again later"
+    case done:
+        message = "Your email has been changed."
+    }
+    
+    return c.Render(http.StatusOK, "email_confirm.html", EmailConfirmPageData{
+        PageData: PageData{
+            Title:       "Email Change",
+            Description: "Confirm email change",
+            Config:      config.AppConfig,
+        },
+        Done:    done,
+        Message: message,
+    })
+}
+
+// EmailVerifyHandler — ссылка из письма, отправленного при регистрации
+func EmailVerifyHandler(c echo.Context) error {
+    err := services.ConfirmEmailVerification(c.Request().Context(), c.QueryParam("token"))
+    
+    message := "Your email has been confirmed."
+    switch {
+    case errors.Is(err, services.ErrEmailTokenNotFound):
+        message = err.Error()
+    case err != nil:
+        message = "Failed to confirm the email, try again later"
+    }
+    
+    return c.Render(http.StatusOK, "email_confirm.html", EmailConfirmPageData{
+        PageData: PageData{
+            Title:       "Email Verification",
+            Description: "Confirm your email",
+            Config:      config.AppConfig,
+        },
+        Done:    err == nil,
+        Message: message,
+    })
+}
+
+// PasswordResetPageHandler открывается по ссылке из письма после сброса пароля
+// администратором
+func PasswordResetPageHandler(c echo.Context) error {
+    return c.Render(http.StatusOK, "password_reset.html", PasswordResetPageData{
+        PageData: PageData{
+            Title:       "Set a New Password",
+            Description: "Choose a new account password",
+            Config:      config.AppConfig,
+        },
+        Token: c.QueryParam("token"),
+    })
+}
+
+func PasswordResetCompleteHandler(c echo.Context) error {
+    var req PasswordResetRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    if req.NewPassword != req.ConfirmPassword {
+        return formError(c, http.StatusBadRequest, "Passwords do not match")
+    }
+    if err := services.ValidatePassword(req.NewPassword); err != nil {
+        return formError(c, http.StatusBadRequest, err.Error())
+    }
+    
+    err := services.CompletePasswordReset(c.Request().Context(), requestActor(c), req.Token, req.NewPassword)
+    switch {
+    case errors.Is(err, services.ErrResetTokenNotFound):
+        return formError(c, http.StatusNotFound, err.Error())
+    case errors.Is(err, services.ErrPasswordReused):
+        return formError(c, http.StatusConflict, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to set the password")
+    }
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/login?next=/account")
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "message": "Password changed, please log in",
+    })
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+    "errors"
+    "html/template"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AdminAccountsPageData struct {
+    PageData
+    Query  AdminAccountQuery
+    Result *services.AccountSearchResult
+    Recent []database.AuditEntry
+}
+
+type AdminAccountPageData struct {
+    PageData
+    Account    *services.AdminAccount
+    Expansions []AdminExpansion
+    BanPresets []services.BanPreset
+    BanReasons []string
+}
+
+type AdminExpansion struct {
+    ID   int
+    Name string
+}
+
+// AdminAccountQuery — параметры поиска из строки запроса; даты в формате 2006-01-02
+type AdminAccountQuery struct {
+    Username string `query:"username"`
+    Email    string `query:"email"`
+    IP       string `query:"ip"`
+    From     string `query:"from"`
+    To       string `query:"to"`
+    Page     int    `query:"page"`
+}
+
+// Empty — форма поиска еще не отправлялась
+func (q AdminAccountQuery) Empty() bool {
+    return q.Username == "" && q.Email == "" && q.IP == "" && q.From == "" && q.To == ""
+}
+
+// QueryString — параметры для ссылок пагинации; template.URL, чтобы шаблон
+// не экранировал = и & внутри ссылки
+func (q AdminAccountQuery) QueryString() template.URL {
+    values := url.Values{}
+    for key, value := range map[string]string{"username": q.Username, "email": q.Email, "ip": q.IP, "from": q.From, "to": q.To} {
+        if value != "" {
+            values.Set(key, value)
+        }
+    }
+    return template.URL(values.Encode())
+}
+
+type AdminAccountActionRequest struct {
+    Locked    bool   `json:"locked" form:"locked"`
+    Expansion int    `json:"expansion" form:"expansion"`
+    Level     int    `json:"level" form:"level"`
+    Note      string `json:"note" form:"note"`
+}
+
+// requestActor — автор запроса для журнала аудита: вошедший игрок или
+// аноним, вместе с IP, User-Agent и ID запроса
+func requestActor(c echo.Context) services.AdminActor {
+    actor := services.AdminActor{
+        IP:        c.RealIP(),
+        UserAgent: c.Request().UserAgent(),
+        RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+    }
+    if session := middleware.CurrentSession(c); session != nil {
+        actor.AccountID = session.AccountID
+        actor.Name = session.Username
+    }
+    return actor
+}
+
+// adminActor — текущий администратор; маршруты панели закрыты RequireAdmin,
+// так что сессия есть всегда
+func adminActor(c echo.Context) services.AdminActor {
+    return requestActor(c)
+}
+
+func (q AdminAccountQuery) filter() (database.AccountSearch, error) {
+    filter := database.AccountSearch{
+        Username: strings.TrimSpace(q.Username),
+        Email:    strings.TrimSpace(q.Email),
+        IP:       strings.TrimSpace(q.IP),
+    }
+    var err error
+    if q.From != "" {
+        if filter.From, err = time.ParseInLocation("2006-01-02", q.From, time.Local); err != nil {
+            return filter, errors.New("invalid from date")
+        }
+    }
+    if q.To != "" {
+        if filter.To, err = time.ParseInLocation("2006-01-02", q.To, time.Local); err != nil {
+            return filter, errors.New("invalid to date")
+        }
+        // Дата «по» включительно
+        filter.To = filter.To.AddDate(0, 0, 1)
+    }
+    return filter, nil
+}
+
+// AdminIndexHandler — у панели пока нет отдельной главной, начинаем с поиска
+func AdminIndexHandler(c echo.Context) error {
+    return c.Redirect(http.StatusFound, "/admin/accounts")
+}
+
+func AdminAccountsHandler(c echo.Context) error {
+    var query AdminAccountQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+        return echo.NewHTTPError(http.StatusBadRequest, "Invalid search")
+    }
+    
+    data := AdminAccountsPageData{
+        PageData: PageData{
+            Title:       "Accounts",
+            Description: "Account search",
+            Config:      config.AppConfig,
+        },
+        Query: query,
+    }
+    
+    if !query.Empty() {
+        filter, err := query.filter()
+        if err != nil {
+            return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+        }
+        if data.Result, err = services.SearchAccounts(filter, query.Page); err != nil {
+            return echo.NewHTTPError(http.StatusInternalServerError, "Failed to search accounts")
+        }
+    }
+    
+    recent, err := services.RecentAudit(0)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load the audit log")
+    }
+    data.Recent = recent
+    
+    return c.Render(http.StatusOK, "admin_accounts.html", data)
+}
+
+func AdminAccountsAPIHandler(c echo.Context) error {
+    var query AdminAccountQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid search"})
+    }
+    filter, err := query.filter()
+    if err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+    }
+    
+    result, err := services.SearchAccounts(filter, query.Page)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search accounts"})
+    }
+    return c.JSON(http.StatusOK, result)
+}
+
+func AdminAccountHandler(c echo.Context) error {
+    id, _ := strconv.Atoi(c.Param("id"))
+    account, err := services.GetAdminAccount(id)
+    if errors.Is(err, services.ErrAccountNotFound) {
+        return echo.NewHTTPError(http.StatusNotFound, "Account not found")
+    }
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load account")
+    }
+    
+    var expansions []AdminExpansion
+    for e := 0; e <= config.AppConfig.Game.Expansion; e++ {
+        expansions = append(expansions, AdminExpansion{ID: e, Name: gamedata.ExpansionName(e)})
+    }
+    
+    return c.Render(http.StatusOK, "admin_account.html", AdminAccountPageData{
+        PageData: PageData{
+            Title:       account.Username,
+            Description: "Account details",
+            Config:      config.AppConfig,
+        },
+        Account:    account,
+        Expansions: expansions,
+        BanPresets: services.BanPresets,
+        BanReasons: config.AppConfig.Security.BanReasons,
+    })
+}
+
+func AdminAccountAPIHandler(c echo.Context) error {
+    id, _ := strconv.Atoi(c.Param("id"))
+    account, err := services.GetAdminAccount(id)
+    if errors.Is(err, services.ErrAccountNotFound) {
+        return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+    }
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load account"})
+    }
+    return c.JSON(http.StatusOK, account)
+}
+
+// AdminAccountActionHandler выполняет действие :action над аккаунтом :id:
+// lock, expansion, 2fa-reset, password-reset, gm-level или note
+func AdminAccountActionHandler(c echo.Context) error {
+    id, _ := strconv.Atoi(c.Param("id"))
+    
+    var req AdminAccountActionRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    actor := adminActor(c)
+    var err error
+    var message string
+    switch c.Param("action") {
+    case "lock":
+        err = services.SetAccountLock(actor, id, req.Locked)
+        message = "IP lock removed"
+        if req.Locked {
+            message = "Account locked to its last IP"
+        }
+    case "expansion":
+        err = services.SetAccountExpansion(actor, id, req.Expansion)
+        message = "Expansion changed"
+    case "2fa-reset":
+        err = services.ResetAccountTwoFactor(actor, id)
+        message = "Authenticator removed"
+    case "password-reset":
+        err = services.ForcePasswordReset(c.Request().Context(), actor, id)
+        message = "Password reset, the player got a link to choose a new one"
+    case "gm-level":
+        err = services.SetAccountGMLevel(actor, id, req.Level)
+        message = "GM level changed"
+    case "note":
+        _, err = services.AddAccountNote(actor, id, req.Note)
+        message = "Note added"
+    default:
+        return formError(c, http.StatusNotFound, "Unknown action")
+    }
+    
+    switch {
+    case errors.Is(err, services.ErrAccountNotFound):
+        return formError(c, http.StatusNotFound, err.Error())
+    case errors.Is(err, services.ErrForbiddenTarget):
+        return formError(c, http.StatusForbidden, err.Error())
+    case errors.Is(err, services.ErrInvalidExpansion), errors.Is(err, services.ErrInvalidGMLevel), errors.Is(err, services.ErrEmptyNote):
+        return formError(c, http.StatusBadRequest, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Action failed")
+    }
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/admin/accounts/"+strconv.Itoa(id))
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "message": message,
+    })
+}
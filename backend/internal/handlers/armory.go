@@ -0,0 +1,183 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type ArmoryPageData struct {
+    PageData
+    Profile *services.ArmoryProfile
+    Error   string
+}
+
+// ArmoryPrivacyRow — строка в списке персонажей с переключателем видимости
+type ArmoryPrivacyRow struct {
+    RealmName string
+    Character services.OnlinePlayer
+    Toggle    ArmoryPrivacyToggle
+}
+
+type ArmoryPrivacyToggle struct {
+    RealmID int
+    GUID    int
+    Hidden  bool
+}
+
+type AccountArmoryPageData struct {
+    PageData
+    Rows []ArmoryPrivacyRow
+}
+
+type ArmoryPrivacyRequest struct {
+    Realm  int  `json:"realm" form:"realm"`
+    GUID   int  `json:"guid" form:"guid"`
+    Hidden bool `json:"hidden" form:"hidden"`
+}
+
+// realmFromPath читает :realm из пути — как ID реалма, так и его название
+func realmFromPath(c echo.Context) (*database.Realm, error) {
+    value := c.Param("realm")
+    if id, err := strconv.Atoi(value); err == nil {
+        if realm, ok := database.GetRealm(id); ok {
+            return realm, nil
+        }
+    }
+    
+    for _, realm := range database.GetRealms() {
+        if strings.EqualFold(realm.Name, value) {
+            r := realm
+            return &r, nil
+        }
+    }
+    return nil, echo.NewHTTPError(http.StatusNotFound, "Realm not found")
+}
+
+func armoryStatus(err error) int {
+    switch {
+    case errors.Is(err, services.ErrCharacterNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, services.ErrArmoryHidden):
+        return http.StatusForbidden
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+func ArmoryHandler(c echo.Context) error {
+    realm, err := realmFromPath(c)
+    if err != nil {
+        return err
+    }
+    
+    name := c.Param("name")
+    profile, err := services.GetArmoryProfile(c.Request().Context(), realm.ID, name)
+    
+    data := ArmoryPageData{
+        PageData: PageData{
+            Title:       name + " @ " + realm.Name,
+            Description: "Character profile of " + name,
+            Config:      config.AppConfig,
+            Realm:       realm,
+        },
+        Profile: profile,
+    }
+    
+    status := http.StatusOK
+    if err != nil {
+        status = armoryStatus(err)
+        data.Error = err.Error()
+        if status == http.StatusInternalServerError {
+            data.Error = "The armory is temporarily unavailable"
+        }
+    }
+    
+    return c.Render(status, "armory.html", data)
+}
+
+func ArmoryAPIHandler(c echo.Context) error {
+    realm, err := realmFromPath(c)
+    if err != nil {
+        return err
+    }
+    
+    profile, err := services.GetArmoryProfile(c.Request().Context(), realm.ID, c.Param("name"))
+    if err != nil {
+        return c.JSON(armoryStatus(err), map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, profile)
+}
+
+// AccountArmoryHandler — персонажи аккаунта на всех реалмах с настройкой видимости в армори
+func AccountArmoryHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var rows []ArmoryPrivacyRow
+    for _, realm := range database.GetRealms() {
+        characters, err := database.GetAccountCharacters(realm.ID, session.AccountID)
+        if err != nil {
+            continue
+        }
+        hidden, _ := database.GetHiddenCharacters(realm.ID, session.AccountID)
+        
+        for _, character := range characters {
+            rows = append(rows, ArmoryPrivacyRow{
+                RealmName: realm.Name,
+                Character: services.NewOnlinePlayer(character),
+                Toggle: ArmoryPrivacyToggle{
+                    RealmID: realm.ID,
+                    GUID:    character.GUID,
+                    Hidden:  hidden[character.GUID],
+                },
+            })
+        }
+    }
+    
+    return c.Render(http.StatusOK, "account_armory.html", AccountArmoryPageData{
+        PageData: PageData{
+            Title:       "Armory Privacy",
+            Description: "Choose which characters are visible in the armory",
+            Config:      config.AppConfig,
+        },
+        Rows: rows,
+    })
+}
+
+func ArmoryPrivacyHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var req ArmoryPrivacyRequest
+    if err := c.Bind(&req); err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+    }
+    
+    realm, ok := database.GetRealm(req.Realm)
+    if !ok {
+        return c.JSON(http.StatusNotFound, map[string]string{"error": "Realm not found"})
+    }
+    
+    err := services.SetArmoryHidden(c.Request().Context(), realm.ID, req.GUID, session.AccountID, req.Hidden)
+    if err != nil {
+        if isHTMX(c) {
+            return c.HTML(http.StatusOK, `<div class="text-red-500 text-sm">Failed to update the armory setting</div>`)
+        }
+        return c.JSON(armoryStatus(err), map[string]string{"error": err.Error()})
+    }
+    
+    if isHTMX(c) {
+        return c.Render(http.StatusOK, "partials/armory_privacy_toggle.html", ArmoryPrivacyToggle{
+            RealmID: realm.ID,
+            GUID:    req.GUID,
+            Hidden:  req.Hidden,
+        })
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{"success": true, "hidden": req.Hidden})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "html"
+    "html/template"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AdminAuditPageData struct {
+    PageData
+    Query   AdminAuditQuery
+    Result  *services.AuditSearchResult
+    Actions []string
+}
+
+// AdminAuditQuery — фильтры журнала из строки запроса; даты в формате 2006-01-02
+type AdminAuditQuery struct {
+    Actor     string `query:"actor"`
+    Action    string `query:"action"`
+    Account   int    `query:"account"`
+    Target    string `query:"target"`
+    IP        string `query:"ip"`
+    RequestID string `query:"request_id"`
+    From      string `query:"from"`
+    To        string `query:"to"`
+    Page      int    `query:"page"`
+}
+
+// QueryString — фильтры для ссылок пагинации и экспорта
+func (q AdminAuditQuery) QueryString() template.URL {
+    values := url.Values{}
+    for key, value := range map[string]string{
+        "actor": q.Actor, "action": q.Action, "target": q.Target, "ip": q.IP,
+        "request_id": q.RequestID, "from": q.From, "to": q.To,
+    } {
+        if value != "" {
+            values.Set(key, value)
+        }
+    }
+    if q.Account > 0 {
+        values.Set("account", strconv.Itoa(q.Account))
+    }
+    return template.URL(values.Encode())
+}
+
+func (q AdminAuditQuery) filter() (database.AuditFilter, error) {
+    filter := database.AuditFilter{
+        Actor:           strings.TrimSpace(q.Actor),
+        Action:          strings.TrimSpace(q.Action),
+        TargetAccountID: q.Account,
+        Target:          strings.TrimSpace(q.Target),
+        IP:              strings.TrimSpace(q.IP),
+        RequestID:       strings.TrimSpace(q.RequestID),
+    }
+    var err error
+    if q.From != "" {
+        if filter.From, err = time.ParseInLocation("2006-01-02", q.From, time.Local); err != nil {
+            return filter, errors.New("invalid from date")
+        }
+    }
+    if q.To != "" {
+        if filter.To, err = time.ParseInLocation("2006-01-02", q.To, time.Local); err != nil {
+            return filter, errors.New("invalid to date")
+        }
+        // Дата «по» включительно
+        filter.To = filter.To.AddDate(0, 0, 1)
+    }
+    return filter, nil
+}
+
+func bindAuditQuery(c echo.Context) (AdminAuditQuery, database.AuditFilter, error) {
+    var query AdminAuditQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &query); err != nil {
+        return query, database.AuditFilter{}, errors.New("invalid filter")
+    }
+    filter, err := query.filter()
+    return query, filter, err
+}
+
+func AdminAuditHandler(c echo.Context) error {
+    query, filter, err := bindAuditQuery(c)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+    }
+    result, err := services.SearchAudit(filter, query.Page)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load the audit log")
+    }
+    
+    return c.Render(http.StatusOK, "admin_audit.html", AdminAuditPageData{
+        PageData: PageData{
+            Title:       "Audit Log",
+            Description: "Staff actions and security events",
+            Config:      config.AppConfig,
+        },
+        Query:   query,
+        Result:  result,
+        Actions: services.AuditActions,
+    })
+}
+
+func AdminAuditAPIHandler(c echo.Context) error {
+    query, filter, err := bindAuditQuery(c)
+    if err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+    }
+    result, err := services.SearchAudit(filter, query.Page)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load the audit log"})
+    }
+    return c.JSON(http.StatusOK, result)
+}
+
+// AdminAuditExportHandler отдает записи по фильтру файлом: ?format=csv или jsonl.
+// Записи идут потоком, поэтому ошибка посреди выгрузки только логируется
+func AdminAuditExportHandler(c echo.Context) error {
+    query, filter, err := bindAuditQuery(c)
+    if err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+    }
+    
+    format := c.QueryParam("format")
+    contentType := "text/csv; charset=utf-8"
+    switch format {
+    case "csv":
+    case "jsonl":
+        contentType = "application/x-ndjson"
+    default:
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": services.ErrUnknownExportFormat.Error()})
+    }
+    
+    // Выгрузка журнала — тоже событие журнала
+    services.RecordAudit(adminActor(c), services.AuditEvent{
+        Action:  services.AuditLogExport,
+        Details: map[string]interface{}{"format": format, "filter": string(query.QueryString())},
+    })
+    
+    name := fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102-150405"), format)
+    c.Response().Header().Set(echo.HeaderContentType, contentType)
+    c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`"`)
+    c.Response().WriteHeader(http.StatusOK)
+    if err := services.ExportAudit(c.Response(), format, filter); err != nil {
+        log.Printf("audit: export: %v", err)
+    }
+    return nil
+}
+
+// AdminAuditVerifyHandler пересчитывает цепочку хешей всего журнала
+func AdminAuditVerifyHandler(c echo.Context) error {
+    status, err := services.VerifyAudit()
+    if err != nil {
+        return formError(c, http.StatusInternalServerError, "Failed to verify the audit log")
+    }
+    if !isHTMX(c) {
+        return c.JSON(http.StatusOK, status)
+    }
+    
+    if !status.Valid {
+        return c.HTML(http.StatusOK, fmt.Sprintf(
+            `<div class="text-red-500 text-sm">Chain broken at entry #%d: the log was edited or entries were removed.</div>`,
+            status.BrokenAt))
+    }
+    message := fmt.Sprintf("Chain intact: %d entries verified", status.Checked)
+    if status.PrunedID > 0 {
+        message += fmt.Sprintf(" (entries up to #%d removed by retention)", status.PrunedID)
+    }
+    if status.Unsealed > 0 {
+        message += fmt.Sprintf(", %d older entries predate the chain", status.Unsealed)
+    }
+    return c.HTML(http.StatusOK, `<div class="text-green-500 text-sm">`+html.EscapeString(message)+`.</div>`)
+}
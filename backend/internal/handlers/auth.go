@@ -2,6 +2,8 @@ package handlers
 
 import (
     "encoding/json"
+    "errors"
+    "log"
     "net/http"
     "strings"
     "time"
@@ -15,6 +17,7 @@ type RegisterRequest struct {
     Email    string `json:"email" validate:"required,email"`
     Password string `json:"password" validate:"required,min=4,max=16"`
     Captcha  string `json:"captcha,omitempty"`
+    Ref      string `json:"ref,omitempty" form:"ref"`
 }
 
 type RegisterResponse struct {
@@ -68,6 +71,21 @@ func RegisterHandler(c echo.Context) error {
         }
     }
     
+    // Забаненный адрес не может завести новый аккаунт
+    if err := services.CheckIPBan(c.RealIP()); err != nil {
+        var banned *services.BannedError
+        if errors.As(err, &banned) {
+            return c.JSON(http.StatusForbidden, RegisterResponse{
+                Success: false,
+                Message: banned.Error(),
+            })
+        }
+        return c.JSON(http.StatusInternalServerError, RegisterResponse{
+            Success: false,
+            Message: "Database error",
+        })
+    }
+    
     // Проверка существования аккаунта
     exists, err := database.AccountExists(strings.ToUpper(req.Username), strings.ToUpper(req.Email))
     if err != nil {
@@ -105,7 +123,7 @@ func RegisterHandler(c echo.Context) error {
         Salt:      srp6.Salt,
         Verifier:  srp6.Verifier,
         Expansion: config.AppConfig.Game.Expansion,
-        IP:        services.GetClientIP(c.Request()),
+        IP:        c.RealIP(),
         CreatedAt: time.Now(),
         Locked:    false,
     }
@@ -117,6 +135,41 @@ func RegisterHandler(c echo.Context) error {
         })
     }
     
+    if err := services.SendEmailVerification(c.Request().Context(), account.ID, account.Username, account.Email); err != nil {
+        log.Printf("email verification: account %d: %v", account.ID, err)
+    }
+    
+    // Стартовый бонус уйдет первому персонажу аккаунта
+    if err := services.QueueRegistrationBonus(account.ID); err != nil {
+        log.Printf("registration bonus: account %d: %v", account.ID, err)
+    }
+    
+    // Реферальный код из формы или из cookie, поставленной при заходе по ссылке
+    ref := req.Ref
+    if cookie, err := c.Cookie(referralCookie); ref == "" && err == nil {
+        ref = cookie.Value
+    }
+    if err := services.RecordReferral(ref, account.ID, account.Email, account.IP); err != nil {
+        log.Printf("referral: account %d: %v", account.ID, err)
+    }
+    
+    services.Audit(requestActor(c).WithAccount(account.ID, account.Username), services.AuditAccountCreate, account.ID, map[string]interface{}{
+        "email":     account.Email,
+        "expansion": account.Expansion,
+    })
+    services.Notify(services.Notification{
+        Kind:  services.NotifyRegistration,
+        Title: "New player",
+        Text:  services.MaskUsername(account.Username) + " has joined " + config.AppConfig.Game.ServerName,
+    })
+    services.EmitWebhook(services.WebhookAccountCreated, map[string]interface{}{
+        "account_id": account.ID,
+        "username":   account.Username,
+        "email":      account.Email,
+        "expansion":  account.Expansion,
+        "created_at": account.CreatedAt.UTC(),
+    })
+    
     // Ответ
     resp := RegisterResponse{
         Success: true,
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AdminBansPageData struct {
+    PageData
+    Bans          *services.BanList
+    Presets       []services.BanPreset
+    Reasons       []string
+    Realms        []database.Realm
+    CharacterBans bool
+}
+
+type AdminBanRequest struct {
+    Account   string `json:"account" form:"account"`
+    IP        string `json:"ip" form:"ip"` // для бана — адрес, CIDR или диапазон from-to
+    Realm     int    `json:"realm" form:"realm"`
+    Character string `json:"character" form:"character"`
+    Duration  string `json:"duration" form:"duration"`
+    Reason    string `json:"reason" form:"reason"`
+    Next      string `json:"next" form:"next"`
+}
+
+func AdminBansHandler(c echo.Context) error {
+    bans, err := services.GetBanList()
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load bans")
+    }
+    
+    return c.Render(http.StatusOK, "admin_bans.html", AdminBansPageData{
+        PageData: PageData{
+            Title:       "Bans",
+            Description: "Account, IP and character bans",
+            Config:      config.AppConfig,
+        },
+        Bans:          bans,
+        Presets:       services.BanPresets,
+        Reasons:       config.AppConfig.Security.BanReasons,
+        Realms:        database.GetRealms(),
+        CharacterBans: config.AppConfig.Game.ServerCore != 5, // CMangos банит только аккаунты
+    })
+}
+
+func AdminBansAPIHandler(c echo.Context) error {
+    bans, err := services.GetBanList()
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load bans"})
+    }
+    return c.JSON(http.StatusOK, bans)
+}
+
+// AdminBanHandler создает бан вида :kind — account, ip или character
+func AdminBanHandler(c echo.Context) error {
+    var req AdminBanRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    duration, err := services.ParseBanDuration(req.Duration)
+    if err != nil {
+        return formError(c, http.StatusBadRequest, err.Error())
+    }
+    
+    actor := adminActor(c)
+    var result interface{}
+    switch c.Param("kind") {
+    case "account":
+        result, err = services.BanAccount(c.Request().Context(), actor, req.Account, duration, req.Reason)
+    case "ip":
+        result, err = services.BanIPRange(actor, req.IP, duration, req.Reason)
+    case "character":
+        result, err = services.BanCharacter(c.Request().Context(), actor, req.Realm, req.Character, duration, req.Reason)
+    default:
+        return formError(c, http.StatusNotFound, "Unknown ban type")
+    }
+    if err != nil {
+        return banError(c, err)
+    }
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", banRedirect(req.Next))
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "ban":     result,
+    })
+}
+
+// AdminUnbanHandler снимает бан вида :kind
+func AdminUnbanHandler(c echo.Context) error {
+    var req AdminBanRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    actor := adminActor(c)
+    var err error
+    switch c.Param("kind") {
+    case "account":
+        err = services.UnbanAccount(actor, req.Account)
+    case "ip":
+        err = services.UnbanIP(actor, req.IP)
+    case "character":
+        err = services.UnbanCharacter(actor, req.Realm, req.Character)
+    default:
+        return formError(c, http.StatusNotFound, "Unknown ban type")
+    }
+    if err != nil {
+        return banError(c, err)
+    }
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", banRedirect(req.Next))
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]bool{"success": true})
+}
+
+// banRedirect возвращает туда, откуда пришла форма: в список банов или в карточку аккаунта
+func banRedirect(next string) string {
+    if next == "" {
+        return "/admin/bans"
+    }
+    return safeNext(next)
+}
+
+func banError(c echo.Context, err error) error {
+    switch {
+    case errors.Is(err, services.ErrAccountNotFound), errors.Is(err, services.ErrCharacterNotFound):
+        return formError(c, http.StatusNotFound, err.Error())
+    case errors.Is(err, services.ErrProtectedAccount), errors.Is(err, services.ErrOwnIP):
+        return formError(c, http.StatusForbidden, err.Error())
+    case errors.Is(err, services.ErrNotBanned), errors.Is(err, services.ErrIPNotBanned), errors.Is(err, services.ErrCharacterNotBanned):
+        return formError(c, http.StatusConflict, err.Error())
+    case errors.Is(err, services.ErrReasonRequired), errors.Is(err, services.ErrInvalidIPRange), errors.Is(err, services.ErrIPRangeTooLarge),
+        errors.Is(err, database.ErrCharacterBansUnsupported):
+        return formError(c, http.StatusBadRequest, err.Error())
+    default:
+        return formError(c, http.StatusInternalServerError, "Failed to update the ban")
+    }
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+    "errors"
+    "html"
+    "net/http"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "wow-registration/internal/soap"
+    "github.com/labstack/echo/v4"
+)
+
+type CharacterToolRequest struct {
+    Realm  int    `json:"realm" form:"realm"`
+    GUID   int    `json:"guid" form:"guid"`
+    Action string `json:"action" form:"action"`
+}
+
+type AccountToolsPageData struct {
+    PageData
+    Tools      []services.CharacterTool
+    Characters []services.AccountCharacter
+    Points     int
+}
+
+func AccountToolsHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    overview, err := services.GetAccountOverview(session.Username)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load account")
+    }
+    points, _ := database.GetPointsBalance(session.AccountID)
+    
+    return c.Render(http.StatusOK, "account_tools.html", AccountToolsPageData{
+        PageData: PageData{
+            Title:       "Character Services",
+            Description: "Unstuck, revive, rename and other character services",
+            Config:      config.AppConfig,
+        },
+        Tools:      services.CharacterTools(),
+        Characters: overview.Characters,
+        Points:     points,
+    })
+}
+
+func CharacterToolsAPIHandler(c echo.Context) error {
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "enabled": config.AppConfig.Custom.CharacterToolsEnabled && config.AppConfig.Integrations.SOAPEnabled,
+        "tools":   services.CharacterTools(),
+    })
+}
+
+func CharacterToolHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var req CharacterToolRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    result, err := services.RunCharacterTool(c.Request().Context(), session.AccountID, req.Realm, req.GUID, req.Action)
+    if err != nil {
+        var cooldown *services.CooldownError
+        switch {
+        case errors.As(err, &cooldown):
+            return formError(c, http.StatusTooManyRequests, cooldown.Error())
+        case errors.Is(err, services.ErrNotYourCharacter):
+            return formError(c, http.StatusForbidden, err.Error())
+        case errors.Is(err, services.ErrUnknownTool):
+            return formError(c, http.StatusNotFound, err.Error())
+        case errors.Is(err, services.ErrCharacterOnline), errors.Is(err, services.ErrToolBusy):
+            return formError(c, http.StatusConflict, err.Error())
+        case errors.Is(err, database.ErrInsufficientPoints):
+            return formError(c, http.StatusPaymentRequired, "Not enough points")
+        case errors.Is(err, services.ErrToolsDisabled), errors.Is(err, soap.ErrDisabled):
+            return formError(c, http.StatusServiceUnavailable, "Character services are disabled")
+        case errors.Is(err, services.ErrToolFailed), errors.Is(err, services.ErrToolReview):
+            return formError(c, http.StatusBadGateway, err.Error())
+        }
+        return formError(c, http.StatusInternalServerError, "Failed to run the action")
+    }
+    
+    if isHTMX(c) {
+        return c.HTML(http.StatusOK, `<div class="text-green-500 text-sm">Done. `+html.EscapeString(result)+`</div>`)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "result":  result,
+    })
+}
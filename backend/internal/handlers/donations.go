@@ -0,0 +1,92 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/payments"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AccountDonationsPageData struct {
+    PageData
+    Providers []services.DonationProvider
+    Donations []database.Donation
+    Points    int
+}
+
+func AccountDonationsHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    donations, err := services.GetDonationHistory(session.AccountID)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load donations")
+    }
+    points, _ := database.GetPointsBalance(session.AccountID)
+    
+    return c.Render(http.StatusOK, "account_donations.html", AccountDonationsPageData{
+        PageData: PageData{
+            Title:       "Donate",
+            Description: "Support the server and get points",
+            Config:      config.AppConfig,
+        },
+        Providers: services.DonationProviders(),
+        Donations: donations,
+        Points:    points,
+    })
+}
+
+func DonationsAPIHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    donations, err := services.GetDonationHistory(session.AccountID)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load donations"})
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "enabled":   config.AppConfig.Custom.DonationsEnabled,
+        "providers": services.DonationProviders(),
+        "donations": donations,
+    })
+}
+
+// DonationRedirectHandler заводит платеж на ?amount= и уводит донора на оплату
+func DonationRedirectHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    amount, _ := strconv.Atoi(c.QueryParam("amount"))
+    
+    target, err := services.StartDonation(session.AccountID, c.Param("provider"), amount)
+    switch {
+    case errors.Is(err, services.ErrDonationAmount):
+        return echo.NewHTTPError(http.StatusBadRequest, "The minimum donation is "+strconv.Itoa(config.AppConfig.Custom.DonationMinAmount)+" "+config.AppConfig.Custom.DonationCurrency)
+    case errors.Is(err, services.ErrDonationsDisabled), errors.Is(err, payments.ErrUnknownProvider):
+        return echo.NewHTTPError(http.StatusNotFound, "Donations are not available")
+    case err != nil:
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to start the payment")
+    }
+    
+    return c.Redirect(http.StatusFound, target)
+}
+
+// DonationWebhookHandler — уведомления провайдера. На 5xx провайдер повторит
+// уведомление, на 4xx — нет
+func DonationWebhookHandler(c echo.Context) error {
+    err := services.HandleDonationWebhook(c.Param("provider"), c.Request())
+    switch {
+    case err == nil:
+        return c.JSON(http.StatusOK, map[string]bool{"success": true})
+    case errors.Is(err, payments.ErrBadSignature):
+        return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+    case errors.Is(err, payments.ErrBadEvent):
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+    case errors.Is(err, services.ErrDonationsDisabled), errors.Is(err, payments.ErrUnknownProvider), errors.Is(err, services.ErrDonationNotFound):
+        return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+    case errors.Is(err, services.ErrDonationMismatch), errors.Is(err, services.ErrDonationTransition):
+        return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+    }
+    return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process the notification"})
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "net/url"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+// GuildsTable — данные для partials/guilds_table.html
+type GuildsTable struct {
+    *services.GuildsPage
+    Query url.Values
+}
+
+type GuildsPageData struct {
+    PageData
+    Table GuildsTable
+}
+
+type GuildPageData struct {
+    PageData
+    Guild *services.GuildProfile
+    Error string
+}
+
+type AccountGuildsPageData struct {
+    PageData
+    Guilds []services.ManagedGuild
+}
+
+type GuildSettingsRequest struct {
+    Realm    int  `json:"realm" form:"realm"`
+    Guild    int  `json:"guild" form:"guild"`
+    ShowMOTD bool `json:"show_motd" form:"show_motd"`
+    ShowInfo bool `json:"show_info" form:"show_info"`
+}
+
+func (t GuildsTable) PageURL(page int) string {
+    q := cloneQuery(t.Query)
+    q.Set("page", strconv.Itoa(page))
+    return "/htmx/guilds-table?" + q.Encode()
+}
+
+func guildsTable(c echo.Context, realmID int) (GuildsTable, error) {
+    var q services.GuildQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+        return GuildsTable{}, echo.NewHTTPError(http.StatusBadRequest, "Invalid filter")
+    }
+    
+    result, err := services.ListGuilds(c.Request().Context(), realmID, q)
+    if err != nil {
+        return GuildsTable{}, err
+    }
+    
+    query := cloneQuery(c.QueryParams())
+    query.Set("realm", strconv.Itoa(realmID))
+    return GuildsTable{GuildsPage: result, Query: query}, nil
+}
+
+func guildStatus(err error) int {
+    switch {
+    case errors.Is(err, services.ErrGuildNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, services.ErrNotGuildMaster):
+        return http.StatusForbidden
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+func GuildsPageHandler(c echo.Context) error {
+    realm, ok := database.GetRealm(requestRealmID(c))
+    if !ok {
+        return echo.NewHTTPError(http.StatusNotFound, "Realm not found")
+    }
+    
+    table, err := guildsTable(c, realm.ID)
+    if err != nil {
+        return err
+    }
+    
+    return c.Render(http.StatusOK, "guilds.html", GuildsPageData{
+        PageData: PageData{
+            Title:       realm.Name + " - Guilds",
+            Description: "Guilds of " + realm.Name,
+            Config:      config.AppConfig,
+            Realms:      database.GetRealms(),
+            Realm:       realm,
+            RealmURL:    "/guilds?realm=%d",
+        },
+        Table: table,
+    })
+}
+
+func GuildsTableHTMXHandler(c echo.Context) error {
+    table, err := guildsTable(c, requestRealmID(c))
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Guild list is temporarily unavailable</div>
+        `)
+    }
+    
+    return c.Render(http.StatusOK, "partials/guilds_table.html", table)
+}
+
+func GuildsAPIHandler(c echo.Context) error {
+    realmID := requestRealmID(c)
+    if c.Param("id") != "" {
+        realm, err := realmFromParam(c)
+        if err != nil {
+            return err
+        }
+        realmID = realm.ID
+    }
+    
+    table, err := guildsTable(c, realmID)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, table.GuildsPage)
+}
+
+func GuildHandler(c echo.Context) error {
+    realm, err := realmFromPath(c)
+    if err != nil {
+        return err
+    }
+    
+    name := c.Param("name")
+    guild, err := services.GetGuildProfile(c.Request().Context(), realm.ID, name)
+    
+    data := GuildPageData{
+        PageData: PageData{
+            Title:       "<" + name + "> @ " + realm.Name,
+            Description: "Guild " + name + " on " + realm.Name,
+            Config:      config.AppConfig,
+            Realm:       realm,
+        },
+        Guild: guild,
+    }
+    
+    status := http.StatusOK
+    if err != nil {
+        status = guildStatus(err)
+        data.Error = err.Error()
+        if status == http.StatusInternalServerError {
+            data.Error = "Guild information is temporarily unavailable"
+        }
+    }
+    
+    return c.Render(status, "guild.html", data)
+}
+
+func GuildAPIHandler(c echo.Context) error {
+    realm, err := realmFromPath(c)
+    if err != nil {
+        return err
+    }
+    
+    guild, err := services.GetGuildProfile(c.Request().Context(), realm.ID, c.Param("name"))
+    if err != nil {
+        return c.JSON(guildStatus(err), map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, guild)
+}
+
+// AccountGuildsHandler — панель гильд-мастера: что показывать на странице гильдии
+func AccountGuildsHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    guilds, err := services.GuildsToManage(session.AccountID)
+    if err != nil {
+        return err
+    }
+    
+    return c.Render(http.StatusOK, "account_guilds.html", AccountGuildsPageData{
+        PageData: PageData{
+            Title:       "My Guilds",
+            Description: "Manage your guild pages",
+            Config:      config.AppConfig,
+        },
+        Guilds: guilds,
+    })
+}
+
+func GuildSettingsHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var req GuildSettingsRequest
+    if err := c.Bind(&req); err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+    }
+    
+    realm, ok := database.GetRealm(req.Realm)
+    if !ok {
+        return c.JSON(http.StatusNotFound, map[string]string{"error": "Realm not found"})
+    }
+    
+    settings := database.GuildSettings{ShowMOTD: req.ShowMOTD, ShowInfo: req.ShowInfo}
+    err := services.SetGuildSettings(c.Request().Context(), realm.ID, req.Guild, session.AccountID, settings)
+    
+    if isHTMX(c) {
+        if err != nil {
+            return c.HTML(http.StatusOK, `<div class="text-red-500 text-sm">Failed to save guild settings</div>`)
+        }
+        return c.HTML(http.StatusOK, `<div class="text-green-500 text-sm">✓ Saved</div>`)
+    }
+    if err != nil {
+        return c.JSON(guildStatus(err), map[string]string{"error": err.Error()})
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{"success": true})
+}
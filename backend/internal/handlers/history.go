@@ -0,0 +1,92 @@
+package handlers
+
+import (
+    "fmt"
+    "html/template"
+    "net/http"
+    "time"
+    "wow-registration/internal/charts"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type StatsChartsData struct {
+    RealmID            int
+    Range              string
+    Ranges             []services.HistoryRange
+    OnlineChart        template.HTML
+    PeakHoursChart     template.HTML
+    RegistrationsChart template.HTML
+}
+
+func historyRange(c echo.Context) (services.HistoryRange, error) {
+    name := c.QueryParam("range")
+    if name == "" {
+        name = "7d"
+    }
+    return services.FindHistoryRange(name)
+}
+
+func StatsHistoryHandler(c echo.Context) error {
+    r, err := historyRange(c)
+    if err != nil {
+        return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+    }
+    
+    history, err := services.GetStatsHistory(c.Request().Context(), requestRealmID(c), r)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, history)
+}
+
+func StatsChartsHTMXHandler(c echo.Context) error {
+    r, err := historyRange(c)
+    if err != nil {
+        r = services.HistoryRanges[1]
+    }
+    
+    realmID := requestRealmID(c)
+    history, err := services.GetStatsHistory(c.Request().Context(), realmID, r)
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Statistics are temporarily unavailable</div>
+        `)
+    }
+    
+    timeFormat := "Jan 2"
+    if r.Period <= 24*time.Hour {
+        timeFormat = "15:04"
+    }
+    
+    labels := make([]string, len(history.Online))
+    online := charts.Series{Name: "Online", Color: "#ffd100"}
+    alliance := charts.Series{Name: "Alliance", Color: "#0078ff"}
+    horde := charts.Series{Name: "Horde", Color: "#c41f3b"}
+    for i, p := range history.Online {
+        labels[i] = p.Time.Format(timeFormat)
+        online.Values = append(online.Values, p.Online)
+        alliance.Values = append(alliance.Values, p.Alliance)
+        horde.Values = append(horde.Values, p.Horde)
+    }
+    
+    peakHours := make([]charts.Bar, 0, len(history.PeakHours))
+    for _, h := range history.PeakHours {
+        peakHours = append(peakHours, charts.Bar{Label: fmt.Sprintf("%02d:00", h.Hour), Value: h.Online})
+    }
+    
+    registrations := make([]charts.Bar, 0, len(history.Registrations))
+    for _, d := range history.Registrations {
+        registrations = append(registrations, charts.Bar{Label: d.Day.Format("Jan 2"), Value: float64(d.Count)})
+    }
+    
+    return c.Render(http.StatusOK, "partials/stats_charts.html", StatsChartsData{
+        RealmID:            realmID,
+        Range:              r.Name,
+        Ranges:             services.HistoryRanges,
+        OnlineChart:        charts.Line("Online players", labels, []charts.Series{online, alliance, horde}),
+        PeakHoursChart:     charts.Bars("Average online by hour", "#ffd100", peakHours),
+        RegistrationsChart: charts.Bars("Registrations per day", "#22c55e", registrations),
+    })
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+// LeaderboardView — данные для partials/leaderboard.html
+type LeaderboardView struct {
+    *services.LeaderboardResult
+    Boards  []services.Leaderboard
+    Classes []gamedata.Class
+}
+
+type LeaderboardsPageData struct {
+    PageData
+    View LeaderboardView
+}
+
+func leaderboardView(c echo.Context, realmID int) (LeaderboardView, error) {
+    boards := services.Leaderboards()
+    if len(boards) == 0 {
+        return LeaderboardView{}, echo.NewHTTPError(http.StatusNotFound, "Leaderboards are disabled")
+    }
+    
+    slug := c.QueryParam("board")
+    if slug == "" {
+        slug = c.Param("board")
+    }
+    if slug == "" {
+        slug = boards[0].Slug
+    }
+    board, err := services.FindLeaderboard(slug)
+    if err != nil {
+        return LeaderboardView{}, echo.NewHTTPError(http.StatusNotFound, err.Error())
+    }
+    
+    class, _ := strconv.Atoi(c.QueryParam("class"))
+    result, err := services.GetLeaderboard(c.Request().Context(), realmID, board, class)
+    if err != nil {
+        return LeaderboardView{}, err
+    }
+    
+    return LeaderboardView{
+        LeaderboardResult: result,
+        Boards:            boards,
+        Classes:           gamedata.Classes(config.AppConfig.Game.Expansion),
+    }, nil
+}
+
+func LeaderboardsPageHandler(c echo.Context) error {
+    realm, ok := database.GetRealm(requestRealmID(c))
+    if !ok {
+        return echo.NewHTTPError(http.StatusNotFound, "Realm not found")
+    }
+    
+    view, err := leaderboardView(c, realm.ID)
+    if err != nil {
+        return err
+    }
+    
+    return c.Render(http.StatusOK, "leaderboards.html", LeaderboardsPageData{
+        PageData: PageData{
+            Title:       realm.Name + " - Leaderboards",
+            Description: "Community leaderboards on " + realm.Name,
+            Config:      config.AppConfig,
+            Realms:      database.GetRealms(),
+            Realm:       realm,
+            RealmURL:    "/leaderboards?realm=%d",
+        },
+        View: view,
+    })
+}
+
+func LeaderboardHTMXHandler(c echo.Context) error {
+    view, err := leaderboardView(c, requestRealmID(c))
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Leaderboard is temporarily unavailable</div>
+        `)
+    }
+    
+    return c.Render(http.StatusOK, "partials/leaderboard.html", view)
+}
+
+func LeaderboardsAPIHandler(c echo.Context) error {
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "leaderboards": services.Leaderboards(),
+    })
+}
+
+func LeaderboardAPIHandler(c echo.Context) error {
+    view, err := leaderboardView(c, requestRealmID(c))
+    if err != nil {
+        if he, ok := err.(*echo.HTTPError); ok {
+            return c.JSON(he.Code, map[string]interface{}{"error": he.Message})
+        }
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, view.LeaderboardResult)
+}
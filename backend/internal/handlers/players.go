@@ -0,0 +1,147 @@
+package handlers
+
+import (
+    "net/http"
+    "net/url"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+// PlayersTable — данные для partials/players_table.html, общие для страницы и HTMX
+type PlayersTable struct {
+    *services.OnlinePlayersPage
+    Query url.Values
+}
+
+type PlayersPageData struct {
+    PageData
+    Table   PlayersTable
+    Races   []gamedata.Race
+    Classes []gamedata.Class
+}
+
+// PageURL — ссылка на страницу page с текущими фильтрами
+func (t PlayersTable) PageURL(page int) string {
+    q := cloneQuery(t.Query)
+    q.Set("page", strconv.Itoa(page))
+    return "/htmx/players-table?" + q.Encode()
+}
+
+// SortURL переключает сортировку по полю, повторный клик меняет направление
+func (t PlayersTable) SortURL(field string) string {
+    q := cloneQuery(t.Query)
+    order := "desc"
+    if t.OnlinePlayersPage.Query.Sort == field && t.OnlinePlayersPage.Query.Order == "desc" {
+        order = "asc"
+    }
+    q.Set("sort", field)
+    q.Set("order", order)
+    q.Del("page")
+    return "/htmx/players-table?" + q.Encode()
+}
+
+func cloneQuery(values url.Values) url.Values {
+    q := url.Values{}
+    for k, v := range values {
+        q[k] = append([]string(nil), v...)
+    }
+    return q
+}
+
+func playersTable(c echo.Context, realmID int) (PlayersTable, error) {
+    var q services.PlayerQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+        return PlayersTable{}, echo.NewHTTPError(http.StatusBadRequest, "Invalid filter")
+    }
+    
+    result, err := services.ListOnlinePlayers(c.Request().Context(), realmID, q)
+    if err != nil {
+        return PlayersTable{}, err
+    }
+    
+    query := cloneQuery(c.QueryParams())
+    query.Set("realm", strconv.Itoa(realmID))
+    return PlayersTable{OnlinePlayersPage: result, Query: query}, nil
+}
+
+func renderPlayersPage(c echo.Context, realm *database.Realm) error {
+    table, err := playersTable(c, realm.ID)
+    if err != nil {
+        return err
+    }
+    
+    expansion := config.AppConfig.Game.Expansion
+    data := PlayersPageData{
+        PageData: PageData{
+            Title:       realm.Name + " - Online Players",
+            Description: "Players online on " + realm.Name,
+            Config:      config.AppConfig,
+            Realms:      database.GetRealms(),
+            Realm:       realm,
+            RealmURL:    "/realm/%d/players",
+        },
+        Table:   table,
+        Races:   gamedata.Races(expansion),
+        Classes: gamedata.Classes(expansion),
+    }
+    
+    return c.Render(http.StatusOK, "players.html", data)
+}
+
+func OnlinePlayersHandler(c echo.Context) error {
+    realm, ok := database.GetRealm(requestRealmID(c))
+    if !ok {
+        return echo.NewHTTPError(http.StatusNotFound, "Realm not found")
+    }
+    return renderPlayersPage(c, realm)
+}
+
+func OnlinePlayersAPIHandler(c echo.Context) error {
+    realmID := requestRealmID(c)
+    if c.Param("id") != "" {
+        realm, err := realmFromParam(c)
+        if err != nil {
+            return err
+        }
+        realmID = realm.ID
+    }
+    
+    table, err := playersTable(c, realmID)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, table.OnlinePlayersPage)
+}
+
+func PlayersTableHTMXHandler(c echo.Context) error {
+    table, err := playersTable(c, requestRealmID(c))
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Player list is temporarily unavailable</div>
+        `)
+    }
+    
+    return c.Render(http.StatusOK, "partials/players_table.html", table)
+}
+
+// Короткий список для боковой панели главной страницы
+func OnlinePlayersHTMXHandler(c echo.Context) error {
+    characters, err := services.GetOnlinePlayers(c.Request().Context(), requestRealmID(c))
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Player list is temporarily unavailable</div>
+        `)
+    }
+    
+    players := make([]services.OnlinePlayer, 0, len(characters))
+    for _, ch := range characters {
+        players = append(players, services.NewOnlinePlayer(ch))
+    }
+    
+    return c.Render(http.StatusOK, "partials/online_players.html", players)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "html"
+    "net/http"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AccountPointsPageData struct {
+    PageData
+    History *services.PointsHistory
+}
+
+type AdminPointsRequest struct {
+    Account string `json:"account" form:"account"`
+    Amount  int    `json:"amount" form:"amount"`
+    Reason  string `json:"reason" form:"reason"`
+}
+
+func AccountPointsHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    page, _ := strconv.Atoi(c.QueryParam("page"))
+    
+    history, err := services.GetPointsHistory(session.AccountID, page)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load points history")
+    }
+    
+    return c.Render(http.StatusOK, "account_points.html", AccountPointsPageData{
+        PageData: PageData{
+            Title:       "Points",
+            Description: "Your site points balance and history",
+            Config:      config.AppConfig,
+        },
+        History: history,
+    })
+}
+
+func PointsHistoryAPIHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    page, _ := strconv.Atoi(c.QueryParam("page"))
+    
+    history, err := services.GetPointsHistory(session.AccountID, page)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load points history"})
+    }
+    return c.JSON(http.StatusOK, history)
+}
+
+// AdminPointsHandler — ручное начисление или списание поинтов администратором
+func AdminPointsHandler(c echo.Context) error {
+    var req AdminPointsRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    entry, err := services.AdminAdjustPoints(adminActor(c), req.Account, req.Amount, req.Reason)
+    switch {
+    case errors.Is(err, services.ErrReasonRequired), errors.Is(err, services.ErrInvalidAmount):
+        return formError(c, http.StatusBadRequest, err.Error())
+    case errors.Is(err, services.ErrAccountNotFound):
+        return formError(c, http.StatusNotFound, err.Error())
+    case errors.Is(err, database.ErrInsufficientPoints):
+        return formError(c, http.StatusConflict, "The account doesn't have that many points")
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to adjust points")
+    }
+    
+    if isHTMX(c) {
+        message := fmt.Sprintf("Done, new balance of %s: %d", req.Account, entry.BalanceAfter)
+        return c.HTML(http.StatusOK, `<div class="text-green-500 text-sm">`+html.EscapeString(message)+`</div>`)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success": true,
+        "entry":   entry,
+    })
+}
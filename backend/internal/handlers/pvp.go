@@ -0,0 +1,234 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "net/url"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+// ArenaTable — данные для partials/arena_ladder.html
+type ArenaTable struct {
+    *services.ArenaLadderPage
+    Brackets []int
+    Query    url.Values
+}
+
+// HonorTable — данные для partials/honor_ladder.html
+type HonorTable struct {
+    *services.HonorLadderPage
+    Query url.Values
+}
+
+type PvPPageData struct {
+    PageData
+    Arena ArenaTable
+    Honor HonorTable
+}
+
+type ArenaTeamPageData struct {
+    PageData
+    Team  *services.ArenaTeamDetail
+    Error string
+}
+
+func (t ArenaTable) URL(key, value string) string {
+    q := cloneQuery(t.Query)
+    q.Set(key, value)
+    if key != "page" {
+        q.Del("page")
+    }
+    return "/htmx/arena-ladder?" + q.Encode()
+}
+
+func (t ArenaTable) PageURL(page int) string {
+    return t.URL("page", strconv.Itoa(page))
+}
+
+func (t HonorTable) URL(key, value string) string {
+    q := cloneQuery(t.Query)
+    q.Set(key, value)
+    if key != "page" {
+        q.Del("page")
+    }
+    return "/htmx/honor-ladder?" + q.Encode()
+}
+
+func (t HonorTable) PageURL(page int) string {
+    return t.URL("page", strconv.Itoa(page))
+}
+
+func arenaTable(c echo.Context, realmID int) (ArenaTable, error) {
+    var q services.ArenaQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+        return ArenaTable{}, echo.NewHTTPError(http.StatusBadRequest, "Invalid filter")
+    }
+    
+    result, err := services.GetArenaLadder(c.Request().Context(), realmID, q)
+    if err != nil {
+        return ArenaTable{}, err
+    }
+    
+    query := url.Values{}
+    query.Set("realm", strconv.Itoa(realmID))
+    query.Set("bracket", strconv.Itoa(result.Query.Bracket))
+    query.Set("season", strconv.Itoa(result.Query.Season))
+    return ArenaTable{ArenaLadderPage: result, Brackets: database.ArenaBrackets, Query: query}, nil
+}
+
+func honorTable(c echo.Context, realmID int) (HonorTable, error) {
+    var q services.HonorQuery
+    if err := (&echo.DefaultBinder{}).BindQueryParams(c, &q); err != nil {
+        return HonorTable{}, echo.NewHTTPError(http.StatusBadRequest, "Invalid filter")
+    }
+    
+    result, err := services.GetHonorLadder(c.Request().Context(), realmID, q)
+    if err != nil {
+        return HonorTable{}, err
+    }
+    
+    query := url.Values{}
+    query.Set("realm", strconv.Itoa(realmID))
+    query.Set("sort", result.Query.Kind)
+    return HonorTable{HonorLadderPage: result, Query: query}, nil
+}
+
+func PvPPageHandler(c echo.Context) error {
+    realm, ok := database.GetRealm(requestRealmID(c))
+    if !ok {
+        return echo.NewHTTPError(http.StatusNotFound, "Realm not found")
+    }
+    
+    arena, err := arenaTable(c, realm.ID)
+    if err != nil {
+        return err
+    }
+    
+    // Страница таблицы чести листается отдельно через HTMX
+    honor, err := services.GetHonorLadder(c.Request().Context(), realm.ID, services.HonorQuery{Kind: c.QueryParam("sort")})
+    if err != nil {
+        return err
+    }
+    honorQuery := url.Values{}
+    honorQuery.Set("realm", strconv.Itoa(realm.ID))
+    honorQuery.Set("sort", honor.Query.Kind)
+    
+    return c.Render(http.StatusOK, "pvp.html", PvPPageData{
+        PageData: PageData{
+            Title:       realm.Name + " - PvP Ladder",
+            Description: "Arena and honor rankings on " + realm.Name,
+            Config:      config.AppConfig,
+            Realms:      database.GetRealms(),
+            Realm:       realm,
+            RealmURL:    "/pvp?realm=%d",
+        },
+        Arena: arena,
+        Honor: HonorTable{HonorLadderPage: honor, Query: honorQuery},
+    })
+}
+
+func ArenaLadderHTMXHandler(c echo.Context) error {
+    table, err := arenaTable(c, requestRealmID(c))
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Arena ladder is temporarily unavailable</div>
+        `)
+    }
+    
+    return c.Render(http.StatusOK, "partials/arena_ladder.html", table)
+}
+
+func HonorLadderHTMXHandler(c echo.Context) error {
+    table, err := honorTable(c, requestRealmID(c))
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Honor ladder is temporarily unavailable</div>
+        `)
+    }
+    
+    return c.Render(http.StatusOK, "partials/honor_ladder.html", table)
+}
+
+func ArenaLadderAPIHandler(c echo.Context) error {
+    table, err := arenaTable(c, requestRealmID(c))
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, table.ArenaLadderPage)
+}
+
+func HonorLadderAPIHandler(c echo.Context) error {
+    table, err := honorTable(c, requestRealmID(c))
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, table.HonorLadderPage)
+}
+
+func arenaTeam(c echo.Context) (*database.Realm, *services.ArenaTeamDetail, error) {
+    realm, err := realmFromPath(c)
+    if err != nil {
+        return nil, nil, err
+    }
+    
+    teamID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        return realm, nil, services.ErrArenaTeamNotFound
+    }
+    season, _ := strconv.Atoi(c.QueryParam("season"))
+    
+    team, err := services.GetArenaTeam(c.Request().Context(), realm.ID, season, teamID)
+    return realm, team, err
+}
+
+func ArenaTeamHandler(c echo.Context) error {
+    realm, team, err := arenaTeam(c)
+    if realm == nil {
+        return err
+    }
+    
+    data := ArenaTeamPageData{
+        PageData: PageData{
+            Title:       "Arena Team",
+            Description: "Arena team on " + realm.Name,
+            Config:      config.AppConfig,
+            Realm:       realm,
+        },
+        Team: team,
+    }
+    
+    status := http.StatusOK
+    if err != nil {
+        status = http.StatusInternalServerError
+        data.Error = "Arena information is temporarily unavailable"
+        if errors.Is(err, services.ErrArenaTeamNotFound) {
+            status = http.StatusNotFound
+            data.Error = err.Error()
+        }
+    } else {
+        data.Title = team.Name + " - Arena Team"
+    }
+    
+    return c.Render(status, "arena_team.html", data)
+}
+
+func ArenaTeamAPIHandler(c echo.Context) error {
+    realm, team, err := arenaTeam(c)
+    if realm == nil {
+        return err
+    }
+    if errors.Is(err, services.ErrArenaTeamNotFound) {
+        return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+    }
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    return c.JSON(http.StatusOK, team)
+}
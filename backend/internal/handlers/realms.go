@@ -0,0 +1,111 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type RealmSummary struct {
+    database.Realm
+    OnlinePlayers int `json:"online_players"`
+}
+
+type StatusPageData struct {
+    PageData
+    RealmSummaries []RealmSummary
+}
+
+// requestRealmID берет реалм из ?realm=, иначе реалм по умолчанию
+func requestRealmID(c echo.Context) int {
+    if id, err := strconv.Atoi(c.QueryParam("realm")); err == nil {
+        if _, ok := database.GetRealm(id); ok {
+            return id
+        }
+    }
+    return database.DefaultRealmID()
+}
+
+// realmFromParam читает :id из пути и проверяет, что реалм подключен
+func realmFromParam(c echo.Context) (*database.Realm, error) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid realm id")
+    }
+    
+    realm, ok := database.GetRealm(id)
+    if !ok {
+        return nil, echo.NewHTTPError(http.StatusNotFound, "Realm not found")
+    }
+    return realm, nil
+}
+
+func realmSummaries(c echo.Context) []RealmSummary {
+    realms := database.GetRealms()
+    summaries := make([]RealmSummary, 0, len(realms))
+    for _, realm := range realms {
+        online, _ := services.CountOnlinePlayers(c.Request().Context(), realm.ID)
+        summaries = append(summaries, RealmSummary{Realm: realm, OnlinePlayers: online})
+    }
+    return summaries
+}
+
+func RealmsHandler(c echo.Context) error {
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "realms":        realmSummaries(c),
+        "default_realm": database.DefaultRealmID(),
+    })
+}
+
+func RealmStatusHandler(c echo.Context) error {
+    realm, err := realmFromParam(c)
+    if err != nil {
+        return err
+    }
+    
+    stats, err := services.GetServerStats(c.Request().Context(), realm.ID)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+    }
+    
+    onlinePlayers, _ := services.GetOnlinePlayers(c.Request().Context(), realm.ID)
+    
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "realm":          realm,
+        "stats":          stats,
+        "online_players": onlinePlayers,
+    })
+}
+
+func StatusPageHandler(c echo.Context) error {
+    realmID := requestRealmID(c)
+    realm, _ := database.GetRealm(realmID)
+    stats, _ := services.GetServerStats(c.Request().Context(), realmID)
+    
+    data := StatusPageData{
+        PageData: PageData{
+            Title:       "Server Status",
+            Description: "Realm status and statistics",
+            Config:      config.AppConfig,
+            Stats:       stats,
+            Realms:      database.GetRealms(),
+            Realm:       realm,
+            RealmURL:    "/status?realm=%d",
+        },
+        RealmSummaries: realmSummaries(c),
+    }
+    
+    return c.Render(http.StatusOK, "status.html", data)
+}
+
+func RealmPlayersPageHandler(c echo.Context) error {
+    realm, err := realmFromParam(c)
+    if err != nil {
+        return err
+    }
+    
+    return renderPlayersPage(c, realm)
+}
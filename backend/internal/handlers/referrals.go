@@ -0,0 +1,72 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+const (
+    referralCookie    = "ref"
+    referralCookieAge = 30 * 24 * time.Hour
+)
+
+type AccountReferralsPageData struct {
+    PageData
+    Referrals *services.ReferralDashboard
+}
+
+// rememberReferral запоминает ?ref= в cookie: игрок может зарегистрироваться
+// не сразу, а через несколько дней
+func rememberReferral(c echo.Context) string {
+    if !config.AppConfig.Custom.ReferralSystemEnabled {
+        return ""
+    }
+    
+    if ref := c.QueryParam("ref"); ref != "" && len(ref) <= 16 {
+        c.SetCookie(&http.Cookie{
+            Name:     referralCookie,
+            Value:    ref,
+            Path:     "/",
+            MaxAge:   int(referralCookieAge / time.Second),
+            HttpOnly: true,
+            SameSite: http.SameSiteLaxMode,
+        })
+        return ref
+    }
+    if cookie, err := c.Cookie(referralCookie); err == nil {
+        return cookie.Value
+    }
+    return ""
+}
+
+func AccountReferralsHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    dashboard, err := services.GetReferralDashboard(session.AccountID)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load referrals")
+    }
+    
+    return c.Render(http.StatusOK, "account_referrals.html", AccountReferralsPageData{
+        PageData: PageData{
+            Title:       "Invite Friends",
+            Description: "Your referral link and invited players",
+            Config:      config.AppConfig,
+        },
+        Referrals: dashboard,
+    })
+}
+
+func ReferralsAPIHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    dashboard, err := services.GetReferralDashboard(session.AccountID)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load referrals"})
+    }
+    return c.JSON(http.StatusOK, dashboard)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+    "database/sql"
+    "errors"
+    "html"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type LoginRequest struct {
+    Username string `json:"username" form:"username"`
+    Password string `json:"password" form:"password"`
+    Next     string `json:"next" form:"next"`
+}
+
+type LoginPageData struct {
+    PageData
+    Next string
+}
+
+// safeNext пропускает только локальные пути, чтобы не получить open redirect;
+// браузеры читают "\" как "/", поэтому "/\evil.com" тоже уводит на чужой хост
+func safeNext(next string) string {
+    if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.ContainsRune(next, '\\') {
+        return "/account"
+    }
+    for _, r := range next {
+        if r < 0x20 || r == 0x7f {
+            return "/account"
+        }
+    }
+    u, err := url.Parse(next)
+    if err != nil || u.Scheme != "" || u.Host != "" {
+        return "/account"
+    }
+    return next
+}
+
+func isHTMX(c echo.Context) bool {
+    return c.Request().Header.Get("HX-Request") != ""
+}
+
+func formError(c echo.Context, status int, message string) error {
+    if isHTMX(c) {
+        return c.HTML(http.StatusOK, `<div class="text-red-500 text-sm">`+html.EscapeString(message)+`</div>`)
+    }
+    return c.JSON(status, map[string]string{"error": message})
+}
+
+func setSessionCookie(c echo.Context, value string, maxAge time.Duration) {
+    c.SetCookie(&http.Cookie{
+        Name:     middleware.SessionCookie,
+        Value:    value,
+        Path:     "/",
+        MaxAge:   int(maxAge / time.Second),
+        HttpOnly: true,
+        Secure:   config.AppConfig.Server.Environment == "production",
+        SameSite: http.SameSiteLaxMode,
+    })
+}
+
+func LoginPageHandler(c echo.Context) error {
+    if middleware.CurrentSession(c) != nil {
+        return c.Redirect(http.StatusSeeOther, safeNext(c.QueryParam("next")))
+    }
+    
+    return c.Render(http.StatusOK, "login.html", LoginPageData{
+        PageData: PageData{
+            Title:       "Login",
+            Description: "Sign in with your game account",
+            Config:      config.AppConfig,
+        },
+        Next: safeNext(c.QueryParam("next")),
+    })
+}
+
+func LoginHandler(c echo.Context) error {
+    var req LoginRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    username := strings.ToUpper(req.Username)
+    if err := services.CheckIPBan(c.RealIP()); err != nil {
+        auditLoginFailure(c, username, 0, "ip_banned")
+        return banRefusal(c, err)
+    }
+    
+    account, err := database.GetAccountCredentials(username)
+    if err == sql.ErrNoRows {
+        auditLoginFailure(c, username, 0, "unknown_account")
+        return formError(c, http.StatusUnauthorized, "Invalid username or password")
+    }
+    if err != nil {
+        return formError(c, http.StatusInternalServerError, "Database error")
+    }
+    if !services.CheckPassword(account, req.Password) {
+        auditLoginFailure(c, username, account.ID, "wrong_password")
+        return formError(c, http.StatusUnauthorized, "Invalid username or password")
+    }
+    
+    // locked — привязка игрового входа к последнему IP, сайт ее не проверяет.
+    // Отключить аккаунт целиком можно только баном
+    if err := services.CheckAccountBan(account.ID); err != nil {
+        auditLoginFailure(c, username, account.ID, "banned")
+        return banRefusal(c, err)
+    }
+    
+    session, err := services.CreateSession(c.Request().Context(), account, c.RealIP())
+    if err != nil {
+        return formError(c, http.StatusInternalServerError, "Failed to create session")
+    }
+    setSessionCookie(c, session.Token, services.SessionLifetime())
+    services.Audit(requestActor(c).WithAccount(account.ID, account.Username), services.AuditLogin, account.ID, nil)
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", safeNext(req.Next))
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "success":  true,
+        "username": account.Username,
+    })
+}
+
+// auditLoginFailure — неудачный вход; accountID 0 — аккаунт не найден
+// или до проверки дело не дошло
+func auditLoginFailure(c echo.Context, username string, accountID int, reason string) {
+    services.AuditLoginFailure(c.Request().Context(), requestActor(c), username, accountID, reason)
+}
+
+// banRefusal показывает причину и срок бана; прочие ошибки проверки — сбой базы
+func banRefusal(c echo.Context, err error) error {
+    var banned *services.BannedError
+    if errors.As(err, &banned) {
+        return formError(c, http.StatusForbidden, banned.Error())
+    }
+    return formError(c, http.StatusInternalServerError, "Database error")
+}
+
+func LogoutHandler(c echo.Context) error {
+    if session := middleware.CurrentSession(c); session != nil {
+        services.DeleteSession(c.Request().Context(), session)
+    }
+    setSessionCookie(c, "", -time.Second)
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/")
+        return c.NoContent(http.StatusOK)
+    }
+    return c.Redirect(http.StatusSeeOther, "/")
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+    "errors"
+    "html"
+    "net/http"
+    "strconv"
+    "strings"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "wow-registration/internal/soap"
+    "github.com/labstack/echo/v4"
+)
+
+type ShopPurchaseRequest struct {
+    Product   int    `json:"product" form:"product"`
+    Character string `json:"character" form:"character"`
+    Key       string `json:"key" form:"key"`
+}
+
+type AdminProductRequest struct {
+    ID          int    `json:"id" form:"id"`
+    Kind        string `json:"kind" form:"kind"`
+    Name        string `json:"name" form:"name"`
+    Description string `json:"description" form:"description"`
+    Payload     string `json:"payload" form:"payload"`
+    Price       int    `json:"price" form:"price"`
+    SortOrder   int    `json:"sort_order" form:"sort_order"`
+    Enabled     bool   `json:"enabled" form:"enabled"`
+}
+
+type AccountShopPageData struct {
+    PageData
+    Products    []database.ShopProduct
+    Orders      []database.ShopOrder
+    Characters  []services.AccountCharacter
+    Points      int
+    PurchaseKey string
+}
+
+type AdminShopPageData struct {
+    PageData
+    Products      []database.ShopProduct
+    Edit          database.ShopProduct
+    MaxBoostLevel int
+}
+
+func AccountShopHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    products, orders, err := services.ShopOverview(session.AccountID)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load the shop")
+    }
+    overview, err := services.GetAccountOverview(session.Username)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load account")
+    }
+    points, _ := database.GetPointsBalance(session.AccountID)
+    
+    return c.Render(http.StatusOK, "account_shop.html", AccountShopPageData{
+        PageData: PageData{
+            Title:       "Shop",
+            Description: "Spend your points on items, gold and character services",
+            Config:      config.AppConfig,
+        },
+        Products:    products,
+        Orders:      orders,
+        Characters:  overview.Characters,
+        Points:      points,
+        PurchaseKey: services.NewPurchaseKey(),
+    })
+}
+
+func ShopProductsAPIHandler(c echo.Context) error {
+    products, err := database.GetShopProducts(true)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load products"})
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "enabled":  config.AppConfig.Custom.ShopEnabled,
+        "products": products,
+    })
+}
+
+func ShopOrdersAPIHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    _, orders, err := services.ShopOverview(session.AccountID)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load orders"})
+    }
+    return c.JSON(http.StatusOK, orders)
+}
+
+// shopResult отвечает на покупку; для HTMX заодно выдает новый ключ покупки,
+// чтобы следующий клик был новым заказом, а не повтором этого
+func shopResult(c echo.Context, status int, class, message string) error {
+    if isHTMX(c) {
+        key := `<input type="hidden" id="purchase-key" name="key" value="` + services.NewPurchaseKey() + `" hx-swap-oob="true">`
+        return c.HTML(http.StatusOK, `<div class="`+class+` text-sm">`+html.EscapeString(message)+`</div>`+key)
+    }
+    if status != http.StatusOK {
+        return c.JSON(status, map[string]string{"error": message})
+    }
+    return c.JSON(status, map[string]interface{}{"success": true, "message": message})
+}
+
+func ShopPurchaseHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var req ShopPurchaseRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    var realmID, guid int
+    if realmRaw, guidRaw, ok := strings.Cut(req.Character, ":"); ok {
+        realmID, _ = strconv.Atoi(realmRaw)
+        guid, _ = strconv.Atoi(guidRaw)
+    }
+    
+    result, err := services.Purchase(c.Request().Context(), session.AccountID, req.Product, realmID, guid, req.Key)
+    if err != nil {
+        fail := func(status int, message string) error {
+            return shopResult(c, status, "text-red-500", message)
+        }
+        switch {
+        case errors.Is(err, services.ErrOrderKeyRequired):
+            // Ключ не меняем: без него форма устарела, и ее нужно перезагрузить
+            return formError(c, http.StatusBadRequest, err.Error())
+        case errors.Is(err, services.ErrUnknownProduct):
+            return fail(http.StatusNotFound, err.Error())
+        case errors.Is(err, services.ErrNotYourCharacter):
+            return fail(http.StatusForbidden, err.Error())
+        case errors.Is(err, services.ErrLevelTooHigh), errors.Is(err, services.ErrInvalidProduct):
+            return fail(http.StatusConflict, err.Error())
+        case errors.Is(err, database.ErrInsufficientPoints):
+            return fail(http.StatusPaymentRequired, "Not enough points")
+        case errors.Is(err, services.ErrShopDisabled), errors.Is(err, soap.ErrDisabled):
+            return fail(http.StatusServiceUnavailable, "The shop is not available right now")
+        }
+        return fail(http.StatusInternalServerError, "Failed to complete the purchase")
+    }
+    
+    if !isHTMX(c) {
+        return c.JSON(http.StatusOK, result)
+    }
+    order := result.Order
+    switch order.Status {
+    case database.OrderDone:
+        return shopResult(c, http.StatusOK, "text-green-500", order.Name+" was delivered to "+order.CharacterName)
+    case database.OrderRefunded:
+        return shopResult(c, http.StatusOK, "text-red-500", "Delivery failed, your points were refunded")
+    case database.OrderFailed:
+        return shopResult(c, http.StatusOK, "text-red-500", "This purchase was not completed")
+    case database.OrderReview:
+        return shopResult(c, http.StatusOK, "text-yellow-500", "The game server did not confirm the delivery, an administrator will check this order")
+    }
+    return shopResult(c, http.StatusOK, "text-yellow-500", "Paid. The game server is busy, "+order.Name+" will be delivered shortly")
+}
+
+func AdminShopHandler(c echo.Context) error {
+    products, err := database.GetShopProducts(false)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load products")
+    }
+    
+    edit := database.ShopProduct{Kind: database.ProductItem, Enabled: true}
+    if id, _ := strconv.Atoi(c.QueryParam("edit")); id > 0 {
+        product, err := database.GetShopProduct(id)
+        if err != nil {
+            return echo.NewHTTPError(http.StatusNotFound, "Product not found")
+        }
+        edit = *product
+    }
+    
+    return c.Render(http.StatusOK, "admin_shop.html", AdminShopPageData{
+        PageData: PageData{
+            Title:       "Shop Catalog",
+            Description: "Shop products",
+            Config:      config.AppConfig,
+        },
+        Products:      products,
+        Edit:          edit,
+        MaxBoostLevel: services.MaxBoostLevel(),
+    })
+}
+
+func AdminShopSaveHandler(c echo.Context) error {
+    var req AdminProductRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    product := &database.ShopProduct{
+        ID:          req.ID,
+        Kind:        req.Kind,
+        Name:        req.Name,
+        Description: req.Description,
+        Payload:     req.Payload,
+        Price:       req.Price,
+        SortOrder:   req.SortOrder,
+        Enabled:     req.Enabled,
+    }
+    var previous *database.ShopProduct
+    if req.ID > 0 {
+        previous, _ = database.GetShopProduct(req.ID)
+    }
+    err := services.SaveProduct(product)
+    switch {
+    case errors.Is(err, services.ErrInvalidProduct):
+        return formError(c, http.StatusBadRequest, err.Error())
+    case errors.Is(err, services.ErrUnknownProduct):
+        return formError(c, http.StatusNotFound, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to save the product")
+    }
+    event := services.AuditEvent{
+        Action: services.AuditShopProduct,
+        Target: "product/" + strconv.Itoa(product.ID),
+        After:  product,
+    }
+    if previous != nil {
+        event.Before = previous
+    }
+    services.RecordAudit(adminActor(c), event)
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/admin/shop")
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, product)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/middleware"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AccountVotePageData struct {
+    PageData
+    Sites      []services.VoteSiteStatus
+    Characters []services.AccountCharacter
+    Points     int
+    RewardItem bool
+}
+
+func AccountVoteHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    sites, err := services.GetVoteStatus(session.AccountID, c.RealIP())
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load vote sites")
+    }
+    overview, err := services.GetAccountOverview(session.Username)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load account")
+    }
+    points, _ := database.GetPointsBalance(session.AccountID)
+    
+    return c.Render(http.StatusOK, "account_vote.html", AccountVotePageData{
+        PageData: PageData{
+            Title:       "Vote for Us",
+            Description: "Vote for the server on top sites and get rewards",
+            Config:      config.AppConfig,
+        },
+        Sites:      sites,
+        Characters: overview.Characters,
+        Points:     points,
+        RewardItem: config.AppConfig.Custom.VoteRewardItem != "",
+    })
+}
+
+func VoteStatusAPIHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    sites, err := services.GetVoteStatus(session.AccountID, c.RealIP())
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load vote sites"})
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{
+        "enabled": config.AppConfig.Custom.VoteSystemEnabled,
+        "sites":   sites,
+    })
+}
+
+// VoteRedirectHandler заводит голос и уводит игрока на топ.
+// ?character=<realm>:<guid> — кому отправить предметную награду
+func VoteRedirectHandler(c echo.Context) error {
+    session := middleware.CurrentSession(c)
+    
+    var realmID, guid int
+    if realmRaw, guidRaw, ok := strings.Cut(c.QueryParam("character"), ":"); ok {
+        realmID, _ = strconv.Atoi(realmRaw)
+        guid, _ = strconv.Atoi(guidRaw)
+    }
+    
+    target, err := services.StartVote(session.AccountID, c.RealIP(), c.Param("site"), realmID, guid)
+    if err != nil {
+        var cooldown *services.CooldownError
+        switch {
+        case errors.As(err, &cooldown):
+            return echo.NewHTTPError(http.StatusTooManyRequests, "You can vote on this site "+cooldown.Error())
+        case errors.Is(err, services.ErrUnknownVoteSite), errors.Is(err, services.ErrVotingDisabled):
+            return echo.NewHTTPError(http.StatusNotFound, err.Error())
+        case errors.Is(err, services.ErrNotYourCharacter):
+            return echo.NewHTTPError(http.StatusForbidden, err.Error())
+        }
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to start the vote")
+    }
+    
+    return c.Redirect(http.StatusFound, target)
+}
+
+// VotePostbackHandler — обратный вызов топа; token и signature берутся из query или формы
+func VotePostbackHandler(c echo.Context) error {
+    err := services.HandleVotePostback(c.Request().Context(), c.Param("site"), c.FormValue("token"), c.FormValue("signature"), c.RealIP())
+    
+    var cooldown *services.CooldownError
+    switch {
+    case err == nil:
+        return c.JSON(http.StatusOK, map[string]bool{"success": true})
+    case errors.Is(err, services.ErrVoteUnverified):
+        return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+    case errors.Is(err, services.ErrUnknownVoteSite), errors.Is(err, services.ErrVotingDisabled), errors.Is(err, services.ErrVoteNotFound):
+        return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+    case errors.Is(err, services.ErrVoteAlreadyFinal), errors.As(err, &cooldown):
+        // Топ уже получил свой ответ, повторный postback не ошибка
+        return c.JSON(http.StatusOK, map[string]interface{}{"success": false, "error": err.Error()})
+    }
+    return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to credit the vote"})
+}
@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "os"
+    "testing"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/handlers"
+    "wow-registration/internal/services"
+    "wow-registration/internal/services/votetest"
+    _ "github.com/go-sql-driver/mysql"
+    "github.com/labstack/echo/v4"
+)
+
+const (
+    testAccountID = 999999001
+    testIP        = "203.0.113.7"
+)
+
+// testDB подключает пустую базу сайта из WOW_TEST_MYSQL_DSN
+// (например "root:root@tcp(127.0.0.1:3306)/wow_test?parseTime=true") и
+// накатывает миграции; без нее тест пропускается
+func testDB(t *testing.T) {
+    dsn := os.Getenv("WOW_TEST_MYSQL_DSN")
+    if dsn == "" {
+        t.Skip("WOW_TEST_MYSQL_DSN is not set")
+    }
+    
+    db, err := sql.Open("mysql", dsn)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := db.Ping(); err != nil {
+        t.Fatal(err)
+    }
+    database.DB = db
+    if err := database.Migrate(); err != nil {
+        t.Fatal(err)
+    }
+    
+    cleanup := func() {
+        for _, table := range []string{"web_votes", "web_points", "web_points_ledger"} {
+            db.Exec("DELETE FROM "+table+" WHERE account_id = ?", testAccountID)
+        }
+    }
+    cleanup()
+    t.Cleanup(func() {
+        cleanup()
+        db.Close()
+    })
+}
+
+// Полный цикл: игрок уходит на фейковый топ, тот шлет подписанный postback,
+// голос засчитывается один раз, дальше действует кулдаун
+func TestVoteCycle(t *testing.T) {
+    testDB(t)
+    
+    e := echo.New()
+    e.Match([]string{http.MethodGet, http.MethodPost}, "/api/vote/postback/:site", handlers.VotePostbackHandler)
+    server := httptest.NewServer(e)
+    defer server.Close()
+    
+    top := votetest.NewTopSite(server.URL+"/api/vote/postback/test", "top-secret")
+    defer top.Close()
+    
+    sites, _ := json.Marshal([]services.VoteSite{top.Site("test", 5)})
+    config.AppConfig = &config.Config{}
+    config.AppConfig.Custom.VoteSystemEnabled = true
+    config.AppConfig.Custom.VoteSites = string(sites)
+    
+    // Второй голос начат до того, как засчитан первый: кулдаун его еще не видит
+    first, err := services.StartVote(testAccountID, testIP, "test", 0, 0)
+    if err != nil {
+        t.Fatalf("StartVote: %v", err)
+    }
+    second, err := services.StartVote(testAccountID, testIP, "test", 0, 0)
+    if err != nil {
+        t.Fatalf("StartVote: %v", err)
+    }
+    
+    visit(t, first)
+    if errs := top.Errors(); len(errs) > 0 {
+        t.Fatalf("postback failed: %v", errs)
+    }
+    assertBalance(t, 5)
+    
+    // Повтор того же postback ничего не начисляет
+    token := top.Tokens()[0]
+    if err := top.Postback(token); err != nil {
+        t.Fatalf("repeated postback: %v", err)
+    }
+    err = services.HandleVotePostback(context.Background(), "test", token, services.VoteSignature(top.Secret, token), "")
+    if !errors.Is(err, services.ErrVoteAlreadyFinal) {
+        t.Fatalf("repeated postback: got %v, want ErrVoteAlreadyFinal", err)
+    }
+    assertBalance(t, 5)
+    
+    // Голос, начатый до кулдауна, отклоняется при postback
+    visit(t, second)
+    rejected, err := database.GetVoteByToken("test", top.Tokens()[1])
+    if err != nil {
+        t.Fatal(err)
+    }
+    if rejected.Status != database.VoteRejected {
+        t.Fatalf("vote during cooldown: status %q, want %q", rejected.Status, database.VoteRejected)
+    }
+    assertBalance(t, 5)
+    
+    // Новый голос не начать, пока идет кулдаун
+    var cooldown *services.CooldownError
+    if _, err := services.StartVote(testAccountID, testIP, "test", 0, 0); !errors.As(err, &cooldown) {
+        t.Fatalf("StartVote during cooldown: got %v, want CooldownError", err)
+    }
+    
+    // Postback с чужой подписью не принимается
+    resp, err := http.PostForm(top.PostbackURL, url.Values{"token": {token}, "signature": {"forged"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusForbidden {
+        t.Fatalf("forged postback: status %d, want %d", resp.StatusCode, http.StatusForbidden)
+    }
+}
+
+func visit(t *testing.T, target string) {
+    t.Helper()
+    resp, err := http.Get(target)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+}
+
+func assertBalance(t *testing.T, want int) {
+    t.Helper()
+    balance, err := database.GetPointsBalance(testAccountID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if balance != want {
+        t.Fatalf("balance %d, want %d", balance, want)
+    }
+}
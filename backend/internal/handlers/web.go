@@ -1,11 +1,15 @@
 package handlers
 
 import (
+    "fmt"
     "html/template"
+    "io"
     "net/http"
-    "os"
     "path/filepath"
+    "time"
+    "wow-registration/internal/config"
     "wow-registration/internal/database"
+    "wow-registration/internal/services"
     "github.com/labstack/echo/v4"
 )
 
@@ -14,7 +18,16 @@ type Template struct {
 }
 
 func NewTemplateRenderer() *Template {
-    tmpl := template.New("")
+    tmpl := template.New("").Funcs(template.FuncMap{
+        "now": time.Now,
+        "add": func(a, b int) int { return a + b },
+        "sub": func(a, b int) int { return a - b },
+        "playtime": playtime,
+        "money": money,
+        "cents": cents,
+        "duration": duration,
+        "unixtime": func(sec int64) time.Time { return time.Unix(sec, 0) },
+    })
     
     // Автоматически загружаем все шаблоны
     templateDir := "./frontend/templates"
@@ -24,6 +37,39 @@ func NewTemplateRenderer() *Template {
     return &Template{templates: tmpl}
 }
 
+// playtime форматирует /played из секунд: "12d 4h 5m"
+func playtime(seconds int64) string {
+    d := seconds / 86400
+    h := seconds % 86400 / 3600
+    m := seconds % 3600 / 60
+    if d > 0 {
+        return fmt.Sprintf("%dd %dh %dm", d, h, m)
+    }
+    return fmt.Sprintf("%dh %dm", h, m)
+}
+
+// money форматирует медь как в игре: "1234g 5s 6c"
+func money(copper int64) string {
+    return fmt.Sprintf("%dg %ds %dc", copper/10000, copper%10000/100, copper%100)
+}
+
+// cents — сумма платежа из центов: "10.00"
+func cents(amount int64) string {
+    return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+}
+
+// duration — кулдауны и сроки: "30m", "24h", "7d"
+func duration(d time.Duration) string {
+    switch {
+    case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+        return fmt.Sprintf("%dd", d/(24*time.Hour))
+    case d >= time.Hour && d%time.Hour == 0:
+        return fmt.Sprintf("%dh", d/time.Hour)
+    default:
+        return fmt.Sprintf("%dm", d/time.Minute)
+    }
+}
+
 func (t *Template) Render(w io.Writer, name string, data interface{}, c echo.Context) error {
     return t.templates.ExecuteTemplate(w, name, data)
 }
@@ -34,43 +80,70 @@ type PageData struct {
     Config      interface{}
     Stats       map[string]interface{}
     OnlinePlayers []database.Character
+    Realms      []database.Realm
+    Realm       *database.Realm
+    RealmURL    string
+}
+
+type HomePageData struct {
+    PageData
+    Referral string
 }
 
 func HomeHandler(c echo.Context) error {
-    stats, _ := database.GetServerStats()
-    onlinePlayers, _ := database.GetOnlinePlayers(1) // realmID = 1
+    realmID := requestRealmID(c)
+    stats, _ := services.GetServerStats(c.Request().Context(), realmID)
+    onlinePlayers, _ := services.GetOnlinePlayers(c.Request().Context(), realmID)
     
-    data := PageData{
-        Title:       "WoW Server Registration",
-        Description: "Register your World of Warcraft account",
-        Config:      config.AppConfig,
-        Stats:       stats,
-        OnlinePlayers: onlinePlayers,
+    data := HomePageData{
+        PageData: PageData{
+            Title:       "WoW Server Registration",
+            Description: "Register your World of Warcraft account",
+            Config:      config.AppConfig,
+            Stats:       stats,
+            OnlinePlayers: onlinePlayers,
+        },
+        Referral: rememberReferral(c),
     }
     
     return c.Render(http.StatusOK, "index.html", data)
 }
 
 func StatusHandler(c echo.Context) error {
-    stats, err := database.GetServerStats()
+    realmID := requestRealmID(c)
+    stats, err := services.GetServerStats(c.Request().Context(), realmID)
     if err != nil {
         return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
     }
     
-    onlinePlayers, _ := database.GetOnlinePlayers(1)
+    onlinePlayers, _ := services.GetOnlinePlayers(c.Request().Context(), realmID)
+    serverStatus, _ := services.GetServerStatus(c.Request().Context())
     
     return c.JSON(http.StatusOK, map[string]interface{}{
         "stats": stats,
         "online_players": onlinePlayers,
+        "server_status": serverStatus,
     })
 }
 
 func RealTimeStatsHandler(c echo.Context) error {
-    stats, _ := database.GetServerStats()
-    onlinePlayers, _ := database.GetOnlinePlayers(1)
+    realmID := requestRealmID(c)
+    stats, _ := services.GetServerStats(c.Request().Context(), realmID)
+    onlinePlayers, _ := services.GetOnlinePlayers(c.Request().Context(), realmID)
     
     return c.Render(http.StatusOK, "partials/stats.html", map[string]interface{}{
         "stats": stats,
         "online_players": onlinePlayers,
     })
 }
+
+func RealmStatusHTMXHandler(c echo.Context) error {
+    serverStatus, err := services.GetServerStatus(c.Request().Context())
+    if err != nil {
+        return c.HTML(http.StatusOK, `
+            <div class="text-red-500 text-sm">Status is temporarily unavailable</div>
+        `)
+    }
+    
+    return c.Render(http.StatusOK, "partials/realm_status.html", serverStatus)
+}
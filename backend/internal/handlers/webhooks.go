@@ -0,0 +1,187 @@
+package handlers
+
+import (
+    "errors"
+    "html"
+    "net/http"
+    "strconv"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+type AdminWebhookRequest struct {
+    ID          int      `json:"id" form:"id"`
+    URL         string   `json:"url" form:"url"`
+    Description string   `json:"description" form:"description"`
+    Events      []string `json:"events" form:"events"`
+    Enabled     bool     `json:"enabled" form:"enabled"`
+}
+
+type AdminWebhooksPageData struct {
+    PageData
+    Webhooks   []database.Webhook
+    Edit       database.Webhook
+    Events     []string
+    Deliveries []database.WebhookDelivery
+    LogFilter  int
+}
+
+func AdminWebhooksHandler(c echo.Context) error {
+    hooks, err := database.GetWebhooks(false)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load webhooks")
+    }
+    
+    edit := database.Webhook{Enabled: true}
+    if id, _ := strconv.Atoi(c.QueryParam("edit")); id > 0 {
+        hook, err := database.GetWebhook(id)
+        if err != nil {
+            return echo.NewHTTPError(http.StatusNotFound, "Webhook not found")
+        }
+        edit = *hook
+    }
+    
+    filter, _ := strconv.Atoi(c.QueryParam("webhook"))
+    deliveries, err := services.WebhookLog(filter)
+    if err != nil {
+        return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load deliveries")
+    }
+    
+    return c.Render(http.StatusOK, "admin_webhooks.html", AdminWebhooksPageData{
+        PageData: PageData{
+            Title:       "Webhooks",
+            Description: "Outbound webhooks",
+            Config:      config.AppConfig,
+        },
+        Webhooks:   hooks,
+        Edit:       edit,
+        Events:     services.WebhookEvents,
+        Deliveries: deliveries,
+        LogFilter:  filter,
+    })
+}
+
+// webhookTarget — webhook в журнале аудита
+func webhookTarget(id int) string {
+    return "webhook/" + strconv.Itoa(id)
+}
+
+func AdminWebhookSaveHandler(c echo.Context) error {
+    var req AdminWebhookRequest
+    if err := c.Bind(&req); err != nil {
+        return formError(c, http.StatusBadRequest, "Invalid request format")
+    }
+    
+    hook := &database.Webhook{
+        ID:          req.ID,
+        URL:         req.URL,
+        Description: req.Description,
+        Events:      req.Events,
+        Enabled:     req.Enabled,
+    }
+    var previous *database.Webhook
+    if req.ID > 0 {
+        previous, _ = database.GetWebhook(req.ID)
+    }
+    err := services.SaveWebhook(hook)
+    switch {
+    case errors.Is(err, services.ErrInvalidWebhook):
+        return formError(c, http.StatusBadRequest, err.Error())
+    case errors.Is(err, services.ErrUnknownWebhook):
+        return formError(c, http.StatusNotFound, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to save the webhook")
+    }
+    event := services.AuditEvent{
+        Action: services.AuditWebhookSave,
+        Target: webhookTarget(hook.ID),
+        After:  hook,
+    }
+    // Секрет в журнал не попадает: у Webhook он скрыт из JSON
+    if previous != nil {
+        event.Before = previous
+        if saved, err := database.GetWebhook(hook.ID); err == nil {
+            event.After = saved
+        }
+    }
+    services.RecordAudit(adminActor(c), event)
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/admin/webhooks?edit="+strconv.Itoa(hook.ID))
+        return c.NoContent(http.StatusOK)
+    }
+    // Секрет отдаем один раз, при создании: дальше он виден только в панели
+    resp := map[string]interface{}{"webhook": hook}
+    if req.ID == 0 {
+        resp["secret"] = hook.Secret
+    }
+    return c.JSON(http.StatusOK, resp)
+}
+
+func AdminWebhookDeleteHandler(c echo.Context) error {
+    id, _ := strconv.Atoi(c.Param("id"))
+    if err := database.DeleteWebhook(id); err != nil {
+        return formError(c, http.StatusInternalServerError, "Failed to delete the webhook")
+    }
+    services.RecordAudit(adminActor(c), services.AuditEvent{Action: services.AuditWebhookDelete, Target: webhookTarget(id)})
+    
+    if isHTMX(c) {
+        c.Response().Header().Set("HX-Redirect", "/admin/webhooks")
+        return c.NoContent(http.StatusOK)
+    }
+    return c.JSON(http.StatusOK, map[string]bool{"success": true})
+}
+
+func AdminWebhookSecretHandler(c echo.Context) error {
+    id, _ := strconv.Atoi(c.Param("id"))
+    secret, err := services.RotateWebhookSecret(id)
+    switch {
+    case errors.Is(err, services.ErrUnknownWebhook):
+        return formError(c, http.StatusNotFound, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to rotate the secret")
+    }
+    services.RecordAudit(adminActor(c), services.AuditEvent{Action: services.AuditWebhookSecret, Target: webhookTarget(id)})
+    
+    if isHTMX(c) {
+        return c.HTML(http.StatusOK, `<code class="break-all text-green-500">`+html.EscapeString(secret)+`</code>`)
+    }
+    return c.JSON(http.StatusOK, map[string]string{"secret": secret})
+}
+
+func AdminWebhookDeliveriesAPIHandler(c echo.Context) error {
+    filter, _ := strconv.Atoi(c.QueryParam("webhook"))
+    deliveries, err := services.WebhookLog(filter)
+    if err != nil {
+        return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to load deliveries"})
+    }
+    if deliveries == nil {
+        deliveries = []database.WebhookDelivery{}
+    }
+    return c.JSON(http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+func AdminWebhookRedeliverHandler(c echo.Context) error {
+    id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+    delivery, err := services.RedeliverWebhook(id)
+    switch {
+    case errors.Is(err, services.ErrUnknownWebhook):
+        return formError(c, http.StatusNotFound, "Delivery not found")
+    case errors.Is(err, services.ErrNotRedeliverable):
+        return formError(c, http.StatusConflict, err.Error())
+    case err != nil:
+        return formError(c, http.StatusInternalServerError, "Failed to queue the delivery")
+    }
+    services.RecordAudit(adminActor(c), services.AuditEvent{
+        Action:  services.AuditWebhookRedeliver,
+        Target:  webhookTarget(delivery.WebhookID),
+        Details: map[string]interface{}{"delivery_id": id},
+    })
+    
+    if isHTMX(c) {
+        return c.HTML(http.StatusOK, `<span class="text-green-500 text-sm">Queued as #`+strconv.FormatInt(delivery.ID, 10)+`</span>`)
+    }
+    return c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+    "net/http"
+    "net/url"
+    "wow-registration/internal/services"
+    "github.com/labstack/echo/v4"
+)
+
+const (
+    SessionCookie = "session"
+    sessionCtxKey = "session"
+)
+
+// LoadSession подхватывает веб-сессию из cookie, если она есть
+func LoadSession(next echo.HandlerFunc) echo.HandlerFunc {
+    return func(c echo.Context) error {
+        if cookie, err := c.Cookie(SessionCookie); err == nil {
+            if session, err := services.GetSession(c.Request().Context(), cookie.Value); err == nil {
+                c.Set(sessionCtxKey, session)
+            }
+        }
+        return next(c)
+    }
+}
+
+// RequireAuth пропускает только залогиненных: API получает 401, страницы — редирект на /login
+func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
+    return func(c echo.Context) error {
+        if CurrentSession(c) != nil {
+            return next(c)
+        }
+        
+        if c.Request().Method != http.MethodGet || c.Request().Header.Get("HX-Request") != "" {
+            return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+        }
+        return c.Redirect(http.StatusSeeOther, "/login?next="+url.QueryEscape(c.Request().URL.RequestURI()))
+    }
+}
+
+// RequireAdmin ставится после RequireAuth и пускает только аккаунты с GM-уровнем
+// не ниже ADMIN_GM_LEVEL
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+    return func(c echo.Context) error {
+        session := CurrentSession(c)
+        if session != nil && services.IsAdmin(session.AccountID) {
+            return next(c)
+        }
+        
+        if c.Request().Method != http.MethodGet || c.Request().Header.Get("HX-Request") != "" {
+            return c.JSON(http.StatusForbidden, map[string]string{"error": "Access denied"})
+        }
+        return echo.NewHTTPError(http.StatusForbidden, "Access denied")
+    }
+}
+
+func CurrentSession(c echo.Context) *services.Session {
+    session, _ := c.Get(sessionCtxKey).(*services.Session)
+    return session
+}
@@ -0,0 +1,103 @@
+// Package payments — платежные провайдеры для пожертвований. Провайдер строит
+// ссылку на оплату и разбирает уведомления (webhook) о смене статуса платежа
+package payments
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strings"
+    "sync"
+    "wow-registration/internal/config"
+)
+
+var (
+    ErrUnknownProvider = errors.New("payments: unknown provider")
+    ErrBadSignature    = errors.New("payments: webhook signature mismatch")
+    ErrBadEvent        = errors.New("payments: malformed webhook event")
+)
+
+// Статусы платежа
+const (
+    StatusPending    = "pending"
+    StatusPaid       = "paid"
+    StatusRefunded   = "refunded"
+    StatusChargeback = "chargeback"
+)
+
+// Checkout — данные для перехода донора на оплату
+type Checkout struct {
+    // Reference — наш идентификатор платежа, провайдер возвращает его в webhook
+    Reference string
+    // Amount — в минимальных единицах валюты (центах)
+    Amount    int64
+    Currency  string
+    AccountID int
+}
+
+// Event — уведомление провайдера о платеже
+type Event struct {
+    Reference string `json:"reference"`
+    PaymentID string `json:"id"`
+    Status    string `json:"status"`
+    Amount    int64  `json:"amount"`
+    Currency  string `json:"currency"`
+}
+
+type Provider interface {
+    Slug() string
+    Name() string
+    // CheckoutURL — куда отправить донора для оплаты
+    CheckoutURL(c Checkout) (string, error)
+    // ParseWebhook проверяет подлинность уведомления и разбирает его
+    ParseWebhook(r *http.Request) (*Event, error)
+}
+
+var (
+    mu         sync.RWMutex
+    registered []Provider
+)
+
+// Register добавляет провайдера со своей схемой уведомлений в дополнение
+// к описанным в DONATION_PROVIDERS
+func Register(p Provider) {
+    mu.Lock()
+    defer mu.Unlock()
+    registered = append(registered, p)
+}
+
+// Providers — провайдеры из конфига и зарегистрированные в коде
+func Providers() []Provider {
+    var providers []Provider
+    if raw := strings.TrimSpace(config.AppConfig.Custom.DonationProviders); raw != "" {
+        var configs []ProviderConfig
+        if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+            log.Printf("DONATION_PROVIDERS: %v", err)
+        }
+        for _, cfg := range configs {
+            providers = append(providers, NewSignedWebhook(cfg))
+        }
+    }
+    
+    mu.RLock()
+    defer mu.RUnlock()
+    return append(providers, registered...)
+}
+
+func Find(slug string) (Provider, error) {
+    for _, p := range Providers() {
+        if p.Slug() == slug {
+            return p, nil
+        }
+    }
+    return nil, ErrUnknownProvider
+}
+
+func validStatus(status string) bool {
+    switch status {
+    case StatusPending, StatusPaid, StatusRefunded, StatusChargeback:
+        return true
+    }
+    return false
+}
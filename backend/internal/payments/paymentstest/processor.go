@@ -0,0 +1,127 @@
+// Package paymentstest — фейковый платежный процессор для проверки полного
+// цикла пожертвования: донор уходит на CheckoutURL, процессор шлет подписанные
+// уведомления на наш webhook
+package paymentstest
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "sync"
+    "wow-registration/internal/payments"
+)
+
+type Processor struct {
+    *httptest.Server
+    
+    // WebhookURL — адрес DonationWebhookHandler, например base + "/api/donations/webhook/test"
+    WebhookURL string
+    Secret     string
+    
+    mu        sync.Mutex
+    checkouts map[string]payments.Event
+    sequence  int
+}
+
+func NewProcessor(webhookURL, secret string) *Processor {
+    p := &Processor{WebhookURL: webhookURL, Secret: secret, checkouts: make(map[string]payments.Event)}
+    p.Server = httptest.NewServer(http.HandlerFunc(p.serve))
+    return p
+}
+
+// Config — запись для DONATION_PROVIDERS, указывающая на этот процессор
+func (p *Processor) Config(slug string) payments.ProviderConfig {
+    return payments.ProviderConfig{
+        Slug:        slug,
+        Name:        "Test Processor " + slug,
+        CheckoutURL: p.URL + "/checkout?reference={reference}&amount={amount_minor}&currency={currency}",
+        Secret:      p.Secret,
+    }
+}
+
+// References — платежи, по которым доноры заходили на оплату
+func (p *Processor) References() []string {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    
+    refs := make([]string, 0, len(p.checkouts))
+    for ref := range p.checkouts {
+        refs = append(refs, ref)
+    }
+    return refs
+}
+
+func (p *Processor) serve(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    amount, err := strconv.ParseInt(q.Get("amount"), 10, 64)
+    if q.Get("reference") == "" || err != nil {
+        http.Error(w, "bad checkout", http.StatusBadRequest)
+        return
+    }
+    
+    p.mu.Lock()
+    p.sequence++
+    p.checkouts[q.Get("reference")] = payments.Event{
+        Reference: q.Get("reference"),
+        PaymentID: fmt.Sprintf("test-%d", p.sequence),
+        Status:    payments.StatusPending,
+        Amount:    amount,
+        Currency:  q.Get("currency"),
+    }
+    p.mu.Unlock()
+    
+    fmt.Fprintln(w, "Checkout page")
+}
+
+// Pay, Refund и Chargeback шлют уведомление о платеже, по которому был checkout
+func (p *Processor) Pay(reference string) error { return p.notify(reference, payments.StatusPaid) }
+func (p *Processor) Refund(reference string) error {
+    return p.notify(reference, payments.StatusRefunded)
+}
+func (p *Processor) Chargeback(reference string) error {
+    return p.notify(reference, payments.StatusChargeback)
+}
+
+func (p *Processor) notify(reference, status string) error {
+    p.mu.Lock()
+    event, ok := p.checkouts[reference]
+    p.mu.Unlock()
+    if !ok {
+        return fmt.Errorf("no checkout for %s", reference)
+    }
+    
+    event.Status = status
+    return p.Send(event)
+}
+
+// Send подписывает и отправляет произвольное уведомление — в том числе
+// неправильное, чтобы проверить отказ
+func (p *Processor) Send(event payments.Event) error {
+    body, err := json.Marshal(event)
+    if err != nil {
+        return err
+    }
+    
+    req, err := http.NewRequest(http.MethodPost, p.WebhookURL, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set(payments.SignatureHeader, payments.Sign(p.Secret, body))
+    
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    
+    if resp.StatusCode != http.StatusOK {
+        data, _ := io.ReadAll(resp.Body)
+        return fmt.Errorf("webhook: %d %s", resp.StatusCode, data)
+    }
+    return nil
+}
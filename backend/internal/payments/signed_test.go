@@ -0,0 +1,96 @@
+package payments_test
+
+import (
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "wow-registration/internal/payments"
+    "wow-registration/internal/payments/paymentstest"
+)
+
+// Цикл платежа через фейковый процессор: checkout по нашей ссылке, затем
+// подписанные уведомления о смене статуса
+func TestSignedWebhook(t *testing.T) {
+    var mu sync.Mutex
+    var events []payments.Event
+    var provider *payments.SignedWebhook
+    
+    receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        event, err := provider.ParseWebhook(r)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusForbidden)
+            return
+        }
+        mu.Lock()
+        events = append(events, *event)
+        mu.Unlock()
+    }))
+    defer receiver.Close()
+    
+    processor := paymentstest.NewProcessor(receiver.URL, "shared-secret")
+    defer processor.Close()
+    provider = payments.NewSignedWebhook(processor.Config("test"))
+    
+    checkout, err := provider.CheckoutURL(payments.Checkout{Reference: "don-1", Amount: 1050, Currency: "EUR", AccountID: 7})
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp, err := http.Get(checkout)
+    if err != nil {
+        t.Fatal(err)
+    }
+    resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        t.Fatalf("checkout: status %d", resp.StatusCode)
+    }
+    
+    if err := processor.Pay("don-1"); err != nil {
+        t.Fatalf("Pay: %v", err)
+    }
+    if err := processor.Chargeback("don-1"); err != nil {
+        t.Fatalf("Chargeback: %v", err)
+    }
+    
+    mu.Lock()
+    defer mu.Unlock()
+    if len(events) != 2 || events[0].Status != payments.StatusPaid || events[1].Status != payments.StatusChargeback {
+        t.Fatalf("events: %+v", events)
+    }
+    if e := events[0]; e.Reference != "don-1" || e.Amount != 1050 || e.Currency != "EUR" || e.PaymentID == "" {
+        t.Fatalf("paid event: %+v", e)
+    }
+}
+
+func TestSignedWebhookRejects(t *testing.T) {
+    provider := payments.NewSignedWebhook(payments.ProviderConfig{Slug: "test", Secret: "shared-secret"})
+    
+    receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        _, err := provider.ParseWebhook(r)
+        switch {
+        case errors.Is(err, payments.ErrBadSignature):
+            http.Error(w, err.Error(), http.StatusForbidden)
+        case errors.Is(err, payments.ErrBadEvent):
+            http.Error(w, err.Error(), http.StatusBadRequest)
+        case err != nil:
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+        }
+    }))
+    defer receiver.Close()
+    
+    // Чужой секрет
+    forger := paymentstest.NewProcessor(receiver.URL, "other-secret")
+    defer forger.Close()
+    if err := forger.Send(payments.Event{Reference: "don-1", PaymentID: "x", Status: payments.StatusPaid, Amount: 100, Currency: "EUR"}); err == nil || !strings.Contains(err.Error(), "403") {
+        t.Fatalf("webhook signed with another secret: got %v, want 403", err)
+    }
+    
+    // Правильная подпись, но неизвестный статус
+    processor := paymentstest.NewProcessor(receiver.URL, "shared-secret")
+    defer processor.Close()
+    if err := processor.Send(payments.Event{Reference: "don-1", PaymentID: "x", Status: "gift", Amount: 100, Currency: "EUR"}); err == nil || !strings.Contains(err.Error(), "400") {
+        t.Fatalf("webhook with an unknown status: got %v, want 400", err)
+    }
+}
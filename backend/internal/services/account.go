@@ -0,0 +1,95 @@
+package services
+
+import (
+    "log"
+    "time"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+// Сколько последних входов показывать в личном кабинете
+const loginHistorySize = 10
+
+type AccountCharacter struct {
+    OnlinePlayer
+    RealmID    int        `json:"realm_id"`
+    RealmName  string     `json:"realm_name"`
+    Online     bool       `json:"online"`
+    TotalTime  int64      `json:"total_time"`
+    LastLogout *time.Time `json:"last_logout,omitempty"`
+}
+
+type AccountLogin struct {
+    IP   string    `json:"ip"`
+    Time time.Time `json:"time"`
+}
+
+type AccountOverview struct {
+    ID            int                `json:"id"`
+    Username      string             `json:"username"`
+    Email         string             `json:"email"`
+    Expansion     int                `json:"expansion"`
+    ExpansionName string             `json:"expansion_name"`
+    JoinDate      time.Time          `json:"join_date"`
+    LastLogin     *time.Time         `json:"last_login,omitempty"`
+    LastIP        string             `json:"last_ip"`
+    Locked        bool               `json:"locked"`
+    Characters    []AccountCharacter `json:"characters"`
+    Logins        []AccountLogin     `json:"logins"` // nil — ядро не хранит историю входов
+}
+
+// GetAccountOverview собирает данные для личного кабинета; недоступный реалм
+// просто пропускается, чтобы кабинет открывался и при лежащем сервере
+func GetAccountOverview(username string) (*AccountOverview, error) {
+    account, err := database.GetAccountByUsername(username)
+    if err != nil {
+        return nil, err
+    }
+    
+    overview := &AccountOverview{
+        ID:            account.ID,
+        Username:      account.Username,
+        Email:         account.Email,
+        Expansion:     account.Expansion,
+        ExpansionName: gamedata.ExpansionName(account.Expansion),
+        JoinDate:      account.CreatedAt,
+        LastIP:        account.IP,
+        Locked:        account.Locked,
+        Characters:    []AccountCharacter{},
+    }
+    if account.LastLogin.Valid {
+        overview.LastLogin = &account.LastLogin.Time
+    }
+    
+    for _, realm := range database.GetRealms() {
+        characters, err := database.GetAccountCharacterDetails(realm.ID, account.ID)
+        if err != nil {
+            log.Printf("account %d: realm %d characters: %v", account.ID, realm.ID, err)
+            continue
+        }
+        for _, c := range characters {
+            character := AccountCharacter{
+                OnlinePlayer: NewOnlinePlayer(c.Character),
+                RealmID:      realm.ID,
+                RealmName:    realm.Name,
+                Online:       c.Online,
+                TotalTime:    c.TotalTime,
+            }
+            if c.LogoutTime > 0 {
+                logout := time.Unix(c.LogoutTime, 0)
+                character.LastLogout = &logout
+            }
+            overview.Characters = append(overview.Characters, character)
+        }
+    }
+    
+    history, err := database.GetLoginHistory(account.ID, loginHistorySize)
+    if err == nil {
+        overview.Logins = []AccountLogin{}
+        for _, record := range history {
+            overview.Logins = append(overview.Logins, AccountLogin{IP: record.IP, Time: record.Time})
+        }
+    }
+    
+    return overview, nil
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+const (
+    adminSearchPageSize   = 50
+    adminLoginHistorySize = 50
+)
+
+var (
+    ErrNotBanned        = errors.New("account is not banned")
+    ErrProtectedAccount = errors.New("staff accounts cannot be banned")
+    ErrInvalidDuration  = errors.New("invalid ban duration, use e.g. 30m, 12h, 7d or perm")
+    ErrForbiddenTarget  = errors.New("you can only manage accounts below your own GM level")
+    ErrInvalidExpansion = errors.New("this expansion is not available on the server")
+    ErrInvalidGMLevel   = errors.New("the GM level must be between 0 and your own level")
+    ErrEmptyNote        = errors.New("the note is empty")
+)
+
+// AdminActor — кто выполняет действие: администратор сайта (AccountID),
+// сотрудник из Telegram (AccountID == 0) или игрок для событий безопасности.
+// Name попадает в bannedby и журнал; остальное — контекст запроса для журнала
+type AdminActor struct {
+    AccountID int
+    Name      string
+    IP        string
+    UserAgent string
+    RequestID string
+}
+
+// WithAccount — тот же запрос от имени игрока, который действует над своим
+// аккаунтом без сессии: регистрация, вход, ссылка из письма
+func (a AdminActor) WithAccount(accountID int, name string) AdminActor {
+    a.AccountID = accountID
+    a.Name = name
+    return a
+}
+
+// AccountLookup — карточка аккаунта для персонала
+type AccountLookup struct {
+    *AccountOverview
+    GMLevel int                  `json:"gm_level"`
+    Ban     *database.AccountBan `json:"ban,omitempty"`
+}
+
+// ParseBanDuration понимает "perm" (бессрочно, 0) и длительности Go с
+// добавкой дней: "30m", "12h", "7d", "1d12h"
+func ParseBanDuration(s string) (time.Duration, error) {
+    s = strings.ToLower(strings.TrimSpace(s))
+    if s == "perm" || s == "permanent" {
+        return 0, nil
+    }
+    
+    var days int
+    if before, after, ok := strings.Cut(s, "d"); ok {
+        n, err := strconv.Atoi(before)
+        if err != nil || n <= 0 {
+            return 0, ErrInvalidDuration
+        }
+        days, s = n, after
+    }
+    var rest time.Duration
+    if s != "" {
+        var err error
+        if rest, err = time.ParseDuration(s); err != nil || rest < 0 {
+            return 0, ErrInvalidDuration
+        }
+    }
+    
+    duration := time.Duration(days)*24*time.Hour + rest
+    if duration < time.Minute {
+        return 0, ErrInvalidDuration
+    }
+    return duration, nil
+}
+
+func findAccount(username string) (*database.Account, error) {
+    account, err := database.GetAccountByUsername(strings.ToUpper(strings.TrimSpace(username)))
+    if err == sql.ErrNoRows {
+        return nil, ErrAccountNotFound
+    }
+    return account, err
+}
+
+// LookupAccount — данные аккаунта, GM-уровень и действующий бан
+func LookupAccount(username string) (*AccountLookup, error) {
+    overview, err := GetAccountOverview(strings.ToUpper(strings.TrimSpace(username)))
+    if err == sql.ErrNoRows {
+        return nil, ErrAccountNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    
+    lookup := &AccountLookup{AccountOverview: overview}
+    if lookup.GMLevel, err = database.GetGMLevel(overview.ID); err != nil {
+        return nil, err
+    }
+    if lookup.Ban, err = database.GetActiveBan(overview.ID); err != nil {
+        return nil, err
+    }
+    return lookup, nil
+}
+
+// BanAccount банит аккаунт username на duration (0 — бессрочно), выкидывает
+// его персонажей из игры и завершает сессии на сайте. Аккаунты персонала
+// банить нельзя
+func BanAccount(ctx context.Context, actor AdminActor, username string, duration time.Duration, reason string) (*database.AccountBan, error) {
+    reason, err := banReason(reason)
+    if err != nil {
+        return nil, err
+    }
+    account, err := findAccount(username)
+    if err != nil {
+        return nil, err
+    }
+    if IsAdmin(account.ID) {
+        return nil, ErrProtectedAccount
+    }
+    
+    if err := database.BanAccount(account.ID, duration, actor.Name, reason); err != nil {
+        return nil, err
+    }
+    log.Printf("admin: %s banned %s for %s (%s)", actor.Name, account.Username, banDurationText(duration), reason)
+    
+    kickAccount(ctx, account.ID)
+    if err := DeleteAccountSessions(ctx, account.ID); err != nil {
+        log.Printf("admin: drop sessions of %s: %v", account.Username, err)
+    }
+    
+    ban := &database.AccountBan{AccountID: account.ID, BannedAt: time.Now(), BannedBy: actor.Name, Reason: reason}
+    if duration > 0 {
+        until := ban.BannedAt.Add(duration)
+        ban.Until = &until
+    }
+    
+    Audit(actor, AuditAccountBan, account.ID, map[string]interface{}{
+        "duration": banDurationText(duration),
+        "reason":   reason,
+    })
+    EmitWebhook(WebhookAccountBanned, map[string]interface{}{
+        "account_id": account.ID,
+        "username":   account.Username,
+        "banned_by":  ban.BannedBy,
+        "reason":     ban.Reason,
+        "until":      ban.Until, // null — бессрочно
+    })
+    return ban, nil
+}
+
+// UnbanAccount снимает действующий бан
+func UnbanAccount(actor AdminActor, username string) error {
+    account, err := findAccount(username)
+    if err != nil {
+        return err
+    }
+    
+    lifted, err := database.UnbanAccount(account.ID, actor.Name)
+    if err != nil {
+        return err
+    }
+    if !lifted {
+        return ErrNotBanned
+    }
+    
+    log.Printf("admin: %s unbanned %s", actor.Name, account.Username)
+    Audit(actor, AuditAccountUnban, account.ID, nil)
+    return nil
+}
+
+// kickAccount выкидывает онлайн-персонажей аккаунта через SOAP. Не критично:
+// без SOAP бан все равно не даст войти снова
+func kickAccount(ctx context.Context, accountID int) {
+    for _, realm := range database.GetRealms() {
+        characters, err := database.GetAccountCharacterDetails(realm.ID, accountID)
+        if err != nil {
+            continue
+        }
+        for _, c := range characters {
+            if !c.Online {
+                continue
+            }
+            client, err := realmSOAP(realm.ID)
+            if err != nil {
+                break
+            }
+            if _, err := client.Execute(ctx, "kick "+c.Name); err != nil {
+                log.Printf("admin: kick %s on realm %d: %v", c.Name, realm.ID, err)
+            }
+        }
+    }
+}
+
+func banDurationText(d time.Duration) string {
+    if d == 0 {
+        return "permanent"
+    }
+    if d%(24*time.Hour) == 0 {
+        return fmt.Sprintf("%dd", d/(24*time.Hour))
+    }
+    return d.String()
+}
+
+// Для сообщений персоналу: "until 2024-05-01 12:00 UTC" или "permanently"
+func banUntilText(ban *database.AccountBan) string {
+    if ban.Until == nil {
+        return "permanently"
+    }
+    return "until " + ban.Until.UTC().Format("2006-01-02 15:04") + " UTC"
+}
+
+type AccountSearchResult struct {
+    Accounts []database.AccountSummary `json:"accounts"`
+    Total    int                       `json:"total"`
+    Page     int                       `json:"page"`
+    Pages    int                       `json:"pages"`
+}
+
+// SearchAccounts — страница результатов поиска аккаунтов
+func SearchAccounts(filter database.AccountSearch, page int) (*AccountSearchResult, error) {
+    if page < 1 {
+        page = 1
+    }
+    filter.Limit = adminSearchPageSize
+    filter.Offset = (page - 1) * adminSearchPageSize
+    
+    accounts, total, err := database.SearchAccounts(filter)
+    if err != nil {
+        return nil, err
+    }
+    if accounts == nil {
+        accounts = []database.AccountSummary{}
+    }
+    return &AccountSearchResult{
+        Accounts: accounts,
+        Total:    total,
+        Page:     page,
+        Pages:    (total + adminSearchPageSize - 1) / adminSearchPageSize,
+    }, nil
+}
+
+// AdminAccount — все, что панель показывает об аккаунте
+type AdminAccount struct {
+    *AccountOverview
+    GMLevel int                    `json:"gm_level"`
+    Ban     *database.AccountBan   `json:"ban,omitempty"`
+    Bans    []database.AccountBan  `json:"bans"`
+    Notes   []database.AccountNote `json:"notes"`
+    Points  *PointsHistory         `json:"points"`
+    Audit   []database.AuditEntry  `json:"audit"`
+}
+
+// GetAdminAccount собирает карточку аккаунта. Недоступные у ядра данные
+// (история входов, баны) просто остаются пустыми
+func GetAdminAccount(accountID int) (*AdminAccount, error) {
+    account, err := database.GetAccountByID(accountID)
+    if err == sql.ErrNoRows {
+        return nil, ErrAccountNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    overview, err := GetAccountOverview(account.Username)
+    if err != nil {
+        return nil, err
+    }
+    
+    // Персоналу нужна история подлиннее, чем игроку в кабинете
+    if history, err := database.GetLoginHistory(accountID, adminLoginHistorySize); err == nil {
+        overview.Logins = []AccountLogin{}
+        for _, record := range history {
+            overview.Logins = append(overview.Logins, AccountLogin{IP: record.IP, Time: record.Time})
+        }
+    }
+    
+    result := &AdminAccount{AccountOverview: overview}
+    if result.GMLevel, err = database.GetGMLevel(accountID); err != nil {
+        return nil, err
+    }
+    if result.Ban, err = database.GetActiveBan(accountID); err != nil {
+        log.Printf("admin: account %d ban: %v", accountID, err)
+    }
+    if result.Bans, err = database.GetBanHistory(accountID, 20); err != nil {
+        log.Printf("admin: account %d bans: %v", accountID, err)
+    }
+    if result.Notes, err = database.GetAccountNotes(accountID); err != nil {
+        return nil, err
+    }
+    if result.Points, err = GetPointsHistory(accountID, 1); err != nil {
+        return nil, err
+    }
+    if result.Audit, err = RecentAudit(accountID); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+// manageableAccount загружает аккаунт и проверяет, что его GM-уровень ниже,
+// чем у того, кто действует; себя менять тоже нельзя. У цели берется
+// наибольший уровень по всем реалмам, чтобы GM одного реалма не выглядел игроком
+func manageableAccount(actor AdminActor, accountID int) (*database.Account, error) {
+    account, err := database.GetAccountByID(accountID)
+    if err == sql.ErrNoRows {
+        return nil, ErrAccountNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    
+    actorLevel, err := database.GetGMLevel(actor.AccountID)
+    if err != nil {
+        return nil, err
+    }
+    targetLevel, err := database.GetHighestGMLevel(accountID)
+    if err != nil {
+        return nil, err
+    }
+    if actor.AccountID == accountID || targetLevel >= actorLevel {
+        return nil, ErrForbiddenTarget
+    }
+    return account, nil
+}
+
+// SetAccountLock включает или снимает привязку входа к последнему IP
+func SetAccountLock(actor AdminActor, accountID int, locked bool) error {
+    account, err := manageableAccount(actor, accountID)
+    if err != nil {
+        return err
+    }
+    if err := database.SetAccountLocked(account.ID, locked); err != nil {
+        return err
+    }
+    
+    action := AuditAccountUnlock
+    if locked {
+        action = AuditAccountLock
+    }
+    RecordAudit(actor, AuditEvent{
+        Action:          action,
+        TargetAccountID: account.ID,
+        Before:          map[string]interface{}{"locked": account.Locked},
+        After:           map[string]interface{}{"locked": locked},
+    })
+    return nil
+}
+
+func SetAccountExpansion(actor AdminActor, accountID, expansion int) error {
+    if expansion < 0 || expansion > config.AppConfig.Game.Expansion {
+        return ErrInvalidExpansion
+    }
+    account, err := manageableAccount(actor, accountID)
+    if err != nil {
+        return err
+    }
+    if err := database.SetAccountExpansion(account.ID, expansion); err != nil {
+        return err
+    }
+    
+    RecordAudit(actor, AuditEvent{
+        Action:          AuditAccountExpansion,
+        TargetAccountID: account.ID,
+        Before:          map[string]interface{}{"expansion": account.Expansion},
+        After:           map[string]interface{}{"expansion": expansion},
+    })
+    return nil
+}
+
+// ResetAccountTwoFactor отключает аутентификатор, если игрок его потерял
+func ResetAccountTwoFactor(actor AdminActor, accountID int) error {
+    account, err := manageableAccount(actor, accountID)
+    if err != nil {
+        return err
+    }
+    if err := database.ResetTwoFactor(account.ID); err != nil {
+        return err
+    }
+    Audit(actor, AuditAccountTwoFactor, account.ID, nil)
+    return nil
+}
+
+// ForcePasswordReset делает текущий пароль недействительным и отправляет
+// игроку ссылку для выбора нового
+func ForcePasswordReset(ctx context.Context, actor AdminActor, accountID int) error {
+    account, err := manageableAccount(actor, accountID)
+    if err != nil {
+        return err
+    }
+    if err := forcePasswordReset(ctx, account); err != nil {
+        return err
+    }
+    Audit(actor, AuditAccountPasswordReset, account.ID, nil)
+    return nil
+}
+
+// SetAccountGMLevel меняет GM-уровень; выдать можно не выше своего
+func SetAccountGMLevel(actor AdminActor, accountID, level int) error {
+    account, err := manageableAccount(actor, accountID)
+    if err != nil {
+        return err
+    }
+    actorLevel, err := database.GetGMLevel(actor.AccountID)
+    if err != nil {
+        return err
+    }
+    if level < 0 || level > actorLevel {
+        return ErrInvalidGMLevel
+    }
+    
+    // SetGMLevel снимает и права на отдельных реалмах, поэтому в аудит
+    // попадают все прежние строки, а не только общий уровень
+    previous, err := database.GetGMLevel(account.ID)
+    if err != nil {
+        return err
+    }
+    realms, err := database.GetRealmGMLevels(account.ID)
+    if err != nil {
+        return err
+    }
+    if err := database.SetGMLevel(account.ID, level); err != nil {
+        return err
+    }
+    after := []database.RealmGMLevel{}
+    if level > 0 {
+        after = append(after, database.RealmGMLevel{RealmID: -1, Level: level})
+    }
+    
+    log.Printf("admin: %s set GM level of %s to %d", actor.Name, account.Username, level)
+    RecordAudit(actor, AuditEvent{
+        Action:          AuditAccountGMLevel,
+        TargetAccountID: account.ID,
+        Before:          map[string]interface{}{"gm_level": previous, "realm_levels": realms},
+        After:           map[string]interface{}{"gm_level": level, "realm_levels": after},
+    })
+    return nil
+}
+
+// AddAccountNote — заметка персонала к аккаунту; писать можно к любому
+func AddAccountNote(actor AdminActor, accountID int, text string) (*database.AccountNote, error) {
+    text = strings.TrimSpace(text)
+    if text == "" {
+        return nil, ErrEmptyNote
+    }
+    text = database.TruncateText(text, 2000)
+    if _, err := database.GetAccountByID(accountID); err == sql.ErrNoRows {
+        return nil, ErrAccountNotFound
+    } else if err != nil {
+        return nil, err
+    }
+    
+    note := &database.AccountNote{AccountID: accountID, AuthorID: actor.AccountID, AuthorName: actor.Name, Note: text}
+    if err := database.AddAccountNote(note); err != nil {
+        return nil, err
+    }
+    Audit(actor, AuditAccountNote, accountID, map[string]interface{}{"note_id": note.ID})
+    return note, nil
+}
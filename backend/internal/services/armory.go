@@ -0,0 +1,306 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+var (
+    ErrCharacterNotFound = errors.New("character not found")
+    ErrArmoryHidden      = errors.New("this character's profile is hidden by its owner")
+)
+
+type ArmoryItem struct {
+    Slot         int    `json:"slot"`
+    SlotName     string `json:"slot_name"`
+    Entry        int    `json:"entry"`
+    Name         string `json:"name"`
+    Quality      int    `json:"quality"`
+    QualityColor string `json:"quality_color"`
+    ItemLevel    int    `json:"item_level"`
+}
+
+type ArmoryTalentSpec struct {
+    Spec   int   `json:"spec"`
+    Spells []int `json:"spells"`
+}
+
+type ArmoryProfession struct {
+    ID        int    `json:"id"`
+    Name      string `json:"name"`
+    Secondary bool   `json:"secondary"`
+    Value     int    `json:"value"`
+    Max       int    `json:"max"`
+}
+
+type ArmoryReputation struct {
+    Faction   int    `json:"faction"`
+    Name      string `json:"name"`
+    Standing  int    `json:"standing"`
+    Rank      string `json:"rank"`
+    RankColor string `json:"rank_color"`
+    Value     int    `json:"value"`
+    Max       int    `json:"max"`
+}
+
+type ArmoryProfile struct {
+    OnlinePlayer
+    RealmID           int                `json:"realm_id"`
+    Online            bool               `json:"online"`
+    TotalTime         int64              `json:"total_time"`
+    TotalKills        int                `json:"total_kills"`
+    GuildName         string             `json:"guild_name,omitempty"`
+    Items             []ArmoryItem       `json:"items"`
+    AverageItemLevel  int                `json:"average_item_level"`
+    Talents           []ArmoryTalentSpec `json:"talents"`
+    Professions       []ArmoryProfession `json:"professions"`
+    Reputation        []ArmoryReputation `json:"reputation"`
+    Achievements      int                `json:"achievements"`
+    AchievementPoints int                `json:"achievement_points"`
+    UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+// Hidden не уходит в JSON API, но нужен в кэше — поэтому храним отдельную обертку
+type cachedArmoryProfile struct {
+    Profile *ArmoryProfile `json:"profile"`
+    Hidden  bool           `json:"hidden"`
+}
+
+func armoryTTL() time.Duration {
+    return time.Duration(config.AppConfig.Cache.ArmoryDuration) * time.Second
+}
+
+func armoryKey(realmID int, name string) string {
+    return fmt.Sprintf("armory:%d:%s", realmID, strings.ToLower(name))
+}
+
+// GetArmoryProfile собирает публичную страницу персонажа; результат кэшируется на персонажа
+func GetArmoryProfile(ctx context.Context, realmID int, name string) (*ArmoryProfile, error) {
+    cached, err := cache.GetOrLoad(ctx, armoryKey(realmID, name), armoryTTL(), func(ctx context.Context) (*cachedArmoryProfile, error) {
+        return loadArmoryProfile(ctx, realmID, name)
+    })
+    if err != nil {
+        return nil, err
+    }
+    if cached.Hidden {
+        return nil, ErrArmoryHidden
+    }
+    return cached.Profile, nil
+}
+
+// SetArmoryHidden включает или выключает скрытие персонажа владельцем
+func SetArmoryHidden(ctx context.Context, realmID, guid, accountID int, hidden bool) error {
+    owner, name, err := database.GetCharacterOwner(realmID, guid)
+    if err == sql.ErrNoRows || (err == nil && owner != accountID) {
+        return ErrCharacterNotFound
+    }
+    if err != nil {
+        return err
+    }
+    
+    if err := database.SetArmoryHidden(realmID, guid, accountID, hidden); err != nil {
+        return err
+    }
+    cache.Invalidate(ctx, armoryKey(realmID, name))
+    return nil
+}
+
+func loadArmoryProfile(ctx context.Context, realmID int, name string) (*cachedArmoryProfile, error) {
+    character, err := database.GetCharacterByName(realmID, name)
+    if err == sql.ErrNoRows {
+        return nil, ErrCharacterNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    
+    hidden, err := database.IsArmoryHidden(realmID, character.GUID)
+    if err != nil {
+        return nil, err
+    }
+    if hidden {
+        return &cachedArmoryProfile{Hidden: true}, nil
+    }
+    
+    profile := &ArmoryProfile{
+        OnlinePlayer: NewOnlinePlayer(character.Character),
+        RealmID:      realmID,
+        Online:       character.Online,
+        TotalTime:    character.TotalTime,
+        TotalKills:   character.TotalKills,
+        GuildName:    character.GuildName,
+        UpdatedAt:    time.Now(),
+    }
+    
+    if profile.Items, profile.AverageItemLevel, err = armoryItems(realmID, character.GUID); err != nil {
+        return nil, err
+    }
+    if profile.Talents, err = armoryTalents(realmID, character.GUID); err != nil {
+        return nil, err
+    }
+    if profile.Professions, err = armoryProfessions(realmID, character.GUID); err != nil {
+        return nil, err
+    }
+    if profile.Reputation, err = armoryReputation(realmID, character.GUID); err != nil {
+        return nil, err
+    }
+    if profile.Achievements, profile.AchievementPoints, err = armoryAchievements(ctx, realmID, character.GUID); err != nil {
+        return nil, err
+    }
+    
+    return &cachedArmoryProfile{Profile: profile}, nil
+}
+
+func armoryItems(realmID, guid int) ([]ArmoryItem, int, error) {
+    equipped, err := database.GetEquippedItems(realmID, guid)
+    if err != nil {
+        return nil, 0, err
+    }
+    
+    entries := make([]int, len(equipped))
+    for i, e := range equipped {
+        entries[i] = e.Entry
+    }
+    
+    // Без world базы показываем предметы только по entry
+    templates, err := database.GetItemTemplates(entries)
+    if err != nil {
+        return nil, 0, err
+    }
+    
+    items := make([]ArmoryItem, 0, len(equipped))
+    var levels, counted int
+    for _, e := range equipped {
+        item := ArmoryItem{
+            Slot:     e.Slot,
+            SlotName: gamedata.SlotName(e.Slot),
+            Entry:    e.Entry,
+            Name:     fmt.Sprintf("Item #%d", e.Entry),
+        }
+        if t, ok := templates[e.Entry]; ok {
+            item.Name = t.Name
+            item.Quality = t.Quality
+            item.ItemLevel = t.ItemLevel
+            
+            // Рубашка и гербовая накидка в средний уровень предметов не входят
+            if e.Slot != 3 && e.Slot != 18 {
+                levels += t.ItemLevel
+                counted++
+            }
+        }
+        item.QualityColor = gamedata.QualityColor(item.Quality)
+        items = append(items, item)
+    }
+    
+    average := 0
+    if counted > 0 {
+        average = levels / counted
+    }
+    return items, average, nil
+}
+
+func armoryTalents(realmID, guid int) ([]ArmoryTalentSpec, error) {
+    talents, err := database.GetCharacterTalents(realmID, guid)
+    if err != nil {
+        return nil, err
+    }
+    
+    // specMask: бит 0 — основная специализация, бит 1 — вторая
+    specs := []ArmoryTalentSpec{{Spec: 0}, {Spec: 1}}
+    for _, t := range talents {
+        for i := range specs {
+            if t.SpecMask&(1<<i) != 0 {
+                specs[i].Spells = append(specs[i].Spells, t.Spell)
+            }
+        }
+    }
+    
+    result := make([]ArmoryTalentSpec, 0, len(specs))
+    for _, s := range specs {
+        if len(s.Spells) > 0 {
+            result = append(result, s)
+        }
+    }
+    return result, nil
+}
+
+func armoryProfessions(realmID, guid int) ([]ArmoryProfession, error) {
+    skills, err := database.GetCharacterSkills(realmID, guid, gamedata.ProfessionIDs())
+    if err != nil {
+        return nil, err
+    }
+    
+    professions := make([]ArmoryProfession, 0, len(skills))
+    for _, s := range skills {
+        p, _ := gamedata.GetProfession(s.Skill)
+        professions = append(professions, ArmoryProfession{
+            ID:        s.Skill,
+            Name:      p.Name,
+            Secondary: p.Secondary,
+            Value:     s.Value,
+            Max:       s.Max,
+        })
+    }
+    
+    // Основные профессии первыми
+    sort.SliceStable(professions, func(i, j int) bool {
+        return !professions[i].Secondary && professions[j].Secondary
+    })
+    return professions, nil
+}
+
+func armoryReputation(realmID, guid int) ([]ArmoryReputation, error) {
+    reps, err := database.GetCharacterReputation(realmID, guid)
+    if err != nil {
+        return nil, err
+    }
+    
+    result := make([]ArmoryReputation, 0, len(reps))
+    for _, r := range reps {
+        rank, value, max := gamedata.ReputationStanding(r.Standing)
+        result = append(result, ArmoryReputation{
+            Faction:   r.Faction,
+            Name:      gamedata.FactionName(r.Faction),
+            Standing:  r.Standing,
+            Rank:      rank.Name,
+            RankColor: rank.Color,
+            Value:     value,
+            Max:       max,
+        })
+    }
+    return result, nil
+}
+
+func armoryAchievements(ctx context.Context, realmID, guid int) (int, int, error) {
+    ids, err := database.GetCharacterAchievements(realmID, guid)
+    if err != nil {
+        return 0, 0, err
+    }
+    
+    points, err := achievementPoints(ctx)
+    if err != nil {
+        return 0, 0, err
+    }
+    
+    total := 0
+    for _, id := range ids {
+        total += points[id]
+    }
+    return len(ids), total, nil
+}
+
+// achievementPoints — справочник очков общий для всех персонажей и меняется только при импорте
+func achievementPoints(ctx context.Context) (map[int]int, error) {
+    return cache.GetOrLoad(ctx, "achievement_points", time.Hour, func(ctx context.Context) (map[int]int, error) {
+        return database.GetAchievementPoints()
+    })
+}
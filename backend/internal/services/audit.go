@@ -0,0 +1,314 @@
+package services
+
+import (
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "io"
+    "log"
+    "reflect"
+    "strconv"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+// События журнала аудита: действия персонала и события безопасности игроков
+const (
+    AuditAccountCreate        = "account.create"
+    AuditAccountBan           = "account.ban"
+    AuditAccountUnban         = "account.unban"
+    AuditAccountLock          = "account.lock"
+    AuditAccountUnlock        = "account.unlock"
+    AuditAccountExpansion     = "account.expansion"
+    AuditAccountTwoFactor     = "account.2fa_reset"
+    AuditAccountPasswordReset = "account.password_reset"
+    AuditAccountGMLevel       = "account.gm_level"
+    AuditAccountNote          = "account.note"
+    AuditPasswordChange       = "account.password_change"
+    AuditPasswordResetDone    = "account.password_reset_done"
+    AuditEmailChange          = "account.email_change"
+    AuditLogin                = "auth.login"
+    AuditLoginFailed          = "auth.login_failed"
+    AuditIPBan                = "ip.ban"
+    AuditIPUnban              = "ip.unban"
+    AuditCharacterBan         = "character.ban"
+    AuditCharacterUnban       = "character.unban"
+    AuditPointsAdjust         = "points.adjust"
+    AuditShopProduct          = "shop.product"
+    AuditWebhookSave          = "webhook.save"
+    AuditWebhookDelete        = "webhook.delete"
+    AuditWebhookSecret        = "webhook.secret"
+    AuditWebhookRedeliver     = "webhook.redeliver"
+    AuditLogExport            = "audit.export"
+)
+
+// AuditActions — все события, для подсказок в фильтре панели
+var AuditActions = []string{
+    AuditAccountCreate, AuditAccountBan, AuditAccountUnban, AuditAccountLock, AuditAccountUnlock,
+    AuditAccountExpansion, AuditAccountTwoFactor, AuditAccountPasswordReset, AuditAccountGMLevel,
+    AuditAccountNote, AuditPasswordChange, AuditPasswordResetDone, AuditEmailChange,
+    AuditLogin, AuditLoginFailed, AuditIPBan, AuditIPUnban, AuditCharacterBan, AuditCharacterUnban,
+    AuditPointsAdjust, AuditShopProduct, AuditWebhookSave, AuditWebhookDelete, AuditWebhookSecret,
+    AuditWebhookRedeliver, AuditLogExport,
+}
+
+const (
+    auditRecent         = 50
+    auditPageSize       = 100
+    auditRetentionEvery = 24 * time.Hour
+)
+
+// Свертка неудачных входов: окно скользящее, оно продлевается каждой попыткой
+const (
+    loginFailureWindow = 15 * time.Minute
+    loginFailuresPerIP = 20
+)
+
+var ErrUnknownExportFormat = errors.New("unknown export format, use csv or jsonl")
+
+// AuditEvent — что записать в журнал. Before и After — состояние объекта до
+// и после действия (структуры или map); в журнал попадают только
+// изменившиеся поля. Target — объект без аккаунта: IP, персонаж, вебхук
+type AuditEvent struct {
+    Action          string
+    TargetAccountID int
+    Target          string
+    Details         map[string]interface{}
+    Before          interface{}
+    After           interface{}
+}
+
+// Audit записывает событие без изменений «до/после»
+func Audit(actor AdminActor, action string, targetAccountID int, details map[string]interface{}) {
+    RecordAudit(actor, AuditEvent{Action: action, TargetAccountID: targetAccountID, Details: details})
+}
+
+// AuditLoginFailure записывает неудачный вход. Каждая запись берет общую
+// блокировку цепочки хешей, поэтому перебор паролей сворачивается: по паре IP и
+// логина пишутся 1-я, 10-я, 100-я… попытки с их числом, а после
+// loginFailuresPerIP неудач с одного IP — только такие же сводки по IP
+func AuditLoginFailure(ctx context.Context, actor AdminActor, username string, accountID int, reason string) {
+    pairKey := "audit:login_failed:" + actor.IP + ":" + username
+    ipKey := "audit:login_failed:" + actor.IP
+    
+    pipe := database.Redis.TxPipeline()
+    pairAttempts := pipe.Incr(ctx, pairKey)
+    pipe.Expire(ctx, pairKey, loginFailureWindow)
+    ipAttempts := pipe.Incr(ctx, ipKey)
+    pipe.Expire(ctx, ipKey, loginFailureWindow)
+    if _, err := pipe.Exec(ctx); err != nil {
+        // Без счетчиков пишем как есть: потерять событие хуже, чем лишняя запись
+        log.Printf("audit: login failure counters: %v", err)
+        pairAttempts.SetVal(1)
+        ipAttempts.SetVal(1)
+    }
+    
+    details := map[string]interface{}{"reason": reason}
+    switch {
+    case ipAttempts.Val() <= loginFailuresPerIP && auditMilestone(pairAttempts.Val()):
+        if pairAttempts.Val() > 1 {
+            details["attempts"] = pairAttempts.Val()
+        }
+    case ipAttempts.Val() > loginFailuresPerIP && auditMilestone(ipAttempts.Val()):
+        details["ip_attempts"] = ipAttempts.Val()
+    default:
+        return
+    }
+    
+    RecordAudit(actor, AuditEvent{
+        Action:          AuditLoginFailed,
+        TargetAccountID: accountID,
+        Target:          username,
+        Details:         details,
+    })
+}
+
+// auditMilestone — 1, 10, 100, 1000…
+func auditMilestone(n int64) bool {
+    for n >= 10 && n%10 == 0 {
+        n /= 10
+    }
+    return n == 1
+}
+
+// RecordAudit дописывает событие в журнал. Сбой записи не отменяет само
+// действие, поэтому ошибка только логируется
+func RecordAudit(actor AdminActor, event AuditEvent) {
+    details := []byte("{}")
+    if event.Details != nil {
+        var err error
+        if details, err = json.Marshal(event.Details); err != nil {
+            log.Printf("audit: %s: %v", event.Action, err)
+        }
+    }
+    changes, err := auditChanges(event.Before, event.After)
+    if err != nil {
+        log.Printf("audit: %s: %v", event.Action, err)
+    }
+    
+    entry := &database.AuditEntry{
+        ActorID:         actor.AccountID,
+        ActorName:       database.TruncateText(actor.Name, 64),
+        Action:          event.Action,
+        TargetAccountID: event.TargetAccountID,
+        Target:          database.TruncateText(event.Target, 128),
+        Details:         string(details),
+        Changes:         changes,
+        IP:              actor.IP,
+        UserAgent:       database.TruncateText(actor.UserAgent, 255),
+        RequestID:       database.TruncateText(actor.RequestID, 64),
+    }
+    if err := database.AddAuditEntry(entry); err != nil {
+        log.Printf("audit: %s by %s: %v", event.Action, actor.Name, err)
+    }
+}
+
+// auditChanges сравнивает JSON-представления before и after по полям верхнего
+// уровня: {"field": {"before": …, "after": …}}. nil — объекта не было
+// (создание) или не стало (удаление)
+func auditChanges(before, after interface{}) (string, error) {
+    if before == nil && after == nil {
+        return "", nil
+    }
+    from, err := auditFields(before)
+    if err != nil {
+        return "", err
+    }
+    to, err := auditFields(after)
+    if err != nil {
+        return "", err
+    }
+    
+    changes := map[string]map[string]interface{}{}
+    for field, value := range to {
+        if old, ok := from[field]; !ok || !reflect.DeepEqual(old, value) {
+            changes[field] = map[string]interface{}{"before": from[field], "after": value}
+        }
+    }
+    for field, value := range from {
+        if _, ok := to[field]; !ok {
+            changes[field] = map[string]interface{}{"before": value, "after": nil}
+        }
+    }
+    if len(changes) == 0 {
+        return "", nil
+    }
+    
+    data, err := json.Marshal(changes)
+    return string(data), err
+}
+
+func auditFields(v interface{}) (map[string]interface{}, error) {
+    fields := map[string]interface{}{}
+    if v == nil {
+        return fields, nil
+    }
+    data, err := json.Marshal(v)
+    if err != nil {
+        return nil, err
+    }
+    return fields, json.Unmarshal(data, &fields)
+}
+
+// RecentAudit — последние записи журнала; accountID 0 — по всем аккаунтам
+func RecentAudit(accountID int) ([]database.AuditEntry, error) {
+    return database.GetAuditEntries(accountID, auditRecent)
+}
+
+// AuditSearchResult — страница журнала для панели
+type AuditSearchResult struct {
+    Entries []database.AuditEntry `json:"entries"`
+    Total   int                   `json:"total"`
+    Page    int                   `json:"page"`
+    Pages   int                   `json:"pages"`
+}
+
+func SearchAudit(filter database.AuditFilter, page int) (*AuditSearchResult, error) {
+    if page < 1 {
+        page = 1
+    }
+    filter.Limit = auditPageSize
+    filter.Offset = (page - 1) * auditPageSize
+    
+    entries, total, err := database.SearchAuditEntries(filter)
+    if err != nil {
+        return nil, err
+    }
+    return &AuditSearchResult{
+        Entries: entries,
+        Total:   total,
+        Page:    page,
+        Pages:   (total + auditPageSize - 1) / auditPageSize,
+    }, nil
+}
+
+// VerifyAudit проверяет цепочку хешей журнала
+func VerifyAudit() (*database.AuditChainStatus, error) {
+    return database.VerifyAuditChain()
+}
+
+var auditCSVHeader = []string{
+    "id", "created_at", "actor_id", "actor_name", "action", "target_account_id", "target",
+    "details", "changes", "ip", "user_agent", "request_id", "prev_hash", "hash",
+}
+
+// ExportAudit выгружает записи по фильтру в csv или jsonl (по объекту JSON
+// на строку). Хеши выгружаются тоже: по ним цепочку можно проверить вне сайта
+func ExportAudit(w io.Writer, format string, filter database.AuditFilter) error {
+    switch format {
+    case "csv":
+        out := csv.NewWriter(w)
+        if err := out.Write(auditCSVHeader); err != nil {
+            return err
+        }
+        err := database.EachAuditEntry(filter, func(e *database.AuditEntry) error {
+            return out.Write([]string{
+                strconv.FormatInt(e.ID, 10), e.CreatedAt.UTC().Format(time.RFC3339),
+                strconv.Itoa(e.ActorID), e.ActorName, e.Action, strconv.Itoa(e.TargetAccountID), e.Target,
+                e.Details, e.Changes, e.IP, e.UserAgent, e.RequestID, e.PrevHash, e.Hash,
+            })
+        })
+        if err != nil {
+            return err
+        }
+        out.Flush()
+        return out.Error()
+    case "jsonl":
+        encoder := json.NewEncoder(w)
+        return database.EachAuditEntry(filter, func(e *database.AuditEntry) error {
+            e.CreatedAt = e.CreatedAt.UTC()
+            return encoder.Encode(e)
+        })
+    default:
+        return ErrUnknownExportFormat
+    }
+}
+
+// StartAuditRetention раз в сутки удаляет записи старше AUDIT_RETENTION_DAYS;
+// 0 — хранить вечно
+func StartAuditRetention(ctx context.Context) {
+    days := config.AppConfig.Logging.AuditRetentionDays
+    if days <= 0 {
+        return
+    }
+    
+    ticker := time.NewTicker(auditRetentionEvery)
+    defer ticker.Stop()
+    
+    for {
+        n, err := database.PruneAuditEntries(time.Now().AddDate(0, 0, -days))
+        if err != nil {
+            log.Printf("audit: retention: %v", err)
+        } else if n > 0 {
+            log.Printf("audit: removed %d entries older than %d days", n, days)
+        }
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
@@ -3,13 +3,14 @@ package services
 import (
     "crypto/rand"
     "crypto/sha1"
+    "crypto/subtle"
     "encoding/hex"
     "fmt"
     "math/big"
-    "net"
     "strings"
     "time"
     "wow-registration/internal/config"
+    "wow-registration/internal/database"
     "github.com/google/uuid"
 )
 
@@ -25,6 +26,37 @@ func GenerateSRP6(username, password string, coreType int) (*SRP6Verifier, error
         return nil, err
     }
     
+    verifierBytes := srp6Verifier(username, password, salt, coreType)
+    
+    if coreType == 5 { // CMangos
+        return &SRP6Verifier{
+            Salt:     strings.ToUpper(hex.EncodeToString(salt)),
+            Verifier: strings.ToUpper(hex.EncodeToString(verifierBytes)),
+        }, nil
+    }
+    
+    return &SRP6Verifier{
+        Salt:     hex.EncodeToString(salt),
+        Verifier: hex.EncodeToString(verifierBytes),
+    }, nil
+}
+
+// VerifySRP6 пересчитывает verifier с сохраненной солью и сравнивает с записанным
+func VerifySRP6(username, password, saltHex, verifierHex string, coreType int) bool {
+    salt, err := hex.DecodeString(saltHex)
+    if err != nil || len(salt) == 0 {
+        return false
+    }
+    
+    expected, err := hex.DecodeString(verifierHex)
+    if err != nil {
+        return false
+    }
+    
+    return subtle.ConstantTimeCompare(srp6Verifier(username, password, salt, coreType), expected) == 1
+}
+
+func srp6Verifier(username, password string, salt []byte, coreType int) []byte {
     // Константы для SRP6
     g := big.NewInt(7)
     N := new(big.Int)
@@ -42,11 +74,11 @@ func GenerateSRP6(username, password string, coreType int) (*SRP6Verifier, error
         }
         h2Input = append(revSalt, h1[:]...)
     } else { // TrinityCore
-        h2Input = append(salt, h1[:]...)
+        h2Input = append(append([]byte{}, salt...), h1[:]...)
     }
     
     h2 := sha1.Sum(h2Input)
-    h2Int := new(big.Int).SetBytes(h2)
+    h2Int := new(big.Int).SetBytes(h2[:])
     
     verifier := new(big.Int).Exp(g, h2Int, N)
     verifierBytes := verifier.Bytes()
@@ -64,16 +96,21 @@ func GenerateSRP6(username, password string, coreType int) (*SRP6Verifier, error
             revVerifier[len(verifierBytes)-1-i] = b
         }
         verifierBytes = revVerifier
-        return &SRP6Verifier{
-            Salt:     strings.ToUpper(hex.EncodeToString(salt)),
-            Verifier: strings.ToUpper(hex.EncodeToString(verifierBytes)),
-        }, nil
     }
     
-    return &SRP6Verifier{
-        Salt:     hex.EncodeToString(salt),
-        Verifier: hex.EncodeToString(verifierBytes),
-    }, nil
+    return verifierBytes
+}
+
+// CheckPassword проверяет пароль по sha_pass_hash, а если его нет — по SRP6
+func CheckPassword(account *database.Account, password string) bool {
+    if account.Password != "" {
+        hash := GenerateSHA1Hash(account.Username, password)
+        return subtle.ConstantTimeCompare([]byte(hash), []byte(strings.ToUpper(account.Password))) == 1
+    }
+    if account.Salt != "" && account.Verifier != "" {
+        return VerifySRP6(account.Username, password, account.Salt, account.Verifier, config.AppConfig.Game.ServerCore)
+    }
+    return false
 }
 
 func GenerateSHA1Hash(username, password string) string {
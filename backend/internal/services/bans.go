@@ -0,0 +1,317 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "log"
+    "net/netip"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+const (
+    banListLimit   = 200
+    maxIPRangeSize = 256 // не больше /24 за раз
+)
+
+var (
+    ErrInvalidIPRange     = errors.New("invalid IP or range, use 1.2.3.4, 1.2.3.0/24 or 1.2.3.10-1.2.3.50")
+    ErrIPRangeTooLarge    = errors.New("the range is too large, at most 256 addresses at once")
+    ErrOwnIP              = errors.New("the range includes your own IP")
+    ErrIPNotBanned        = errors.New("IP is not banned")
+    ErrCharacterNotBanned = errors.New("character is not banned")
+)
+
+// BanPreset — готовая длительность для форм панели
+type BanPreset struct {
+    Value string
+    Label string
+}
+
+var BanPresets = []BanPreset{
+    {"1h", "1 hour"},
+    {"1d", "1 day"},
+    {"3d", "3 days"},
+    {"7d", "7 days"},
+    {"30d", "30 days"},
+    {"perm", "Permanent"},
+}
+
+// BannedError — отказ во входе или регистрации с причиной и сроком бана
+type BannedError struct {
+    Subject string // "account" или "IP"
+    Reason  string
+    Until   *time.Time
+}
+
+func (e *BannedError) Error() string {
+    until := "permanently"
+    if e.Until != nil {
+        until = "until " + e.Until.UTC().Format("2006-01-02 15:04") + " UTC"
+    }
+    message := "This " + e.Subject + " is banned " + until
+    if e.Reason != "" {
+        message += ". Reason: " + e.Reason
+    }
+    return message
+}
+
+// CheckIPBan возвращает *BannedError, если адрес забанен
+func CheckIPBan(ip string) error {
+    ban, err := database.GetActiveIPBan(ip)
+    if err != nil || ban == nil {
+        return err
+    }
+    return &BannedError{Subject: "IP", Reason: ban.Reason, Until: ban.Until}
+}
+
+// CheckAccountBan возвращает *BannedError, если аккаунт забанен
+func CheckAccountBan(accountID int) error {
+    ban, err := database.GetActiveBan(accountID)
+    if err != nil || ban == nil {
+        return err
+    }
+    return &BannedError{Subject: "account", Reason: ban.Reason, Until: ban.Until}
+}
+
+// banReason обрезает причину под колонку banreason; пустая не принимается
+func banReason(reason string) (string, error) {
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return "", ErrReasonRequired
+    }
+    return database.TruncateText(reason, 255), nil
+}
+
+// ParseIPRange разворачивает адрес, CIDR или диапазон "from-to" в список
+// IPv4-адресов: ip_banned ядер хранит только точные адреса
+func ParseIPRange(spec string) ([]string, error) {
+    spec = strings.TrimSpace(spec)
+    
+    var first, last netip.Addr
+    switch {
+    case strings.Contains(spec, "/"):
+        prefix, err := netip.ParsePrefix(spec)
+        if err != nil || !prefix.Addr().Is4() {
+            return nil, ErrInvalidIPRange
+        }
+        if prefix.Bits() < 24 {
+            return nil, ErrIPRangeTooLarge
+        }
+        prefix = prefix.Masked()
+        first = prefix.Addr()
+        last = first
+        for next := last.Next(); prefix.Contains(next); next = next.Next() {
+            last = next
+        }
+    case strings.Contains(spec, "-"):
+        from, to, _ := strings.Cut(spec, "-")
+        var err error
+        if first, err = netip.ParseAddr(strings.TrimSpace(from)); err != nil {
+            return nil, ErrInvalidIPRange
+        }
+        if last, err = netip.ParseAddr(strings.TrimSpace(to)); err != nil {
+            return nil, ErrInvalidIPRange
+        }
+    default:
+        addr, err := netip.ParseAddr(spec)
+        if err != nil {
+            return nil, ErrInvalidIPRange
+        }
+        first, last = addr, addr
+    }
+    if !first.Is4() || !last.Is4() || last.Less(first) {
+        return nil, ErrInvalidIPRange
+    }
+    
+    var ips []string
+    for addr := first; ; addr = addr.Next() {
+        if len(ips) == maxIPRangeSize {
+            return nil, ErrIPRangeTooLarge
+        }
+        ips = append(ips, addr.String())
+        if addr == last {
+            break
+        }
+    }
+    return ips, nil
+}
+
+// BanIPRange банит все адреса диапазона. Свой адрес забанить нельзя, чтобы
+// не отрезать себе доступ к панели
+func BanIPRange(actor AdminActor, spec string, duration time.Duration, reason string) ([]string, error) {
+    reason, err := banReason(reason)
+    if err != nil {
+        return nil, err
+    }
+    ips, err := ParseIPRange(spec)
+    if err != nil {
+        return nil, err
+    }
+    for _, ip := range ips {
+        if ip == actor.IP {
+            return nil, ErrOwnIP
+        }
+    }
+    
+    if err := database.BanIPs(ips, duration, actor.Name, reason); err != nil {
+        return nil, err
+    }
+    log.Printf("admin: %s banned %s (%d addresses) for %s (%s)", actor.Name, spec, len(ips), banDurationText(duration), reason)
+    
+    RecordAudit(actor, AuditEvent{
+        Action: AuditIPBan,
+        Target: strings.TrimSpace(spec),
+        Details: map[string]interface{}{
+            "addresses": len(ips),
+            "duration":  banDurationText(duration),
+            "reason":    reason,
+        },
+    })
+    return ips, nil
+}
+
+// UnbanIP снимает бан одного адреса
+func UnbanIP(actor AdminActor, ip string) error {
+    addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+    if err != nil {
+        return ErrInvalidIPRange
+    }
+    
+    lifted, err := database.UnbanIP(addr.String())
+    if err != nil {
+        return err
+    }
+    if !lifted {
+        return ErrIPNotBanned
+    }
+    
+    log.Printf("admin: %s unbanned %s", actor.Name, addr)
+    RecordAudit(actor, AuditEvent{Action: AuditIPUnban, Target: addr.String()})
+    return nil
+}
+
+// characterTarget — персонаж в журнале аудита, как в адресе армори: "<realm>/<name>"
+func characterTarget(realmID int, name string) string {
+    return strconv.Itoa(realmID) + "/" + name
+}
+
+func findCharacter(realmID int, name string) (*database.CharacterProfile, error) {
+    character, err := database.GetCharacterByName(realmID, strings.TrimSpace(name))
+    if err == sql.ErrNoRows {
+        return nil, ErrCharacterNotFound
+    }
+    return character, err
+}
+
+// BanCharacter банит персонажа и выкидывает его из игры. Персонажей
+// персонала банить нельзя, как и их аккаунты
+func BanCharacter(ctx context.Context, actor AdminActor, realmID int, name string, duration time.Duration, reason string) (*database.CharacterBan, error) {
+    reason, err := banReason(reason)
+    if err != nil {
+        return nil, err
+    }
+    character, err := findCharacter(realmID, name)
+    if err != nil {
+        return nil, err
+    }
+    if IsAdmin(character.Account) {
+        return nil, ErrProtectedAccount
+    }
+    
+    if err := database.BanCharacter(realmID, character.GUID, duration, actor.Name, reason); err != nil {
+        return nil, err
+    }
+    log.Printf("admin: %s banned character %s on realm %d for %s (%s)", actor.Name, character.Name, realmID, banDurationText(duration), reason)
+    
+    if character.Online {
+        if client, err := realmSOAP(realmID); err == nil {
+            if _, err := client.Execute(ctx, "kick "+character.Name); err != nil {
+                log.Printf("admin: kick %s on realm %d: %v", character.Name, realmID, err)
+            }
+        }
+    }
+    
+    ban := &database.CharacterBan{
+        RealmID:  realmID,
+        GUID:     character.GUID,
+        Name:     character.Name,
+        BannedAt: time.Now(),
+        BannedBy: actor.Name,
+        Reason:   reason,
+    }
+    if duration > 0 {
+        until := ban.BannedAt.Add(duration)
+        ban.Until = &until
+    }
+    
+    RecordAudit(actor, AuditEvent{
+        Action:          AuditCharacterBan,
+        TargetAccountID: character.Account,
+        Target:          characterTarget(realmID, character.Name),
+        Details: map[string]interface{}{
+            "duration": banDurationText(duration),
+            "reason":   reason,
+        },
+    })
+    return ban, nil
+}
+
+// UnbanCharacter снимает действующий бан персонажа
+func UnbanCharacter(actor AdminActor, realmID int, name string) error {
+    character, err := findCharacter(realmID, name)
+    if err != nil {
+        return err
+    }
+    
+    lifted, err := database.UnbanCharacter(realmID, character.GUID)
+    if err != nil {
+        return err
+    }
+    if !lifted {
+        return ErrCharacterNotBanned
+    }
+    
+    log.Printf("admin: %s unbanned character %s on realm %d", actor.Name, character.Name, realmID)
+    RecordAudit(actor, AuditEvent{
+        Action:          AuditCharacterUnban,
+        TargetAccountID: character.Account,
+        Target:          characterTarget(realmID, character.Name),
+    })
+    return nil
+}
+
+// BanList — действующие баны всех видов для панели
+type BanList struct {
+    Accounts   []database.AccountBan   `json:"accounts"`
+    IPs        []database.IPBan        `json:"ips"`
+    Characters []database.CharacterBan `json:"characters"`
+}
+
+// GetBanList собирает действующие баны; недоступный реалм пропускается
+func GetBanList() (*BanList, error) {
+    list := &BanList{}
+    var err error
+    if list.Accounts, err = database.GetActiveAccountBans(banListLimit); err != nil {
+        return nil, err
+    }
+    if list.IPs, err = database.GetActiveIPBans(banListLimit); err != nil {
+        return nil, err
+    }
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return list, nil
+    }
+    for _, realm := range database.GetRealms() {
+        bans, err := database.GetActiveCharacterBans(realm.ID, banListLimit)
+        if err != nil {
+            log.Printf("admin: realm %d character bans: %v", realm.ID, err)
+            continue
+        }
+        list.Characters = append(list.Characters, bans...)
+    }
+    return list, nil
+}
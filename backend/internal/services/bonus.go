@@ -0,0 +1,307 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+const (
+    bonusInterval  = time.Minute
+    bonusIdleDelay = time.Hour
+    bonusBatchSize = 100
+)
+
+type bonusContents struct {
+    Money  int
+    Items  []database.ItemStack
+    Spells []int
+}
+
+// ParseItemList разбирает "itemId:count,…"; количество можно опустить, тогда 1
+func ParseItemList(raw string) ([]database.ItemStack, error) {
+    var items []database.ItemStack
+    for _, part := range strings.Split(raw, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        
+        entryRaw, countRaw, hasCount := strings.Cut(part, ":")
+        entry, err := strconv.Atoi(strings.TrimSpace(entryRaw))
+        if err != nil || entry <= 0 {
+            return nil, fmt.Errorf("invalid item id in %q", part)
+        }
+        count := 1
+        if hasCount {
+            if count, err = strconv.Atoi(strings.TrimSpace(countRaw)); err != nil || count <= 0 {
+                return nil, fmt.Errorf("invalid item count in %q", part)
+            }
+        }
+        items = append(items, database.ItemStack{Entry: entry, Count: count})
+    }
+    return items, nil
+}
+
+// ValidateItems проверяет, что предметы есть в world.item_template и пачки
+// не больше stackable — иначе письмо придет пустым или не придет вовсе
+func ValidateItems(items []database.ItemStack) error {
+    entries := make([]int, len(items))
+    for i, item := range items {
+        entries[i] = item.Entry
+    }
+    templates, err := database.GetItemTemplates(entries)
+    if err != nil {
+        return err
+    }
+    
+    for _, item := range items {
+        template, ok := templates[item.Entry]
+        if !ok {
+            return fmt.Errorf("item %d does not exist in the world database", item.Entry)
+        }
+        if template.Stackable > 0 && item.Count > template.Stackable {
+            return fmt.Errorf("item %d stacks up to %d, got %d", item.Entry, template.Stackable, item.Count)
+        }
+    }
+    return nil
+}
+
+func loadBonusContents() (*bonusContents, error) {
+    cfg := config.AppConfig.Custom
+    
+    items, err := ParseItemList(cfg.StartItems)
+    if err != nil {
+        return nil, fmt.Errorf("START_ITEMS: %w", err)
+    }
+    if err := ValidateItems(items); err != nil {
+        return nil, fmt.Errorf("START_ITEMS: %w", err)
+    }
+    
+    var spells []int
+    for _, part := range strings.Split(cfg.StartSpells, ",") {
+        if part = strings.TrimSpace(part); part == "" {
+            continue
+        }
+        spell, err := strconv.Atoi(part)
+        if err != nil || spell <= 0 {
+            return nil, fmt.Errorf("START_SPELLS: invalid spell id %q", part)
+        }
+        spells = append(spells, spell)
+    }
+    
+    return &bonusContents{Money: cfg.StartGold * 10000, Items: items, Spells: spells}, nil
+}
+
+// QueueRegistrationBonus ставит новому аккаунту бонус в очередь; выдается он
+// на первого созданного персонажа
+func QueueRegistrationBonus(accountID int) error {
+    if !config.AppConfig.Custom.RegistrationBonusEnabled {
+        return nil
+    }
+    return database.CreateRegistrationBonus(accountID)
+}
+
+// StartBonusDelivery раз в минуту ищет первых персонажей новых аккаунтов и
+// доставляет им стартовый бонус
+func StartBonusDelivery(ctx context.Context) {
+    if !config.AppConfig.Custom.RegistrationBonusEnabled {
+        return
+    }
+    contents, err := loadBonusContents()
+    if err != nil {
+        log.Printf("registration bonus disabled: %v", err)
+        return
+    }
+    
+    ticker := time.NewTicker(bonusInterval)
+    defer ticker.Stop()
+    
+    for {
+        deliverBonuses(ctx, contents)
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func deliverBonuses(ctx context.Context, contents *bonusContents) {
+    bonuses, err := database.GetOpenBonuses(bonusBatchSize)
+    if err != nil {
+        log.Printf("registration bonus: %v", err)
+        return
+    }
+    
+    // Взятые строки уходят в конец очереди: аккаунты без персонажей не должны
+    // навсегда занять всю пачку. Откладываются на полинтервала, чтобы к
+    // следующему тику снова попасть в выборку
+    var waiting, taken []int
+    for _, b := range bonuses {
+        taken = append(taken, b.AccountID)
+        if b.Status == database.BonusWaiting {
+            waiting = append(waiting, b.AccountID)
+        }
+    }
+    if err := database.PostponeBonuses(taken, bonusInterval/2, bonusIdleDelay); err != nil {
+        log.Printf("registration bonus: %v", err)
+        return
+    }
+    
+    // Персонажа ищем по реалмам в порядке из realmlist: времени создания в
+    // characters нет, а GUID разных реалмов не сравнить. Проверка идет каждую
+    // минуту, так что обычно находится единственный персонаж
+    assigned := make(map[int]database.Character)
+    for _, realm := range database.GetRealms() {
+        first, err := database.FindFirstCharacters(realm.ID, waiting)
+        if err != nil {
+            log.Printf("registration bonus: realm %d: %v", realm.ID, err)
+            continue
+        }
+        for account, character := range first {
+            if _, ok := assigned[account]; !ok {
+                assigned[account] = character
+            }
+        }
+    }
+    
+    for _, b := range bonuses {
+        if b.Status == database.BonusWaiting {
+            character, ok := assigned[b.AccountID]
+            if !ok {
+                continue
+            }
+            if ok, err := database.AssignBonusCharacter(b.AccountID, character); err != nil || !ok {
+                continue
+            }
+            b.RealmID, b.GUID, b.CharacterName = character.RealmID, character.GUID, character.Name
+        }
+        
+        if err := deliverBonus(ctx, b, contents); err != nil {
+            log.Printf("registration bonus: account %d: %v", b.AccountID, err)
+            database.UpdateBonusStatus(b.AccountID, database.BonusDelivering, err.Error())
+        }
+    }
+}
+
+func deliverBonus(ctx context.Context, b database.RegistrationBonus, contents *bonusContents) error {
+    client, soapErr := realmSOAP(b.RealmID)
+    subject := "Welcome to " + strings.ReplaceAll(config.AppConfig.Game.ServerName, `"`, "")
+    text := "Thank you for joining us! Here is a little something to start your adventure."
+    
+    // Без SOAP письмо пишется прямо в базу, а это безопасно только при выключенном мире
+    realmDown := false
+    if status, err := getProbeStatus(ctx, strconv.Itoa(b.RealmID)); err == nil && status != nil {
+        realmDown = !status.Up
+    }
+    
+    steps := []struct {
+        name  string
+        empty bool
+        send  func() error
+        ready func() (bool, error)
+    }{
+        {
+            name:  "money",
+            empty: contents.Money <= 0,
+            ready: func() (bool, error) { return soapErr == nil || realmDown, nil },
+            send: func() error {
+                if soapErr == nil {
+                    _, err := client.Execute(ctx, fmt.Sprintf(`send money %s "%s" "%s" %d`, b.CharacterName, subject, text, contents.Money))
+                    return err
+                }
+                return database.SendMailSQL(b.RealmID, b.GUID, subject, text, contents.Money, nil)
+            },
+        },
+        {
+            name:  "items",
+            empty: len(contents.Items) == 0,
+            ready: func() (bool, error) { return soapErr == nil || realmDown, nil },
+            send: func() error {
+                // Письма, дошедшие до прошлого сбоя, второй раз не отправляются
+                for start := b.ItemsSent; start < len(contents.Items); start += database.MailMaxItems {
+                    end := min(start+database.MailMaxItems, len(contents.Items))
+                    chunk := contents.Items[start:end]
+                    var err error
+                    if soapErr == nil {
+                        _, err = client.Execute(ctx, fmt.Sprintf(`send items %s "%s" "%s" %s`, b.CharacterName, subject, text, formatItemList(chunk)))
+                    } else {
+                        err = database.SendMailSQL(b.RealmID, b.GUID, subject, text, 0, chunk)
+                    }
+                    if err != nil {
+                        return err
+                    }
+                    if err := database.SetBonusItemsSent(b.AccountID, end); err != nil {
+                        return err
+                    }
+                }
+                return nil
+            },
+        },
+        {
+            name:  "spells",
+            empty: len(contents.Spells) == 0,
+            ready: func() (bool, error) {
+                online, err := database.IsCharacterOnline(b.RealmID, b.GUID)
+                return !online, err
+            },
+            send: func() error { return database.LearnSpells(b.RealmID, b.GUID, contents.Spells) },
+        },
+    }
+    
+    done := true
+    for _, step := range steps {
+        if b.Steps[step.name] != database.BonusStepPending {
+            continue
+        }
+        if step.empty {
+            database.SetBonusStep(b.AccountID, step.name, database.BonusStepPending, database.BonusStepSkipped)
+            continue
+        }
+        
+        ready, err := step.ready()
+        if err != nil {
+            return err
+        }
+        if !ready {
+            done = false
+            continue
+        }
+        
+        // Часть помечается отправленной до отправки: при падении посередине
+        // игрок скорее недополучит бонус, чем получит его дважды
+        claimed, err := database.SetBonusStep(b.AccountID, step.name, database.BonusStepPending, database.BonusStepSent)
+        if err != nil {
+            return err
+        }
+        if !claimed {
+            continue
+        }
+        if err := step.send(); err != nil {
+            if deliveryNotAttempted(err) {
+                database.SetBonusStep(b.AccountID, step.name, database.BonusStepSent, database.BonusStepPending)
+            }
+            return fmt.Errorf("%s: %w", step.name, err)
+        }
+    }
+    
+    if !done {
+        return nil
+    }
+    return database.UpdateBonusStatus(b.AccountID, database.BonusDone, "")
+}
+
+func formatItemList(items []database.ItemStack) string {
+    parts := make([]string, len(items))
+    for i, item := range items {
+        parts[i] = fmt.Sprintf("%d:%d", item.Entry, item.Count)
+    }
+    return strings.Join(parts, " ")
+}
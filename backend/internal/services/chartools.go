@@ -0,0 +1,235 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+    "wow-registration/internal/soap"
+    "github.com/google/uuid"
+    "github.com/redis/go-redis/v9"
+)
+
+var (
+    ErrToolsDisabled    = errors.New("character tools are disabled")
+    ErrUnknownTool      = errors.New("unknown character action")
+    ErrNotYourCharacter = errors.New("this character does not belong to your account")
+    ErrCharacterOnline  = errors.New("log out of the game first")
+    ErrToolBusy         = errors.New("this action is already in progress")
+    ErrToolFailed       = errors.New("the game server could not complete the action, any points spent were refunded")
+    ErrToolReview       = errors.New("the game server did not confirm the action, an administrator will check it")
+)
+
+// CooldownError — действие уже выполнялось недавно
+type CooldownError struct {
+    Until time.Time
+}
+
+func (e *CooldownError) Error() string {
+    return "available again in " + strings.TrimSuffix(time.Until(e.Until).Round(time.Minute).String(), "0s")
+}
+
+// CharacterTool — действие над персонажем; command — GM-команда, куда подставляется имя
+type CharacterTool struct {
+    Slug         string        `json:"slug"`
+    Title        string        `json:"title"`
+    Description  string        `json:"description"`
+    OfflineOnly  bool          `json:"offline_only"`
+    MinExpansion int           `json:"-"`
+    Cost         int           `json:"cost"`
+    Cooldown     time.Duration `json:"-"`
+    CooldownSecs int           `json:"cooldown"`
+    command      string
+}
+
+var characterTools = []CharacterTool{
+    {Slug: "unstuck", Title: "Unstuck", Description: "Teleport to your hearthstone location", OfflineOnly: true, command: "tele name %s $home"},
+    {Slug: "revive", Title: "Revive", Description: "Resurrect a dead character", command: "revive %s"},
+    {Slug: "rename", Title: "Rename", Description: "Choose a new name at next login", command: "character rename %s"},
+    {Slug: "customize", Title: "Customize", Description: "Change appearance at next login", MinExpansion: gamedata.ExpansionWotLK, command: "character customize %s"},
+    {Slug: "faction", Title: "Faction Change", Description: "Change faction at next login", MinExpansion: gamedata.ExpansionWotLK, command: "character changefaction %s"},
+    {Slug: "race", Title: "Race Change", Description: "Change race at next login", MinExpansion: gamedata.ExpansionWotLK, command: "character changerace %s"},
+}
+
+// Клиент SOAP реалма; подменяется на soaptest в проверках
+var realmSOAP = soap.ForRealm
+
+// Удаляет блокировку, только если в ней все еще наш токен
+var releaseToolLock = redis.NewScript(`
+    if redis.call("GET", KEYS[1]) == ARGV[1] then
+        return redis.call("DEL", KEYS[1])
+    end
+    return 0
+`)
+
+const (
+    toolSweepInterval = 5 * time.Minute
+    // Действие, которое столько висит в pending, уже не завершится само:
+    // процесс упал между списанием и ответом worldserver
+    toolStaleAfter = 10 * time.Minute
+)
+
+// CharacterTools — доступные на этом сервере действия со стоимостью и кулдауном из конфига
+func CharacterTools() []CharacterTool {
+    costs := parseToolSettings(config.AppConfig.Custom.CharacterToolCosts)
+    cooldowns := parseToolSettings(config.AppConfig.Custom.CharacterToolCooldowns)
+    
+    var tools []CharacterTool
+    for _, tool := range characterTools {
+        if config.AppConfig.Game.Expansion < tool.MinExpansion {
+            continue
+        }
+        tool.Cost, _ = strconv.Atoi(costs[tool.Slug])
+        tool.Cooldown, _ = time.ParseDuration(cooldowns[tool.Slug])
+        tool.CooldownSecs = int(tool.Cooldown / time.Second)
+        tools = append(tools, tool)
+    }
+    return tools
+}
+
+func FindCharacterTool(slug string) (CharacterTool, error) {
+    for _, tool := range CharacterTools() {
+        if tool.Slug == slug {
+            return tool, nil
+        }
+    }
+    return CharacterTool{}, ErrUnknownTool
+}
+
+// "rename:100,faction:500" -> map
+func parseToolSettings(raw string) map[string]string {
+    settings := make(map[string]string)
+    for _, pair := range strings.Split(raw, ",") {
+        if key, value, ok := strings.Cut(strings.TrimSpace(pair), ":"); ok {
+            settings[strings.TrimSpace(key)] = strings.TrimSpace(value)
+        }
+    }
+    return settings
+}
+
+// RunCharacterTool проверяет владельца, онлайн и кулдаун, списывает поинты и
+// выполняет команду через SOAP. Поинты возвращаются, только если команда точно
+// не выполнялась; при таймауте и обрыве действие уходит на проверку
+func RunCharacterTool(ctx context.Context, accountID, realmID, guid int, slug string) (string, error) {
+    if !config.AppConfig.Custom.CharacterToolsEnabled {
+        return "", ErrToolsDisabled
+    }
+    tool, err := FindCharacterTool(slug)
+    if err != nil {
+        return "", err
+    }
+    client, err := realmSOAP(realmID)
+    if err != nil {
+        return "", err
+    }
+    
+    owner, name, err := database.GetCharacterOwner(realmID, guid)
+    if err == sql.ErrNoRows || (err == nil && owner != accountID) {
+        return "", ErrNotYourCharacter
+    }
+    if err != nil {
+        return "", err
+    }
+    
+    if tool.OfflineOnly {
+        online, err := database.IsCharacterOnline(realmID, guid)
+        if err != nil {
+            return "", err
+        }
+        if online {
+            return "", ErrCharacterOnline
+        }
+    }
+    
+    // Одновременные клики не должны пройти проверку кулдауна дважды. Блокировка
+    // снимается только своим токеном: если команда шла дольше TTL, ключ уже
+    // может принадлежать другому запросу
+    lock := fmt.Sprintf("chartool:%d:%d:%s", realmID, guid, tool.Slug)
+    token := uuid.NewString()
+    ok, err := database.Redis.SetNX(ctx, lock, token, time.Minute).Result()
+    if err != nil {
+        return "", err
+    }
+    if !ok {
+        return "", ErrToolBusy
+    }
+    defer releaseToolLock.Run(context.Background(), database.Redis, []string{lock}, token)
+    
+    if tool.Cooldown > 0 {
+        remaining, err := database.CharacterActionCooldown(realmID, guid, tool.Slug, tool.Cooldown)
+        if err != nil {
+            return "", err
+        }
+        if remaining > 0 {
+            return "", &CooldownError{Until: time.Now().Add(remaining)}
+        }
+    }
+    
+    actionID, err := database.CreateCharacterAction(accountID, realmID, guid, tool.Slug, tool.Cost)
+    if err != nil {
+        return "", err
+    }
+    
+    result, err := client.Execute(ctx, fmt.Sprintf(tool.command, name))
+    if err != nil {
+        // Без ответа worldserver неизвестно, выполнена ли команда: возврат мог
+        // бы отдать действие бесплатно. Такие действия разбирает администратор
+        if !deliveryNotAttempted(err) {
+            log.Printf("chartools: action %d needs review: %v", actionID, err)
+            database.FinishCharacterAction(actionID, database.ActionReview, err.Error())
+            return "", fmt.Errorf("%w: %v", ErrToolReview, err)
+        }
+        if refundErr := database.RefundCharacterAction(actionID, err.Error()); refundErr != nil {
+            log.Printf("chartools: action %d: refund of %d points failed: %v", actionID, tool.Cost, refundErr)
+            database.FinishCharacterAction(actionID, database.ActionReview, "refund failed: "+refundErr.Error())
+            return "", fmt.Errorf("%w: %v", ErrToolReview, err)
+        }
+        return "", fmt.Errorf("%w: %v", ErrToolFailed, err)
+    }
+    
+    database.FinishCharacterAction(actionID, database.ActionDone, result)
+    return result, nil
+}
+
+// deliveryNotAttempted — ошибка точно означает, что worldserver команду не
+// выполнял, и ее можно повторить или вернуть поинты; при таймауте SOAP это неизвестно
+func deliveryNotAttempted(err error) bool {
+    var fault *soap.Fault
+    return errors.As(err, &fault) ||
+        errors.Is(err, soap.ErrUnauthorized) ||
+        errors.Is(err, soap.ErrCommandNotAllowed) ||
+        errors.Is(err, database.ErrMailSQLUnsupported)
+}
+
+// StartCharacterToolSweeper отправляет на разбор действия, застрявшие в pending:
+// поинты за них списаны, а выполнена ли команда — неизвестно
+func StartCharacterToolSweeper(ctx context.Context) {
+    if !config.AppConfig.Custom.CharacterToolsEnabled {
+        return
+    }
+    
+    ticker := time.NewTicker(toolSweepInterval)
+    defer ticker.Stop()
+    
+    for {
+        n, err := database.ReviewStaleCharacterActions(toolStaleAfter, "interrupted before the game server answered")
+        if err != nil {
+            log.Printf("chartools: %v", err)
+        } else if n > 0 {
+            log.Printf("chartools: %d interrupted actions moved to review", n)
+        }
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
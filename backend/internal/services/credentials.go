@@ -0,0 +1,411 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/subtle"
+    "database/sql"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+var (
+    ErrWrongPassword      = errors.New("current password is incorrect")
+    ErrPasswordReused     = errors.New("this password was used recently, choose a different one")
+    ErrEmailUnchanged     = errors.New("this is already your email")
+    ErrEmailInUse         = errors.New("this email is already used by another account")
+    ErrEmailBlacklisted   = errors.New("this email provider is not allowed")
+    ErrEmailTokenNotFound = errors.New("confirmation link is invalid or has expired")
+    ErrResetTokenNotFound = errors.New("reset link is invalid or has expired")
+)
+
+// Сколько живут ссылки подтверждения почты и сброса пароля
+const (
+    emailChangeLifetime   = 24 * time.Hour
+    passwordResetLifetime = 24 * time.Hour
+)
+
+// ChangePassword меняет пароль после проверки текущего: пишет новый sha_pass_hash
+// и SRP6 с новой солью, запоминает его в истории и завершает все веб-сессии
+func ChangePassword(ctx context.Context, username, current, password string) error {
+    account, err := database.GetAccountCredentials(username)
+    if err != nil {
+        return err
+    }
+    if !CheckPassword(account, current) {
+        return ErrWrongPassword
+    }
+    if err := ValidatePassword(password); err != nil {
+        return err
+    }
+    
+    reused, err := passwordReused(account, password)
+    if err != nil {
+        return err
+    }
+    if reused {
+        return ErrPasswordReused
+    }
+    
+    return setPassword(ctx, account, password)
+}
+
+// setPassword пишет новый пароль, запоминает его в истории и завершает все веб-сессии
+func setPassword(ctx context.Context, account *database.Account, password string) error {
+    if err := writeCredentials(account, password); err != nil {
+        return err
+    }
+    
+    if keep := config.AppConfig.Security.PasswordHistoryCount; keep > 0 {
+        entry, err := passwordHistoryEntry(account.Username, password)
+        if err != nil {
+            return err
+        }
+        if err := database.AddPasswordHistory(account.ID, entry, keep); err != nil {
+            return err
+        }
+    }
+    
+    return DeleteAccountSessions(ctx, account.ID)
+}
+
+func writeCredentials(account *database.Account, password string) error {
+    srp6, err := GenerateSRP6(account.Username, password, config.AppConfig.Game.ServerCore)
+    if err != nil {
+        return err
+    }
+    return database.UpdateCredentials(account.ID, GenerateSHA1Hash(account.Username, password), srp6.Salt, srp6.Verifier)
+}
+
+func passwordResetKey(token string) string {
+    return "password_reset:" + token
+}
+
+// forcePasswordReset заменяет пароль случайным, который никто не знает, так что
+// войти ни в игру, ни на сайт со старым уже нельзя, и шлет ссылку для нового
+func forcePasswordReset(ctx context.Context, account *database.Account) error {
+    if err := writeCredentials(account, GenerateRandomString(32)); err != nil {
+        return err
+    }
+    if err := DeleteAccountSessions(ctx, account.ID); err != nil {
+        return err
+    }
+    
+    token := GenerateRandomString(48)
+    if err := database.Redis.Set(ctx, passwordResetKey(token), account.ID, passwordResetLifetime).Err(); err != nil {
+        return err
+    }
+    if account.Email == "" {
+        return nil
+    }
+    
+    link := strings.TrimRight(config.AppConfig.Server.BaseURL, "/") + "/password/reset?token=" + token
+    server := config.AppConfig.Game.ServerName
+    body := fmt.Sprintf("The staff of %s has reset the password of account %s.\n\n"+
+        "Choose a new password here (the link works for 24 hours):\n%s\n",
+        server, account.Username, link)
+    return SendMail(account.Email, server+": set a new password", body)
+}
+
+// CompletePasswordReset задает новый пароль по ссылке из письма; actor —
+// контекст запроса для журнала
+func CompletePasswordReset(ctx context.Context, actor AdminActor, token, password string) error {
+    if err := ValidatePassword(password); err != nil {
+        return err
+    }
+    
+    value, err := database.Redis.Get(ctx, passwordResetKey(token)).Result()
+    if err != nil {
+        return ErrResetTokenNotFound
+    }
+    accountID, _ := strconv.Atoi(value)
+    account, err := database.GetAccountByID(accountID)
+    if err != nil {
+        return ErrResetTokenNotFound
+    }
+    credentials, err := database.GetAccountCredentials(account.Username)
+    if err != nil {
+        return err
+    }
+    
+    reused, err := passwordReused(credentials, password)
+    if err != nil {
+        return err
+    }
+    if reused {
+        return ErrPasswordReused
+    }
+    
+    // Ссылка одноразовая: удаляем до смены, чтобы два запроса не прошли оба
+    if n, err := database.Redis.Del(ctx, passwordResetKey(token)).Result(); err != nil || n == 0 {
+        return ErrResetTokenNotFound
+    }
+    if err := setPassword(ctx, credentials, password); err != nil {
+        return err
+    }
+    
+    Audit(actor.WithAccount(account.ID, account.Username), AuditPasswordResetDone, account.ID, nil)
+    return nil
+}
+
+// passwordReused — совпадает ли пароль с текущим или с одним из PASSWORD_HISTORY_COUNT прошлых
+func passwordReused(account *database.Account, password string) (bool, error) {
+    if CheckPassword(account, password) {
+        return true, nil
+    }
+    
+    keep := config.AppConfig.Security.PasswordHistoryCount
+    if keep <= 0 {
+        return false, nil
+    }
+    history, err := database.GetPasswordHistory(account.ID, keep)
+    if err != nil {
+        return false, err
+    }
+    
+    core := config.AppConfig.Game.ServerCore
+    for _, entry := range history {
+        salt, err := hex.DecodeString(entry.Salt)
+        if err != nil {
+            continue
+        }
+        hash := verifierHash(srp6Verifier(account.Username, password, salt, core))
+        if subtle.ConstantTimeCompare([]byte(hash), []byte(entry.VerifierHash)) == 1 {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+// В истории лежит не сам verifier, а его SHA-256 с отдельной солью,
+// чтобы утечка таблицы не давала готовых данных для входа в игру
+func passwordHistoryEntry(username, password string) (database.PasswordHistoryEntry, error) {
+    salt := make([]byte, 32)
+    if _, err := rand.Read(salt); err != nil {
+        return database.PasswordHistoryEntry{}, err
+    }
+    
+    return database.PasswordHistoryEntry{
+        Salt:         hex.EncodeToString(salt),
+        VerifierHash: verifierHash(srp6Verifier(username, password, salt, config.AppConfig.Game.ServerCore)),
+    }, nil
+}
+
+func verifierHash(verifier []byte) string {
+    sum := sha256.Sum256(verifier)
+    return hex.EncodeToString(sum[:])
+}
+
+func emailChangeKey(id string) string {
+    return "email_change:" + id
+}
+
+func emailChangeTokenKey(token string) string {
+    return "email_change_token:" + token
+}
+
+func accountEmailChangeKey(accountID int) string {
+    return fmt.Sprintf("account_email_change:%d", accountID)
+}
+
+// RequestEmailChange заводит смену почты и шлет ссылки подтверждения на старый
+// и новый адреса. Почта меняется, только когда подтверждены обе ссылки;
+// новый запрос отменяет предыдущий
+func RequestEmailChange(ctx context.Context, username, password, email string) error {
+    account, err := database.GetAccountCredentials(username)
+    if err != nil {
+        return err
+    }
+    if !CheckPassword(account, password) {
+        return ErrWrongPassword
+    }
+    if err := ValidateEmail(email); err != nil {
+        return err
+    }
+    
+    newEmail := strings.ToUpper(strings.TrimSpace(email))
+    if newEmail == strings.ToUpper(account.Email) {
+        return ErrEmailUnchanged
+    }
+    if emailBlacklisted(newEmail) {
+        return ErrEmailBlacklisted
+    }
+    if !config.AppConfig.Security.AllowMultipleAccountsPerEmail {
+        inUse, err := database.EmailInUse(newEmail)
+        if err != nil {
+            return err
+        }
+        if inUse {
+            return ErrEmailInUse
+        }
+    }
+    
+    id := GenerateRandomString(32)
+    oldToken := GenerateRandomString(48)
+    newToken := GenerateRandomString(48)
+    
+    pipe := database.Redis.TxPipeline()
+    pipe.HSet(ctx, emailChangeKey(id), map[string]interface{}{
+        "account_id": account.ID,
+        "username":   account.Username,
+        "old_email":  account.Email,
+        "new_email":  newEmail,
+    })
+    pipe.Expire(ctx, emailChangeKey(id), emailChangeLifetime)
+    pipe.Set(ctx, emailChangeTokenKey(oldToken), id+":old", emailChangeLifetime)
+    pipe.Set(ctx, emailChangeTokenKey(newToken), id+":new", emailChangeLifetime)
+    pipe.Set(ctx, accountEmailChangeKey(account.ID), id, emailChangeLifetime)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return err
+    }
+    
+    link := strings.TrimRight(config.AppConfig.Server.BaseURL, "/") + "/account/email/confirm?token="
+    server := config.AppConfig.Game.ServerName
+    
+    if account.Email != "" {
+        body := fmt.Sprintf("Someone asked to change the email of account %s on %s to %s.\n\n"+
+            "If it was you, confirm the change:\n%s\n\n"+
+            "If it wasn't, ignore this letter and change your password.\n",
+            account.Username, server, email, link+oldToken)
+        if err := SendMail(account.Email, server+": confirm email change", body); err != nil {
+            return err
+        }
+    }
+    
+    body := fmt.Sprintf("Confirm that this address should be used for account %s on %s:\n%s\n",
+        account.Username, server, link+newToken)
+    return SendMail(email, server+": confirm your new email", body)
+}
+
+// ConfirmEmailChange отмечает одну из ссылок; done — почта уже изменена
+func ConfirmEmailChange(ctx context.Context, actor AdminActor, token string) (done bool, err error) {
+    value, err := database.Redis.GetDel(ctx, emailChangeTokenKey(token)).Result()
+    if err != nil {
+        return false, ErrEmailTokenNotFound
+    }
+    id, side, _ := strings.Cut(value, ":")
+    
+    if err := database.Redis.HSet(ctx, emailChangeKey(id), side, 1).Err(); err != nil {
+        return false, err
+    }
+    change, err := database.Redis.HGetAll(ctx, emailChangeKey(id)).Result()
+    if err != nil {
+        return false, err
+    }
+    if change["account_id"] == "" {
+        return false, ErrEmailTokenNotFound
+    }
+    
+    // Подтверждение старого запроса после нового ничего не меняет
+    accountID, _ := strconv.Atoi(change["account_id"])
+    current, _ := database.Redis.Get(ctx, accountEmailChangeKey(accountID)).Result()
+    if current != id {
+        return false, ErrEmailTokenNotFound
+    }
+    
+    // Без старой почты подтверждать нечего
+    if (change["old"] == "" && change["old_email"] != "") || change["new"] == "" {
+        return false, nil
+    }
+    
+    if err := database.UpdateEmail(accountID, change["new_email"]); err != nil {
+        return false, err
+    }
+    database.Redis.Del(ctx, emailChangeKey(id), accountEmailChangeKey(accountID))
+    
+    RecordAudit(actor.WithAccount(accountID, change["username"]), AuditEvent{
+        Action:          AuditEmailChange,
+        TargetAccountID: accountID,
+        Before:          map[string]interface{}{"email": change["old_email"]},
+        After:           map[string]interface{}{"email": change["new_email"]},
+    })
+    
+    EmitWebhook(WebhookAccountVerified, map[string]interface{}{
+        "account_id": accountID,
+        "username":   change["username"],
+        "email":      change["new_email"],
+        "reason":     "email_change",
+    })
+    return true, nil
+}
+
+func emailVerifyKey(token string) string {
+    return "email_verify:" + token
+}
+
+// SendEmailVerification шлет новому аккаунту ссылку подтверждения почты,
+// если включен REQUIRE_EMAIL_VERIFICATION
+func SendEmailVerification(ctx context.Context, accountID int, username, email string) error {
+    if !config.AppConfig.Security.RequireEmailVerification || email == "" {
+        return nil
+    }
+    
+    token := GenerateRandomString(48)
+    pipe := database.Redis.TxPipeline()
+    pipe.HSet(ctx, emailVerifyKey(token), map[string]interface{}{
+        "account_id": accountID,
+        "username":   username,
+        "email":      email,
+    })
+    pipe.Expire(ctx, emailVerifyKey(token), emailChangeLifetime)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return err
+    }
+    
+    server := config.AppConfig.Game.ServerName
+    link := strings.TrimRight(config.AppConfig.Server.BaseURL, "/") + "/account/email/verify?token=" + token
+    body := fmt.Sprintf("Welcome to %s! Confirm the email of account %s:\n%s\n", server, username, link)
+    return SendMail(email, server+": confirm your email", body)
+}
+
+// ConfirmEmailVerification отмечает почту нового аккаунта подтвержденной.
+// Ссылка одноразовая и не действует, если почту успели сменить
+func ConfirmEmailVerification(ctx context.Context, token string) error {
+    pipe := database.Redis.TxPipeline()
+    get := pipe.HGetAll(ctx, emailVerifyKey(token))
+    pipe.Del(ctx, emailVerifyKey(token))
+    if _, err := pipe.Exec(ctx); err != nil {
+        return err
+    }
+    verify := get.Val()
+    if verify["account_id"] == "" {
+        return ErrEmailTokenNotFound
+    }
+    
+    account, err := database.GetAccountCredentials(verify["username"])
+    if err == sql.ErrNoRows {
+        return ErrEmailTokenNotFound
+    }
+    if err != nil {
+        return err
+    }
+    accountID, _ := strconv.Atoi(verify["account_id"])
+    if account.ID != accountID || !strings.EqualFold(account.Email, verify["email"]) {
+        return ErrEmailTokenNotFound
+    }
+    
+    EmitWebhook(WebhookAccountVerified, map[string]interface{}{
+        "account_id": accountID,
+        "username":   verify["username"],
+        "email":      verify["email"],
+        "reason":     "registration",
+    })
+    return nil
+}
+
+func emailBlacklisted(email string) bool {
+    _, domain, _ := strings.Cut(strings.ToLower(email), "@")
+    for _, blocked := range config.AppConfig.Security.EmailDomainsBlacklist {
+        if blocked = strings.ToLower(strings.TrimSpace(blocked)); blocked != "" && domain == blocked {
+            return true
+        }
+    }
+    return false
+}
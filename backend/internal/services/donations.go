@@ -0,0 +1,202 @@
+package services
+
+import (
+    "crypto/rand"
+    "database/sql"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "strings"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/payments"
+)
+
+const donationHistorySize = 50
+
+var (
+    ErrDonationsDisabled  = errors.New("donations are disabled")
+    ErrDonationAmount     = errors.New("donation amount is below the minimum")
+    ErrDonationNotFound   = errors.New("donation not found")
+    ErrDonationMismatch   = errors.New("event does not match the donation")
+    ErrDonationTransition = errors.New("donation status change is not allowed")
+)
+
+// Разрешенные смены статуса; refunded и chargeback окончательные
+var donationTransitions = map[string][]string{
+    payments.StatusPending: {payments.StatusPaid, payments.StatusRefunded, payments.StatusChargeback},
+    payments.StatusPaid:    {payments.StatusRefunded, payments.StatusChargeback},
+}
+
+type DonationProvider struct {
+    Slug string `json:"slug"`
+    Name string `json:"name"`
+}
+
+// DonationProviders — провайдеры для страницы пожертвований
+func DonationProviders() []DonationProvider {
+    if !config.AppConfig.Custom.DonationsEnabled {
+        return nil
+    }
+    var providers []DonationProvider
+    for _, p := range payments.Providers() {
+        providers = append(providers, DonationProvider{Slug: p.Slug(), Name: p.Name()})
+    }
+    return providers
+}
+
+// DonationPoints — сколько поинтов дается за сумму в центах
+func DonationPoints(amount int64) int {
+    return int(amount * int64(config.AppConfig.Custom.DonationPointsPerUnit) / 100)
+}
+
+// StartDonation заводит pending-платеж на amount целых единиц валюты и
+// возвращает адрес оплаты у провайдера
+func StartDonation(accountID int, slug string, amount int) (string, error) {
+    cfg := config.AppConfig.Custom
+    if !cfg.DonationsEnabled {
+        return "", ErrDonationsDisabled
+    }
+    provider, err := payments.Find(slug)
+    if err != nil {
+        return "", err
+    }
+    if amount < cfg.DonationMinAmount || amount <= 0 {
+        return "", ErrDonationAmount
+    }
+    
+    reference := make([]byte, 16)
+    if _, err := rand.Read(reference); err != nil {
+        return "", err
+    }
+    donation := &database.Donation{
+        AccountID: accountID,
+        Provider:  provider.Slug(),
+        Reference: hex.EncodeToString(reference),
+        Amount:    int64(amount) * 100,
+        Currency:  cfg.DonationCurrency,
+        Status:    payments.StatusPending,
+    }
+    if err := database.CreateDonation(donation); err != nil {
+        return "", err
+    }
+    
+    return provider.CheckoutURL(payments.Checkout{
+        Reference: donation.Reference,
+        Amount:    donation.Amount,
+        Currency:  donation.Currency,
+        AccountID: accountID,
+    })
+}
+
+// HandleDonationWebhook проверяет уведомление провайдера и применяет его
+func HandleDonationWebhook(slug string, r *http.Request) error {
+    if !config.AppConfig.Custom.DonationsEnabled {
+        return ErrDonationsDisabled
+    }
+    provider, err := payments.Find(slug)
+    if err != nil {
+        return err
+    }
+    event, err := provider.ParseWebhook(r)
+    if err != nil {
+        return err
+    }
+    return ApplyDonationEvent(slug, event)
+}
+
+// ApplyDonationEvent переводит платеж в статус из уведомления. Оплата начисляет
+// поинты по фактически полученной сумме, возврат и chargeback забирают
+// начисленное, даже если баланс уйдет в минус. Повтор уже примененного
+// уведомления ничего не меняет
+func ApplyDonationEvent(slug string, event *payments.Event) error {
+    for attempt := 0; attempt < 3; attempt++ {
+        donation, err := database.GetDonationByReference(event.Reference)
+        if err == sql.ErrNoRows || (err == nil && donation.Provider != slug) {
+            return ErrDonationNotFound
+        }
+        if err != nil {
+            return err
+        }
+        if !strings.EqualFold(event.Currency, donation.Currency) {
+            return ErrDonationMismatch
+        }
+        if event.Status == donation.Status {
+            return nil
+        }
+        if !donationTransitionAllowed(donation.Status, event.Status) {
+            return ErrDonationTransition
+        }
+        
+        amount := donation.Amount
+        if event.Status == payments.StatusPaid {
+            if event.Amount <= 0 {
+                return ErrDonationMismatch
+            }
+            amount = event.Amount
+        }
+        
+        reference := fmt.Sprintf("donation:%d", donation.ID)
+        var points *database.PointsEntry
+        switch {
+        case event.Status == payments.StatusPaid:
+            if credit := DonationPoints(amount); credit > 0 {
+                points = &database.PointsEntry{AccountID: donation.AccountID, Amount: credit, Kind: database.PointsDonation, Reference: reference}
+            }
+        case donation.Status == payments.StatusPaid && donation.Points > 0:
+            points = &database.PointsEntry{
+                AccountID:     donation.AccountID,
+                Amount:        -donation.Points,
+                Kind:          database.PointsChargeback,
+                Reference:     reference,
+                Reason:        event.Status,
+                AllowNegative: true,
+            }
+        }
+        
+        from := donation.Status
+        applied, err := database.TransitionDonation(donation, event.Status, event.PaymentID, amount, points)
+        if err != nil {
+            return err
+        }
+        if applied {
+            log.Printf("donation %d: %s -> %s", donation.ID, from, event.Status)
+            notifyDonation(donation)
+            return nil
+        }
+    }
+    return fmt.Errorf("donation %s: status keeps changing concurrently", event.Reference)
+}
+
+// notifyDonation объявляет крупные пожертвования; донор не называется
+func notifyDonation(d *database.Donation) {
+    threshold := int64(config.AppConfig.Custom.DonationNotifyAmount) * 100
+    if d.Status != payments.StatusPaid || threshold <= 0 || d.Amount < threshold {
+        return
+    }
+    Notify(Notification{
+        Kind:   NotifyDonation,
+        Title:  "New donation",
+        Text:   "Someone just supported the server. Thank you!",
+        Fields: []NotificationField{{Name: "Amount", Value: fmt.Sprintf("%d.%02d %s", d.Amount/100, d.Amount%100, d.Currency)}},
+    })
+}
+
+func donationTransitionAllowed(from, to string) bool {
+    for _, allowed := range donationTransitions[from] {
+        if allowed == to {
+            return true
+        }
+    }
+    return false
+}
+
+func GetDonationHistory(accountID int) ([]database.Donation, error) {
+    donations, err := database.GetAccountDonations(accountID, donationHistorySize)
+    if donations == nil && err == nil {
+        donations = []database.Donation{}
+    }
+    return donations, err
+}
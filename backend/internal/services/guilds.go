@@ -0,0 +1,269 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+const maxGuildsPerPage = 100
+
+var (
+    ErrGuildNotFound  = errors.New("guild not found")
+    ErrNotGuildMaster = errors.New("only the guild master can change these settings")
+)
+
+type GuildQuery struct {
+    Name    string `query:"q" json:"q,omitempty"`
+    Page    int    `query:"page" json:"page,omitempty"`
+    PerPage int    `query:"per_page" json:"per_page,omitempty"`
+}
+
+type GuildSummary struct {
+    ID         int       `json:"id"`
+    Name       string    `json:"name"`
+    LeaderName string    `json:"leader_name"`
+    Members    int       `json:"members"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+type GuildsPage struct {
+    RealmID int            `json:"realm_id"`
+    Query   GuildQuery     `json:"query"`
+    Guilds  []GuildSummary `json:"guilds"`
+    Total   int            `json:"total"`
+    Page    int            `json:"page"`
+    PerPage int            `json:"per_page"`
+    Pages   int            `json:"pages"`
+}
+
+type GuildMember struct {
+    OnlinePlayer
+    Rank     int    `json:"rank"`
+    RankName string `json:"rank_name"`
+    Online   bool   `json:"online"`
+}
+
+type ClassCount struct {
+    Class   int    `json:"class"`
+    Name    string `json:"name"`
+    Color   string `json:"color"`
+    Count   int    `json:"count"`
+    Percent int    `json:"percent"`
+}
+
+type GuildProfile struct {
+    GuildSummary
+    RealmID   int           `json:"realm_id"`
+    Leader    *GuildMember  `json:"leader,omitempty"`
+    MOTD      string        `json:"motd,omitempty"`
+    Info      string        `json:"info,omitempty"`
+    Online    int           `json:"online"`
+    Roster    []GuildMember `json:"roster"`
+    Classes   []ClassCount  `json:"classes"`
+    UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func (q *GuildQuery) normalize() {
+    q.Name = strings.TrimSpace(q.Name)
+    if q.Page < 1 {
+        q.Page = 1
+    }
+    if q.PerPage < 1 {
+        q.PerPage = 50
+    }
+    if q.PerPage > maxGuildsPerPage {
+        q.PerPage = maxGuildsPerPage
+    }
+}
+
+func newGuildSummary(g *database.Guild) GuildSummary {
+    return GuildSummary{
+        ID:         g.ID,
+        Name:       g.Name,
+        LeaderName: g.LeaderName,
+        Members:    g.Members,
+        CreatedAt:  g.CreatedAt,
+    }
+}
+
+func guildKey(realmID int, name string) string {
+    return fmt.Sprintf("guild:%d:%s", realmID, strings.ToLower(name))
+}
+
+func ListGuilds(ctx context.Context, realmID int, q GuildQuery) (*GuildsPage, error) {
+    q.normalize()
+    
+    key := fmt.Sprintf("guilds:%d:%s:%d:%d", realmID, strings.ToLower(q.Name), q.Page, q.PerPage)
+    return cache.GetOrLoad(ctx, key, playersTTL(), func(ctx context.Context) (*GuildsPage, error) {
+        guilds, total, err := database.SearchGuilds(realmID, database.GuildFilter{
+            Name:   q.Name,
+            Limit:  q.PerPage,
+            Offset: (q.Page - 1) * q.PerPage,
+        })
+        if err != nil {
+            return nil, err
+        }
+        
+        page := &GuildsPage{
+            RealmID: realmID,
+            Query:   q,
+            Guilds:  make([]GuildSummary, 0, len(guilds)),
+            Total:   total,
+            Page:    q.Page,
+            PerPage: q.PerPage,
+            Pages:   (total + q.PerPage - 1) / q.PerPage,
+        }
+        for i := range guilds {
+            page.Guilds = append(page.Guilds, newGuildSummary(&guilds[i]))
+        }
+        return page, nil
+    })
+}
+
+// GetGuildProfile собирает публичную страницу гильдии; MOTD и описание
+// попадают в нее только если гильд-мастер включил их показ
+func GetGuildProfile(ctx context.Context, realmID int, name string) (*GuildProfile, error) {
+    return cache.GetOrLoad(ctx, guildKey(realmID, name), armoryTTL(), func(ctx context.Context) (*GuildProfile, error) {
+        guild, err := database.GetGuildByName(realmID, name)
+        if err == sql.ErrNoRows {
+            return nil, ErrGuildNotFound
+        }
+        if err != nil {
+            return nil, err
+        }
+        
+        settings, err := database.GetGuildSettings(realmID, guild.ID)
+        if err != nil {
+            return nil, err
+        }
+        
+        members, err := database.GetGuildRoster(realmID, guild.ID)
+        if err != nil {
+            return nil, err
+        }
+        
+        profile := &GuildProfile{
+            GuildSummary: newGuildSummary(guild),
+            RealmID:      realmID,
+            Roster:       make([]GuildMember, 0, len(members)),
+            UpdatedAt:    time.Now(),
+        }
+        if settings.ShowMOTD {
+            profile.MOTD = guild.MOTD
+        }
+        if settings.ShowInfo {
+            profile.Info = guild.Info
+        }
+        
+        counts := make(map[int]int)
+        for _, m := range members {
+            member := GuildMember{
+                OnlinePlayer: NewOnlinePlayer(m.Character),
+                Rank:         m.Rank,
+                RankName:     m.RankName,
+                Online:       m.Online,
+            }
+            profile.Roster = append(profile.Roster, member)
+            if m.GUID == guild.LeaderGUID {
+                leader := member
+                profile.Leader = &leader
+            }
+            if m.Online {
+                profile.Online++
+            }
+            counts[m.Class]++
+        }
+        profile.Members = len(members)
+        profile.Classes = classDistribution(counts, len(members))
+        
+        return profile, nil
+    })
+}
+
+func classDistribution(counts map[int]int, total int) []ClassCount {
+    result := make([]ClassCount, 0, len(counts))
+    for class, count := range counts {
+        result = append(result, ClassCount{
+            Class:   class,
+            Name:    gamedata.ClassName(class),
+            Color:   gamedata.ClassColor(class),
+            Count:   count,
+            Percent: count * 100 / total,
+        })
+    }
+    sort.Slice(result, func(i, j int) bool {
+        if result[i].Count != result[j].Count {
+            return result[i].Count > result[j].Count
+        }
+        return result[i].Name < result[j].Name
+    })
+    return result
+}
+
+// SetGuildSettings меняет видимость MOTD и описания; разрешено только владельцу лидера гильдии
+func SetGuildSettings(ctx context.Context, realmID, guildID, accountID int, s database.GuildSettings) error {
+    guild, err := database.GetGuildByID(realmID, guildID)
+    if err == sql.ErrNoRows {
+        return ErrGuildNotFound
+    }
+    if err != nil {
+        return err
+    }
+    
+    owner, _, err := database.GetCharacterOwner(realmID, guild.LeaderGUID)
+    if err != nil && err != sql.ErrNoRows {
+        return err
+    }
+    if owner != accountID {
+        return ErrNotGuildMaster
+    }
+    
+    if err := database.SetGuildSettings(realmID, guildID, accountID, s); err != nil {
+        return err
+    }
+    cache.Invalidate(ctx, guildKey(realmID, guild.Name))
+    return nil
+}
+
+type ManagedGuild struct {
+    RealmID   int
+    RealmName string
+    Guild     GuildSummary
+    MOTD      string
+    Info      string
+    Settings  database.GuildSettings
+}
+
+// GuildsToManage — гильдии, где лидер — персонаж аккаунта, на всех реалмах
+func GuildsToManage(accountID int) ([]ManagedGuild, error) {
+    var result []ManagedGuild
+    for _, realm := range database.GetRealms() {
+        guilds, err := database.GetLeaderGuilds(realm.ID, accountID)
+        if err != nil {
+            return nil, err
+        }
+        for i := range guilds {
+            settings, err := database.GetGuildSettings(realm.ID, guilds[i].ID)
+            if err != nil {
+                return nil, err
+            }
+            result = append(result, ManagedGuild{
+                RealmID:   realm.ID,
+                RealmName: realm.Name,
+                Guild:     newGuildSummary(&guilds[i]),
+                MOTD:      guilds[i].MOTD,
+                Info:      guilds[i].Info,
+                Settings:  settings,
+            })
+        }
+    }
+    return result, nil
+}
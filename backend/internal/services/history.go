@@ -0,0 +1,122 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+type HistoryRange struct {
+    Name   string
+    Period time.Duration
+    Bucket time.Duration
+}
+
+// Размер корзины подобран так, чтобы на графике было 100–200 точек
+var HistoryRanges = []HistoryRange{
+    {Name: "24h", Period: 24 * time.Hour, Bucket: 15 * time.Minute},
+    {Name: "7d", Period: 7 * 24 * time.Hour, Bucket: time.Hour},
+    {Name: "30d", Period: 30 * 24 * time.Hour, Bucket: 6 * time.Hour},
+    {Name: "90d", Period: 90 * 24 * time.Hour, Bucket: 24 * time.Hour},
+}
+
+type StatsHistory struct {
+    RealmID       int                           `json:"realm_id"`
+    Range         string                        `json:"range"`
+    BucketSeconds int64                         `json:"bucket_seconds"`
+    Online        []database.OnlinePoint        `json:"online"`
+    PeakHours     []database.HourlyOnline       `json:"peak_hours"`
+    Registrations []database.DailyRegistrations `json:"registrations"`
+}
+
+func FindHistoryRange(name string) (HistoryRange, error) {
+    for _, r := range HistoryRanges {
+        if r.Name == name {
+            return r, nil
+        }
+    }
+    return HistoryRange{}, fmt.Errorf("unknown range %q", name)
+}
+
+// StartStatsSampler пишет снимок онлайна каждого реалма и регистрации по часам
+func StartStatsSampler(ctx context.Context) {
+    interval := time.Duration(config.AppConfig.Monitoring.StatsSampleInterval) * time.Second
+    if interval <= 0 {
+        interval = 5 * time.Minute
+    }
+    
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    
+    for {
+        sampleStats(time.Now())
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func sampleStats(now time.Time) {
+    for _, realm := range database.GetRealms() {
+        byRace, err := database.CountOnlineByRace(realm.ID)
+        if err != nil {
+            log.Printf("stats sampler: realm %d: %v", realm.ID, err)
+            continue
+        }
+        
+        sample := database.StatsSample{RealmID: realm.ID, SampledAt: now}
+        for race, count := range byRace {
+            sample.Online += count
+            switch gamedata.RaceFaction(race) {
+            case gamedata.FactionAlliance:
+                sample.Alliance += count
+            case gamedata.FactionHorde:
+                sample.Horde += count
+            }
+        }
+        
+        if err := database.InsertStatsSample(sample); err != nil {
+            log.Printf("stats sampler: realm %d: %v", realm.ID, err)
+        }
+    }
+    
+    // Прошлый час пересчитываем тоже, чтобы добрать регистрации на стыке часов
+    hour := now.Truncate(time.Hour)
+    for _, h := range []time.Time{hour.Add(-time.Hour), hour} {
+        if err := database.RefreshRegistrationHour(h); err != nil {
+            log.Printf("stats sampler: registrations: %v", err)
+        }
+    }
+}
+
+func GetStatsHistory(ctx context.Context, realmID int, r HistoryRange) (*StatsHistory, error) {
+    key := fmt.Sprintf("history:%d:%s", realmID, r.Name)
+    return cache.GetOrLoad(ctx, key, statsTTL(), func(ctx context.Context) (*StatsHistory, error) {
+        since := time.Now().Add(-r.Period)
+        history := &StatsHistory{
+            RealmID:       realmID,
+            Range:         r.Name,
+            BucketSeconds: int64(r.Bucket / time.Second),
+        }
+        
+        var err error
+        if history.Online, err = database.GetOnlineHistory(realmID, since, r.Bucket); err != nil {
+            return nil, err
+        }
+        if history.PeakHours, err = database.GetPeakHours(realmID, since); err != nil {
+            return nil, err
+        }
+        if history.Registrations, err = database.GetDailyRegistrations(since); err != nil {
+            return nil, err
+        }
+        return history, nil
+    })
+}
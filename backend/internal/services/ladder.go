@@ -0,0 +1,312 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+// Сколько мест хранится в снимке каждой таблицы
+const ladderSize = 500
+
+const maxLadderPerPage = 100
+
+var HonorKinds = []string{"kills", "honor"}
+
+var ErrArenaTeamNotFound = errors.New("arena team not found")
+
+type ArenaQuery struct {
+    Bracket int `query:"bracket" json:"bracket"`
+    Season  int `query:"season" json:"season"`
+    Page    int `query:"page" json:"page,omitempty"`
+    PerPage int `query:"per_page" json:"per_page,omitempty"`
+}
+
+type HonorQuery struct {
+    Kind    string `query:"sort" json:"sort"`
+    Page    int    `query:"page" json:"page,omitempty"`
+    PerPage int    `query:"per_page" json:"per_page,omitempty"`
+}
+
+type ArenaTeamEntry struct {
+    ID          int              `json:"id"`
+    Position    int              `json:"position"`
+    Name        string           `json:"name"`
+    Rating      int              `json:"rating"`
+    SeasonGames int              `json:"season_games"`
+    SeasonWins  int              `json:"season_wins"`
+    WinRate     int              `json:"win_rate"`
+    CaptainName string           `json:"captain_name"`
+    Faction     gamedata.Faction `json:"faction"`
+}
+
+type ArenaLadderPage struct {
+    RealmID   int              `json:"realm_id"`
+    Query     ArenaQuery       `json:"query"`
+    Seasons   []int            `json:"seasons"`
+    Teams     []ArenaTeamEntry `json:"teams"`
+    Total     int              `json:"total"`
+    Page      int              `json:"page"`
+    PerPage   int              `json:"per_page"`
+    Pages     int              `json:"pages"`
+    UpdatedAt time.Time        `json:"updated_at"`
+}
+
+type ArenaTeamMember struct {
+    OnlinePlayer
+    PersonalRating int `json:"personal_rating"`
+    SeasonGames    int `json:"season_games"`
+    SeasonWins     int `json:"season_wins"`
+}
+
+type ArenaTeamDetail struct {
+    ArenaTeamEntry
+    RealmID   int               `json:"realm_id"`
+    Season    int               `json:"season"`
+    Bracket   int               `json:"bracket"`
+    Members   []ArenaTeamMember `json:"members"`
+    UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type HonorLadderEntry struct {
+    OnlinePlayer
+    Position    int `json:"position"`
+    TotalKills  int `json:"total_kills"`
+    HonorPoints int `json:"honor_points"`
+}
+
+type HonorLadderPage struct {
+    RealmID   int                `json:"realm_id"`
+    Query     HonorQuery         `json:"query"`
+    Entries   []HonorLadderEntry `json:"entries"`
+    Total     int                `json:"total"`
+    Page      int                `json:"page"`
+    PerPage   int                `json:"per_page"`
+    Pages     int                `json:"pages"`
+    UpdatedAt time.Time          `json:"updated_at"`
+}
+
+func CurrentArenaSeason() int {
+    return config.AppConfig.Game.ArenaSeason
+}
+
+func normalizePaging(page, perPage *int) {
+    if *page < 1 {
+        *page = 1
+    }
+    if *perPage < 1 {
+        *perPage = 50
+    }
+    if *perPage > maxLadderPerPage {
+        *perPage = maxLadderPerPage
+    }
+}
+
+func (q *ArenaQuery) normalize() {
+    valid := false
+    for _, b := range database.ArenaBrackets {
+        valid = valid || q.Bracket == b
+    }
+    if !valid {
+        q.Bracket = 2
+    }
+    if q.Season <= 0 {
+        q.Season = CurrentArenaSeason()
+    }
+    normalizePaging(&q.Page, &q.PerPage)
+}
+
+func (q *HonorQuery) normalize() {
+    if q.Kind != "honor" {
+        q.Kind = "kills"
+    }
+    normalizePaging(&q.Page, &q.PerPage)
+}
+
+// StartLadderUpdater пересчитывает рейтинги по расписанию, а не на каждый запрос:
+// сортировка arena_team и characters по рейтингу на живой базе недешева
+func StartLadderUpdater(ctx context.Context) {
+    interval := time.Duration(config.AppConfig.Monitoring.LadderInterval) * time.Second
+    if interval <= 0 {
+        interval = 10 * time.Minute
+    }
+    
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    
+    for {
+        RefreshLadders(time.Now())
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// RefreshLadders снимает текущий сезон арены и топы чести со всех реалмов
+func RefreshLadders(now time.Time) {
+    season := CurrentArenaSeason()
+    
+    for _, realm := range database.GetRealms() {
+        for _, bracket := range database.ArenaBrackets {
+            if err := refreshArenaBracket(realm.ID, season, bracket, now); err != nil {
+                log.Printf("ladder: realm %d %dv%d: %v", realm.ID, bracket, bracket, err)
+            }
+        }
+        
+        for _, kind := range HonorKinds {
+            entries, err := database.ReadHonorTop(realm.ID, kind, ladderSize)
+            if err == nil {
+                err = database.SaveHonorSnapshot(realm.ID, kind, entries, now)
+            }
+            if err != nil {
+                log.Printf("ladder: realm %d honor %s: %v", realm.ID, kind, err)
+            }
+        }
+    }
+}
+
+func refreshArenaBracket(realmID, season, bracket int, now time.Time) error {
+    teams, err := database.ReadArenaTeams(realmID, bracket, ladderSize)
+    if err != nil {
+        return err
+    }
+    
+    ids := make([]int, len(teams))
+    for i, t := range teams {
+        ids[i] = t.ID
+    }
+    
+    members, err := database.ReadArenaTeamMembers(realmID, ids)
+    if err != nil {
+        return err
+    }
+    
+    return database.SaveArenaSnapshot(realmID, season, bracket, teams, members, now)
+}
+
+func newArenaTeamEntry(t database.ArenaTeam) ArenaTeamEntry {
+    entry := ArenaTeamEntry{
+        ID:          t.ID,
+        Position:    t.Position,
+        Name:        t.Name,
+        Rating:      t.Rating,
+        SeasonGames: t.SeasonGames,
+        SeasonWins:  t.SeasonWins,
+        CaptainName: t.CaptainName,
+        Faction:     gamedata.RaceFaction(t.CaptainRace),
+    }
+    if t.SeasonGames > 0 {
+        entry.WinRate = t.SeasonWins * 100 / t.SeasonGames
+    }
+    return entry
+}
+
+func GetArenaLadder(ctx context.Context, realmID int, q ArenaQuery) (*ArenaLadderPage, error) {
+    q.normalize()
+    
+    key := fmt.Sprintf("arena:%d:%d:%d:%d:%d", realmID, q.Season, q.Bracket, q.Page, q.PerPage)
+    return cache.GetOrLoad(ctx, key, statsTTL(), func(ctx context.Context) (*ArenaLadderPage, error) {
+        teams, total, err := database.GetArenaLadder(realmID, q.Season, q.Bracket, q.PerPage, (q.Page-1)*q.PerPage)
+        if err != nil {
+            return nil, err
+        }
+        
+        seasons, err := database.GetArenaSeasons(realmID)
+        if err != nil {
+            return nil, err
+        }
+        
+        page := &ArenaLadderPage{
+            RealmID: realmID,
+            Query:   q,
+            Seasons: seasons,
+            Teams:   make([]ArenaTeamEntry, 0, len(teams)),
+            Total:   total,
+            Page:    q.Page,
+            PerPage: q.PerPage,
+            Pages:   (total + q.PerPage - 1) / q.PerPage,
+        }
+        for _, t := range teams {
+            page.Teams = append(page.Teams, newArenaTeamEntry(t))
+            page.UpdatedAt = t.ComputedAt
+        }
+        return page, nil
+    })
+}
+
+func GetArenaTeam(ctx context.Context, realmID, season, teamID int) (*ArenaTeamDetail, error) {
+    if season <= 0 {
+        season = CurrentArenaSeason()
+    }
+    
+    key := fmt.Sprintf("arena_team:%d:%d:%d", realmID, season, teamID)
+    return cache.GetOrLoad(ctx, key, statsTTL(), func(ctx context.Context) (*ArenaTeamDetail, error) {
+        team, members, err := database.GetArenaTeamSnapshot(realmID, season, teamID)
+        if err == sql.ErrNoRows {
+            return nil, ErrArenaTeamNotFound
+        }
+        if err != nil {
+            return nil, err
+        }
+        
+        detail := &ArenaTeamDetail{
+            ArenaTeamEntry: newArenaTeamEntry(*team),
+            RealmID:        realmID,
+            Season:         season,
+            Bracket:        team.Bracket,
+            Members:        make([]ArenaTeamMember, 0, len(members)),
+            UpdatedAt:      team.ComputedAt,
+        }
+        for _, m := range members {
+            detail.Members = append(detail.Members, ArenaTeamMember{
+                OnlinePlayer:   NewOnlinePlayer(m.Character),
+                PersonalRating: m.PersonalRating,
+                SeasonGames:    m.SeasonGames,
+                SeasonWins:     m.SeasonWins,
+            })
+        }
+        return detail, nil
+    })
+}
+
+func GetHonorLadder(ctx context.Context, realmID int, q HonorQuery) (*HonorLadderPage, error) {
+    q.normalize()
+    
+    key := fmt.Sprintf("honor:%d:%s:%d:%d", realmID, q.Kind, q.Page, q.PerPage)
+    return cache.GetOrLoad(ctx, key, statsTTL(), func(ctx context.Context) (*HonorLadderPage, error) {
+        entries, total, err := database.GetHonorLadder(realmID, q.Kind, q.PerPage, (q.Page-1)*q.PerPage)
+        if err != nil {
+            return nil, err
+        }
+        
+        page := &HonorLadderPage{
+            RealmID: realmID,
+            Query:   q,
+            Entries: make([]HonorLadderEntry, 0, len(entries)),
+            Total:   total,
+            Page:    q.Page,
+            PerPage: q.PerPage,
+            Pages:   (total + q.PerPage - 1) / q.PerPage,
+        }
+        for _, e := range entries {
+            page.Entries = append(page.Entries, HonorLadderEntry{
+                OnlinePlayer: NewOnlinePlayer(e.Character),
+                Position:     e.Position,
+                TotalKills:   e.TotalKills,
+                HonorPoints:  e.HonorPoints,
+            })
+            page.UpdatedAt = e.ComputedAt
+        }
+        return page, nil
+    })
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+// Формат значения для шаблонов: duration — секунды /played, money — медь,
+// date — unix-время, number — просто число
+type Leaderboard struct {
+    Slug        string `json:"slug"`
+    Title       string `json:"title"`
+    Description string `json:"description"`
+    Metric      string `json:"-"`
+    Format      string `json:"format"`
+    Level       int    `json:"level,omitempty"`
+}
+
+type LeaderboardRow struct {
+    OnlinePlayer
+    Position int   `json:"position"`
+    Value    int64 `json:"value"`
+}
+
+type LeaderboardResult struct {
+    RealmID   int              `json:"realm_id"`
+    Board     Leaderboard      `json:"board"`
+    Class     int              `json:"class,omitempty"`
+    Rows      []LeaderboardRow `json:"rows"`
+    UpdatedAt time.Time        `json:"updated_at"`
+}
+
+var leaderboards = []Leaderboard{
+    {Slug: "playtime", Title: "Most Played", Description: "Total time played", Metric: database.MetricPlaytime, Format: "duration"},
+    {Slug: "wealth", Title: "Wealthiest", Description: "Gold on hand", Metric: database.MetricWealth, Format: "money"},
+    {Slug: "achievements", Title: "Achievement Points", Description: "Total achievement points", Metric: database.MetricAchievements, Format: "number"},
+}
+
+func init() {
+    // Гонки за уровень: first-60, first-70, first-80
+    for _, level := range []int{60, 70, 80} {
+        leaderboards = append(leaderboards, Leaderboard{
+            Slug:        "first-" + strconv.Itoa(level),
+            Title:       "First to " + strconv.Itoa(level),
+            Description: "Who reached level " + strconv.Itoa(level) + " first",
+            Metric:      database.MetricFirstLevel,
+            Format:      "date",
+            Level:       level,
+        })
+    }
+}
+
+// Leaderboards — включенные в LEADERBOARDS таблицы в порядке из конфига
+func Leaderboards() []Leaderboard {
+    var enabled []Leaderboard
+    for _, slug := range config.AppConfig.Custom.Leaderboards {
+        for _, board := range leaderboards {
+            if board.Slug == strings.TrimSpace(slug) {
+                enabled = append(enabled, board)
+            }
+        }
+    }
+    return enabled
+}
+
+func FindLeaderboard(slug string) (Leaderboard, error) {
+    for _, board := range Leaderboards() {
+        if board.Slug == slug {
+            return board, nil
+        }
+    }
+    return Leaderboard{}, fmt.Errorf("unknown leaderboard %q", slug)
+}
+
+func leaderboardTTL() time.Duration {
+    return time.Duration(config.AppConfig.Cache.LeaderboardDuration) * time.Second
+}
+
+// GetLeaderboard считает таблицу лидеров реалма; персонажи GM-аккаунтов не участвуют
+func GetLeaderboard(ctx context.Context, realmID int, board Leaderboard, class int) (*LeaderboardResult, error) {
+    if _, ok := gamedata.GetClass(class); !ok {
+        class = 0
+    }
+    
+    key := fmt.Sprintf("leaderboard:%d:%s:%d", realmID, board.Slug, class)
+    return cache.GetOrLoad(ctx, key, leaderboardTTL(), func(ctx context.Context) (*LeaderboardResult, error) {
+        gms, err := database.GetGMAccountIDs(realmID)
+        if err != nil {
+            return nil, err
+        }
+        
+        q := database.LeaderboardQuery{
+            Metric:          board.Metric,
+            Class:           class,
+            ExcludeAccounts: gms,
+            Limit:           config.AppConfig.Custom.LeaderboardSize,
+        }
+        
+        switch board.Metric {
+        case database.MetricAchievements:
+            if q.AchievementPoints, err = achievementPoints(ctx); err != nil {
+                return nil, err
+            }
+        case database.MetricFirstLevel:
+            id, ok := gamedata.LevelAchievement(board.Level)
+            if !ok {
+                return nil, fmt.Errorf("no achievement for level %d", board.Level)
+            }
+            q.Achievement = id
+        }
+        
+        entries, err := database.GetLeaderboard(realmID, q)
+        if err != nil {
+            return nil, err
+        }
+        
+        result := &LeaderboardResult{
+            RealmID:   realmID,
+            Board:     board,
+            Class:     class,
+            Rows:      make([]LeaderboardRow, 0, len(entries)),
+            UpdatedAt: time.Now(),
+        }
+        for i, e := range entries {
+            result.Rows = append(result.Rows, LeaderboardRow{
+                OnlinePlayer: NewOnlinePlayer(e.Character),
+                Position:     i + 1,
+                Value:        e.Value,
+            })
+        }
+        return result, nil
+    })
+}
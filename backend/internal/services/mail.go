@@ -0,0 +1,69 @@
+package services
+
+import (
+    "crypto/tls"
+    "fmt"
+    "mime"
+    "net"
+    "net/smtp"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+)
+
+// SendMail отправляет простое текстовое письмо через SMTP из конфига.
+// При SMTP_SECURE=true письмо не уйдет, если сервер не поддерживает STARTTLS
+func SendMail(to, subject, body string) error {
+    cfg := config.AppConfig.Email
+    addr := net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort)
+    
+    client, err := smtp.Dial(addr)
+    if err != nil {
+        return err
+    }
+    defer client.Close()
+    
+    if ok, _ := client.Extension("STARTTLS"); ok {
+        if err := client.StartTLS(&tls.Config{ServerName: cfg.SMTPHost}); err != nil {
+            return err
+        }
+    } else if cfg.SMTPSecure {
+        return fmt.Errorf("smtp: %s does not support STARTTLS", addr)
+    }
+    
+    if cfg.SMTPUser != "" {
+        if err := client.Auth(smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)); err != nil {
+            return err
+        }
+    }
+    
+    if err := client.Mail(cfg.SMTPFrom); err != nil {
+        return err
+    }
+    if err := client.Rcpt(to); err != nil {
+        return err
+    }
+    
+    w, err := client.Data()
+    if err != nil {
+        return err
+    }
+    
+    headers := []string{
+        "From: " + mime.QEncoding.Encode("utf-8", cfg.SMTPFromName) + " <" + cfg.SMTPFrom + ">",
+        "To: " + to,
+        "Subject: " + mime.QEncoding.Encode("utf-8", subject),
+        "Date: " + time.Now().Format(time.RFC1123Z),
+        "MIME-Version: 1.0",
+        "Content-Type: text/plain; charset=utf-8",
+    }
+    message := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n")
+    if _, err := w.Write([]byte(message)); err != nil {
+        return err
+    }
+    if err := w.Close(); err != nil {
+        return err
+    }
+    
+    return client.Quit()
+}
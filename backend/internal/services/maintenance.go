@@ -0,0 +1,91 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "log"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "github.com/redis/go-redis/v9"
+)
+
+const (
+    maintenanceInterval = time.Minute
+    maintenanceStateKey = "maintenance:active"
+)
+
+var maintenanceLayouts = []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02T15:04"}
+
+func parseMaintenanceTime(raw string) time.Time {
+    for _, layout := range maintenanceLayouts {
+        if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+            return t
+        }
+    }
+    return time.Time{}
+}
+
+// MaintenanceActive — включен MAINTENANCE_MODE или сейчас идет окно
+// MAINTENANCE_START…MAINTENANCE_END (конец можно не указывать)
+func MaintenanceActive(now time.Time) bool {
+    cfg := config.AppConfig.Maintenance
+    if cfg.Enabled {
+        return true
+    }
+    
+    start := parseMaintenanceTime(cfg.Start)
+    if start.IsZero() || now.Before(start) {
+        return false
+    }
+    end := parseMaintenanceTime(cfg.End)
+    return end.IsZero() || now.Before(end)
+}
+
+// StartMaintenanceWatcher следит за началом и концом техработ и объявляет их.
+// Последнее состояние хранится в Redis, чтобы перезапуск сайта не повторял объявление
+func StartMaintenanceWatcher(ctx context.Context) {
+    ticker := time.NewTicker(maintenanceInterval)
+    defer ticker.Stop()
+    
+    for {
+        if err := checkMaintenance(ctx); err != nil {
+            log.Printf("maintenance watcher: %v", err)
+        }
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func checkMaintenance(ctx context.Context) error {
+    active := MaintenanceActive(time.Now())
+    value := "0"
+    if active {
+        value = "1"
+    }
+    
+    previous, err := database.Redis.GetSet(ctx, maintenanceStateKey, value).Result()
+    if err != nil && !errors.Is(err, redis.Nil) {
+        return err
+    }
+    // Первый запуск без сохраненного состояния: объявляем только начавшиеся техработы
+    if previous == value || (previous == "" && !active) {
+        return nil
+    }
+    
+    cfg := config.AppConfig.Maintenance
+    if active {
+        n := Notification{Kind: NotifyMaintenanceStart, Title: "Maintenance started", Text: cfg.Message}
+        if end := parseMaintenanceTime(cfg.End); !end.IsZero() {
+            n.Fields = []NotificationField{{Name: "Expected end", Value: end.Format("2006-01-02 15:04 MST")}}
+        }
+        Notify(n)
+    } else {
+        Notify(Notification{Kind: NotifyMaintenanceEnd, Title: "Maintenance is over", Text: "The server is open again."})
+    }
+    return nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+    "context"
+    "log"
+    "strings"
+    "sync"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/discord"
+)
+
+// Виды уведомлений
+const (
+    NotifyRegistration     = "registration"
+    NotifyRealmDown        = "realm.down"
+    NotifyRealmUp          = "realm.up"
+    NotifyMaintenanceStart = "maintenance.start"
+    NotifyMaintenanceEnd   = "maintenance.end"
+    NotifyDonation         = "donation"
+)
+
+// Notification — событие для внешних каналов (Discord, Telegram). Персональные
+// данные сюда не попадают: имя аккаунта маскируется, почта и IP не передаются
+type Notification struct {
+    Kind   string              `json:"event"`
+    Title  string              `json:"title"`
+    Text   string              `json:"text"`
+    Fields []NotificationField `json:"fields,omitempty"`
+    Time   time.Time           `json:"time"`
+}
+
+type NotificationField struct {
+    Name  string `json:"name"`
+    Value string `json:"value"`
+}
+
+// Каналы доставки; каждый обязан вернуться сразу, не дожидаясь отправки
+var (
+    notifySinksMu sync.RWMutex
+    notifySinks   []func(Notification)
+)
+
+func addNotifySink(sink func(Notification)) {
+    notifySinksMu.Lock()
+    defer notifySinksMu.Unlock()
+    notifySinks = append(notifySinks, sink)
+}
+
+// Notify раздает событие всем настроенным каналам
+func Notify(n Notification) {
+    if n.Time.IsZero() {
+        n.Time = time.Now()
+    }
+    
+    notifySinksMu.RLock()
+    defer notifySinksMu.RUnlock()
+    for _, sink := range notifySinks {
+        sink(n)
+    }
+}
+
+// StartNotifiers запускает отправку уведомлений в настроенные каналы
+func StartNotifiers(ctx context.Context) {
+    cfg := config.AppConfig.Integrations
+    
+    if cfg.DiscordWebhookURL != "" {
+        notifier, err := discord.NewNotifier(cfg.DiscordWebhookURL, cfg.DiscordBaseURL, cfg.DiscordQueueSize)
+        if err != nil {
+            log.Printf("discord notifications disabled: %v", err)
+        } else {
+            go notifier.Run(ctx)
+            addNotifySink(discordSink(notifier))
+        }
+    }
+    
+    if cfg.TelegramBotToken != "" {
+        startTelegram(ctx)
+    }
+}
+
+var discordColors = map[string]int{
+    NotifyRegistration:     0x3b82f6,
+    NotifyRealmDown:        0xef4444,
+    NotifyRealmUp:          0x22c55e,
+    NotifyMaintenanceStart: 0xf59e0b,
+    NotifyMaintenanceEnd:   0x22c55e,
+    NotifyDonation:         0xeab308,
+}
+
+func discordSink(notifier *discord.Notifier) func(Notification) {
+    return func(n Notification) {
+        if n.Kind == NotifyRegistration && !config.AppConfig.Integrations.DiscordRegistrationNotify {
+            return
+        }
+        
+        embed := discord.Embed{
+            Title:       n.Title,
+            Description: n.Text,
+            Color:       discordColors[n.Kind],
+            Footer:      &discord.Footer{Text: config.AppConfig.Game.ServerName},
+            Timestamp:   &n.Time,
+        }
+        for _, f := range n.Fields {
+            embed.Fields = append(embed.Fields, discord.Field{Name: f.Name, Value: f.Value, Inline: true})
+        }
+        
+        msg := discord.Message{Username: config.AppConfig.Game.ServerName, Embeds: []discord.Embed{embed}}
+        if err := notifier.Enqueue(msg); err != nil {
+            log.Printf("discord: dropped %s notification: %v", n.Kind, err)
+        }
+    }
+}
+
+// MaskUsername оставляет первую и последнюю букву: "ARTHAS" -> "A****S"
+func MaskUsername(username string) string {
+    runes := []rune(username)
+    switch len(runes) {
+    case 0:
+        return ""
+    case 1, 2:
+        return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+    }
+    return string(runes[0]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1])
+}
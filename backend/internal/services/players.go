@@ -0,0 +1,129 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+)
+
+const maxPlayersPerPage = 100
+
+type PlayerQuery struct {
+    Faction  string `query:"faction" json:"faction,omitempty"`
+    Class    int    `query:"class" json:"class,omitempty"`
+    MinLevel int    `query:"min_level" json:"min_level,omitempty"`
+    MaxLevel int    `query:"max_level" json:"max_level,omitempty"`
+    Sort     string `query:"sort" json:"sort,omitempty"`
+    Order    string `query:"order" json:"order,omitempty"`
+    Page     int    `query:"page" json:"page,omitempty"`
+    PerPage  int    `query:"per_page" json:"per_page,omitempty"`
+}
+
+type OnlinePlayer struct {
+    GUID       int              `json:"guid"`
+    Name       string           `json:"name"`
+    Level      int              `json:"level"`
+    Race       int              `json:"race"`
+    RaceName   string           `json:"race_name"`
+    RaceIcon   string           `json:"race_icon"`
+    Class      int              `json:"class"`
+    ClassName  string           `json:"class_name"`
+    ClassIcon  string           `json:"class_icon"`
+    ClassColor string           `json:"class_color"`
+    Gender     string           `json:"gender"`
+    Faction    gamedata.Faction `json:"faction"`
+    Zone       int              `json:"zone"`
+    ZoneName   string           `json:"zone_name"`
+}
+
+type OnlinePlayersPage struct {
+    RealmID int            `json:"realm_id"`
+    Query   PlayerQuery    `json:"query"`
+    Players []OnlinePlayer `json:"players"`
+    Total   int            `json:"total"`
+    Page    int            `json:"page"`
+    PerPage int            `json:"per_page"`
+    Pages   int            `json:"pages"`
+}
+
+func (q *PlayerQuery) normalize() {
+    if q.Page < 1 {
+        q.Page = 1
+    }
+    if q.PerPage < 1 {
+        q.PerPage = 50
+    }
+    if q.PerPage > maxPlayersPerPage {
+        q.PerPage = maxPlayersPerPage
+    }
+    if q.Order != "asc" {
+        q.Order = "desc"
+    }
+    if q.Faction != string(gamedata.FactionAlliance) && q.Faction != string(gamedata.FactionHorde) {
+        q.Faction = ""
+    }
+}
+
+// NewOnlinePlayer добавляет к сырым ID из characters названия, иконки и фракцию
+func NewOnlinePlayer(c database.Character) OnlinePlayer {
+    return OnlinePlayer{
+        GUID:       c.GUID,
+        Name:       c.Name,
+        Level:      c.Level,
+        Race:       c.Race,
+        RaceName:   gamedata.RaceName(c.Race),
+        RaceIcon:   gamedata.RaceIcon(c.Race, c.Gender),
+        Class:      c.Class,
+        ClassName:  gamedata.ClassName(c.Class),
+        ClassIcon:  gamedata.ClassIcon(c.Class),
+        ClassColor: gamedata.ClassColor(c.Class),
+        Gender:     gamedata.GenderName(c.Gender),
+        Faction:    gamedata.RaceFaction(c.Race),
+        Zone:       c.Zone,
+        ZoneName:   gamedata.ZoneName(c.Zone),
+    }
+}
+
+func ListOnlinePlayers(ctx context.Context, realmID int, q PlayerQuery) (*OnlinePlayersPage, error) {
+    q.normalize()
+    
+    key := fmt.Sprintf("players:%d:%s:%d:%d:%d:%s:%s:%d:%d",
+        realmID, q.Faction, q.Class, q.MinLevel, q.MaxLevel, q.Sort, q.Order, q.Page, q.PerPage)
+    
+    return cache.GetOrLoad(ctx, key, playersTTL(), func(ctx context.Context) (*OnlinePlayersPage, error) {
+        filter := database.CharacterFilter{
+            Class:    q.Class,
+            MinLevel: q.MinLevel,
+            MaxLevel: q.MaxLevel,
+            Sort:     q.Sort,
+            Desc:     q.Order == "desc",
+            Limit:    q.PerPage,
+            Offset:   (q.Page - 1) * q.PerPage,
+        }
+        if q.Faction != "" {
+            filter.Races = gamedata.FactionRaces(gamedata.Faction(q.Faction), config.AppConfig.Game.Expansion)
+        }
+        
+        characters, total, err := database.ListOnlineCharacters(realmID, filter)
+        if err != nil {
+            return nil, err
+        }
+        
+        page := &OnlinePlayersPage{
+            RealmID: realmID,
+            Query:   q,
+            Players: make([]OnlinePlayer, 0, len(characters)),
+            Total:   total,
+            Page:    q.Page,
+            PerPage: q.PerPage,
+            Pages:   (total + q.PerPage - 1) / q.PerPage,
+        }
+        for _, c := range characters {
+            page.Players = append(page.Players, NewOnlinePlayer(c))
+        }
+        return page, nil
+    })
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "log"
+    "strings"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+const pointsPerPage = 25
+
+var (
+    ErrReasonRequired  = errors.New("a reason is required")
+    ErrInvalidAmount   = errors.New("amount must be a non-zero number")
+    ErrAccountNotFound = errors.New("account not found")
+)
+
+type PointsHistory struct {
+    Balance int                    `json:"balance"`
+    Entries []database.PointsEntry `json:"entries"`
+    Total   int                    `json:"total"`
+    Page    int                    `json:"page"`
+    PerPage int                    `json:"per_page"`
+    Pages   int                    `json:"pages"`
+}
+
+// GetPointsHistory — баланс и страница журнала поинтов аккаунта
+func GetPointsHistory(accountID, page int) (*PointsHistory, error) {
+    if page < 1 {
+        page = 1
+    }
+    
+    balance, err := database.GetPointsBalance(accountID)
+    if err != nil {
+        return nil, err
+    }
+    total, err := database.CountPointsEntries(accountID)
+    if err != nil {
+        return nil, err
+    }
+    entries, err := database.GetPointsEntries(accountID, pointsPerPage, (page-1)*pointsPerPage)
+    if err != nil {
+        return nil, err
+    }
+    if entries == nil {
+        entries = []database.PointsEntry{}
+    }
+    
+    return &PointsHistory{
+        Balance: balance,
+        Entries: entries,
+        Total:   total,
+        Page:    page,
+        PerPage: pointsPerPage,
+        Pages:   (total + pointsPerPage - 1) / pointsPerPage,
+    }, nil
+}
+
+// IsAdmin проверяет GM-уровень при каждом запросе, чтобы снятие прав
+// действовало сразу, а не после истечения сессии
+func IsAdmin(accountID int) bool {
+    level, err := database.GetGMLevel(accountID)
+    if err != nil {
+        log.Printf("admin check: account %d: %v", accountID, err)
+        return false
+    }
+    return level > 0 && level >= config.AppConfig.Security.AdminGMLevel
+}
+
+// AdminAdjustPoints начисляет (или списывает при отрицательном amount) поинты
+// аккаунту username от имени администратора. Причина обязательна и
+// сохраняется в журнале вместе с автором
+func AdminAdjustPoints(actor AdminActor, username string, amount int, reason string) (*database.PointsEntry, error) {
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return nil, ErrReasonRequired
+    }
+    reason = database.TruncateText(reason, 255)
+    if amount == 0 {
+        return nil, ErrInvalidAmount
+    }
+    
+    account, err := database.GetAccountByUsername(strings.ToUpper(strings.TrimSpace(username)))
+    if err == sql.ErrNoRows {
+        return nil, ErrAccountNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    
+    entry := &database.PointsEntry{
+        AccountID: account.ID,
+        Amount:    amount,
+        Kind:      database.PointsAdminGrant,
+        Reference: fmt.Sprintf("admin:%d", actor.AccountID),
+        Reason:    reason,
+        ActorID:   actor.AccountID,
+    }
+    if err := database.RecordPoints(entry); err != nil {
+        return nil, err
+    }
+    
+    log.Printf("points: admin %d adjusted %s by %d (%s)", actor.AccountID, account.Username, amount, reason)
+    RecordAudit(actor, AuditEvent{
+        Action:          AuditPointsAdjust,
+        TargetAccountID: account.ID,
+        Details:         map[string]interface{}{"amount": amount, "reason": reason},
+        Before:          map[string]interface{}{"balance": entry.BalanceAfter - amount},
+        After:           map[string]interface{}{"balance": entry.BalanceAfter},
+    })
+    return entry, nil
+}
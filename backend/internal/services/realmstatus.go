@@ -0,0 +1,291 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "net"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "github.com/redis/go-redis/v9"
+)
+
+const (
+    probeTimeout      = 3 * time.Second
+    statusHistoryTTL  = 31 * 24 * time.Hour
+    authServerProbeID = "auth"
+    uptimeTTL         = time.Minute
+)
+
+type ProbeStatus struct {
+    Up        bool      `json:"up"`
+    LatencyMs int64     `json:"latency_ms"`
+    CheckedAt time.Time `json:"checked_at"`
+    Since     time.Time `json:"since"`
+}
+
+type UptimeStats struct {
+    Day   float64 `json:"24h"`
+    Week  float64 `json:"7d"`
+    Month float64 `json:"30d"`
+}
+
+type RealmStatus struct {
+    RealmID int                   `json:"realm_id"`
+    Name    string                `json:"name"`
+    Address string                `json:"address"`
+    Status  *ProbeStatus          `json:"status"`
+    Uptime  UptimeStats           `json:"uptime"`
+    Core    *database.RealmUptime `json:"core,omitempty"`
+}
+
+type ServerStatus struct {
+    AuthServer *ProbeStatus  `json:"authserver"`
+    AuthUptime UptimeStats   `json:"authserver_uptime"`
+    Realms     []RealmStatus `json:"realms"`
+}
+
+// StartRealmProber раз в Monitoring.HealthCheckInterval проверяет TCP-порты
+// authserver и всех worldserver из realmlist и пишет результат в Redis.
+func StartRealmProber(ctx context.Context) {
+    interval := time.Duration(config.AppConfig.Monitoring.HealthCheckInterval) * time.Second
+    if interval <= 0 {
+        interval = 30 * time.Second
+    }
+    
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    
+    for {
+        probeAll(ctx)
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func probeAll(ctx context.Context) {
+    cfg := config.AppConfig
+    
+    authAddr := net.JoinHostPort(cfg.Game.RealmList, cfg.Game.AuthServerPort)
+    if err := recordProbe(ctx, authServerProbeID, "Login server", authAddr); err != nil {
+        log.Printf("realm prober: authserver: %v", err)
+    }
+    
+    for _, realm := range database.GetRealms() {
+        addr := net.JoinHostPort(realm.Address, strconv.Itoa(realm.Port))
+        if err := recordProbe(ctx, strconv.Itoa(realm.ID), realm.Name, addr); err != nil {
+            log.Printf("realm prober: realm %d: %v", realm.ID, err)
+        }
+    }
+}
+
+func probeTCP(addr string) (bool, time.Duration) {
+    start := time.Now()
+    conn, err := net.DialTimeout("tcp", addr, probeTimeout)
+    if err != nil {
+        return false, 0
+    }
+    conn.Close()
+    return true, time.Since(start)
+}
+
+func recordProbe(ctx context.Context, id, name, addr string) error {
+    up, latency := probeTCP(addr)
+    now := time.Now()
+    
+    previous, err := getProbeStatus(ctx, id)
+    if err != nil {
+        return err
+    }
+    
+    since := now
+    if previous != nil && previous.Up == up {
+        since = previous.Since
+    }
+    
+    upValue := 0
+    if up {
+        upValue = 1
+    }
+    
+    bucket := statusBucketKey(id, now)
+    pipe := database.Redis.TxPipeline()
+    pipe.HSet(ctx, statusKey(id),
+        "up", upValue,
+        "latency_ms", latency.Milliseconds(),
+        "checked_at", now.Unix(),
+        "since", since.Unix(),
+    )
+    pipe.HIncrBy(ctx, bucket, "total", 1)
+    pipe.HIncrBy(ctx, bucket, "up", int64(upValue))
+    pipe.Expire(ctx, bucket, statusHistoryTTL)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return err
+    }
+    
+    // О смене состояния сообщаем только после первой проверки, иначе каждый
+    // перезапуск сайта объявлял бы реалмы поднявшимися
+    if previous != nil && previous.Up != up {
+        notifyRealmState(id, name, up, now.Sub(previous.Since))
+    }
+    return nil
+}
+
+func notifyRealmState(id, name string, up bool, after time.Duration) {
+    lasted := strings.TrimSuffix(after.Round(time.Minute).String(), "0s")
+    if lasted == "" {
+        lasted = "under a minute"
+    }
+    if up {
+        Notify(Notification{
+            Kind:   NotifyRealmUp,
+            Title:  name + " is online",
+            Text:   "The server is back up.",
+            Fields: []NotificationField{{Name: "Downtime", Value: lasted}},
+        })
+        return
+    }
+    EmitWebhook(WebhookRealmDown, map[string]interface{}{
+        "server":         id,
+        "name":           name,
+        "uptime_seconds": int64(after / time.Second),
+    })
+    Notify(Notification{
+        Kind:   NotifyRealmDown,
+        Title:  name + " is offline",
+        Text:   "The server stopped responding.",
+        Fields: []NotificationField{{Name: "Uptime", Value: lasted}},
+    })
+}
+
+func statusKey(id string) string {
+    return "realmstatus:" + id
+}
+
+// Почасовые корзины: realmstatus:<id>:h:<YYYYMMDDHH> = {up, total}
+func statusBucketKey(id string, t time.Time) string {
+    return fmt.Sprintf("realmstatus:%s:h:%s", id, t.UTC().Format("2006010215"))
+}
+
+func getProbeStatus(ctx context.Context, id string) (*ProbeStatus, error) {
+    values, err := database.Redis.HGetAll(ctx, statusKey(id)).Result()
+    if err != nil {
+        return nil, err
+    }
+    if len(values) == 0 {
+        return nil, nil
+    }
+    
+    latency, _ := strconv.ParseInt(values["latency_ms"], 10, 64)
+    checkedAt, _ := strconv.ParseInt(values["checked_at"], 10, 64)
+    since, _ := strconv.ParseInt(values["since"], 10, 64)
+    
+    return &ProbeStatus{
+        Up:        values["up"] == "1",
+        LatencyMs: latency,
+        CheckedAt: time.Unix(checkedAt, 0),
+        Since:     time.Unix(since, 0),
+    }, nil
+}
+
+// uptimeStats считает долю успешных проверок за 24 часа, 7 и 30 дней. Все три
+// окна собираются за один проход по 720 почасовым корзинам, а результат
+// кэшируется на минуту: статус запрашивает каждый посетитель главной
+func uptimeStats(ctx context.Context, id string) (UptimeStats, error) {
+    return cache.GetOrLoad(ctx, "uptime:"+id, uptimeTTL, func(ctx context.Context) (UptimeStats, error) {
+        return loadUptimeStats(ctx, id)
+    })
+}
+
+func loadUptimeStats(ctx context.Context, id string) (UptimeStats, error) {
+    const hours = 30 * 24
+    
+    now := time.Now()
+    pipe := database.Redis.Pipeline()
+    cmds := make([]*redis.SliceCmd, 0, hours)
+    for i := 0; i < hours; i++ {
+        key := statusBucketKey(id, now.Add(-time.Duration(i)*time.Hour))
+        cmds = append(cmds, pipe.HMGet(ctx, key, "up", "total"))
+    }
+    if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+        return UptimeStats{}, err
+    }
+    
+    // Окна вложены друг в друга: 24 часа, неделя, месяц
+    windows := [3]int{24, 7 * 24, hours}
+    var up, total [3]int64
+    for i, cmd := range cmds {
+        values := cmd.Val()
+        if len(values) != 2 || values[1] == nil {
+            continue
+        }
+        u, _ := strconv.ParseInt(fmt.Sprint(values[0]), 10, 64)
+        t, _ := strconv.ParseInt(fmt.Sprint(values[1]), 10, 64)
+        for w, limit := range windows {
+            if i < limit {
+                up[w] += u
+                total[w] += t
+            }
+        }
+    }
+    
+    percent := func(w int) float64 {
+        if total[w] == 0 {
+            return 0
+        }
+        return float64(up[w]) * 100 / float64(total[w])
+    }
+    return UptimeStats{Day: percent(0), Week: percent(1), Month: percent(2)}, nil
+}
+
+func GetRealmStatus(ctx context.Context, realm database.Realm) (RealmStatus, error) {
+    id := strconv.Itoa(realm.ID)
+    status := RealmStatus{
+        RealmID: realm.ID,
+        Name:    realm.Name,
+        Address: net.JoinHostPort(realm.Address, strconv.Itoa(realm.Port)),
+    }
+    
+    var err error
+    if status.Status, err = getProbeStatus(ctx, id); err != nil {
+        return status, err
+    }
+    if status.Uptime, err = uptimeStats(ctx, id); err != nil {
+        return status, err
+    }
+    if status.Core, err = database.GetRealmUptime(realm.ID); err != nil {
+        return status, err
+    }
+    
+    return status, nil
+}
+
+func GetServerStatus(ctx context.Context) (*ServerStatus, error) {
+    result := &ServerStatus{}
+    
+    var err error
+    if result.AuthServer, err = getProbeStatus(ctx, authServerProbeID); err != nil {
+        return nil, err
+    }
+    if result.AuthUptime, err = uptimeStats(ctx, authServerProbeID); err != nil {
+        return nil, err
+    }
+    
+    for _, realm := range database.GetRealms() {
+        status, err := GetRealmStatus(ctx, realm)
+        if err != nil {
+            return nil, err
+        }
+        result.Realms = append(result.Realms, status)
+    }
+    
+    return result, nil
+}
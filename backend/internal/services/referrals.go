@@ -0,0 +1,293 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+const (
+    referralCheckInterval = 10 * time.Minute
+    referralBatchSize     = 200
+    referralCodeLength    = 8
+)
+
+// Причины отклонения приглашения
+const (
+    referralSameIP    = "shared ip"
+    referralSameEmail = "shared email"
+    referralLimit     = "referral limit reached"
+)
+
+type ReferralEntry struct {
+    Username   string     `json:"username"`
+    Status     string     `json:"status"`
+    Reason     string     `json:"reason,omitempty"`
+    Level      int        `json:"level"`
+    PlayedSecs int64      `json:"played"`
+    JoinedAt   time.Time  `json:"joined_at"`
+    RewardedAt *time.Time `json:"rewarded_at,omitempty"`
+}
+
+type ReferralDashboard struct {
+    Code           string          `json:"code"`
+    Link           string          `json:"link"`
+    Reward         int             `json:"reward"`
+    MilestoneLevel int             `json:"milestone_level"`
+    MilestoneHours int             `json:"milestone_hours"`
+    MaxReferrals   int             `json:"max_referrals"`
+    Referrals      []ReferralEntry `json:"referrals"`
+}
+
+func referralReward() int {
+    reward, _ := strconv.Atoi(strings.TrimSpace(config.AppConfig.Custom.ReferralReward))
+    return reward
+}
+
+// ReferralCode возвращает код аккаунта, при первом обращении создает его
+func ReferralCode(accountID int) (string, error) {
+    code, err := database.GetReferralCode(accountID)
+    if err != sql.ErrNoRows {
+        return code, err
+    }
+    
+    for attempt := 0; attempt < 5; attempt++ {
+        code = strings.ToUpper(GenerateRandomString(referralCodeLength))
+        created, err := database.CreateReferralCode(accountID, code)
+        if err != nil {
+            return "", err
+        }
+        if created {
+            return code, nil
+        }
+        // Либо занят код, либо параллельный запрос уже создал код этому аккаунту
+        if existing, err := database.GetReferralCode(accountID); err == nil {
+            return existing, nil
+        }
+    }
+    return "", fmt.Errorf("failed to generate a unique referral code")
+}
+
+// RecordReferral привязывает новый аккаунт к пригласившему. Общий IP или почта
+// сразу помечают приглашение отклоненным, чтобы нельзя было позвать самого себя
+func RecordReferral(code string, refereeID int, email, ip string) error {
+    cfg := config.AppConfig.Custom
+    if !cfg.ReferralSystemEnabled || code == "" {
+        return nil
+    }
+    
+    referrerID, err := database.GetReferrerByCode(strings.ToUpper(strings.TrimSpace(code)))
+    if err == sql.ErrNoRows {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    if referrerID == refereeID {
+        return nil
+    }
+    
+    referral := &database.Referral{
+        RefereeID:  refereeID,
+        ReferrerID: referrerID,
+        Status:     database.ReferralPending,
+        IP:         ip,
+    }
+    
+    reason, err := referralAbuse(referrerID, email, map[string]bool{ip: true})
+    if err != nil {
+        return err
+    }
+    if reason == "" && cfg.MaxReferralsPerAccount > 0 {
+        count, err := database.CountReferrals(referrerID)
+        if err != nil {
+            return err
+        }
+        if count >= cfg.MaxReferralsPerAccount {
+            reason = referralLimit
+        }
+    }
+    if reason != "" {
+        referral.Status = database.ReferralRejected
+        referral.Reason = reason
+    }
+    
+    return database.CreateReferral(referral)
+}
+
+// referralAbuse — причина отказа, если у пригласившего та же почта или один из тех же IP
+func referralAbuse(referrerID int, refereeEmail string, refereeIPs map[string]bool) (string, error) {
+    referrerEmail, err := database.GetAccountEmail(referrerID)
+    if err != nil {
+        return "", err
+    }
+    if refereeEmail != "" && strings.EqualFold(referrerEmail, refereeEmail) {
+        return referralSameEmail, nil
+    }
+    
+    referrerIPs, err := database.GetAccountIPs(referrerID)
+    if err != nil {
+        return "", err
+    }
+    for ip := range refereeIPs {
+        if referrerIPs[ip] {
+            return referralSameIP, nil
+        }
+    }
+    return "", nil
+}
+
+// accountProgress — лучший уровень и суммарное /played аккаунта по всем реалмам
+func accountProgress(accountID int) (int, int64) {
+    var best int
+    var played int64
+    for _, realm := range database.GetRealms() {
+        level, seconds, err := database.GetAccountProgress(realm.ID, accountID)
+        if err != nil {
+            continue
+        }
+        if level > best {
+            best = level
+        }
+        played += seconds
+    }
+    return best, played
+}
+
+func referralMilestoneReached(level int, played int64) bool {
+    cfg := config.AppConfig.Custom
+    if cfg.ReferralMilestoneLevel > 0 && level >= cfg.ReferralMilestoneLevel {
+        return true
+    }
+    return cfg.ReferralMilestoneHours > 0 && played >= int64(cfg.ReferralMilestoneHours)*3600
+}
+
+// StartReferralChecker периодически проверяет, не достигли ли приглашенные цели
+func StartReferralChecker(ctx context.Context) {
+    if !config.AppConfig.Custom.ReferralSystemEnabled {
+        return
+    }
+    
+    ticker := time.NewTicker(referralCheckInterval)
+    defer ticker.Stop()
+    
+    for {
+        checkReferrals()
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func checkReferrals() {
+    referrals, err := database.GetPendingReferrals(referralBatchSize)
+    if err != nil {
+        log.Printf("referrals: %v", err)
+        return
+    }
+    
+    // Проверенная пачка уходит в конец очереди, иначе первые referralBatchSize
+    // приглашений, не дошедших до цели, проверялись бы вечно. Половина
+    // интервала — чтобы к следующему тику строки уже снова были в очереди
+    ids := make([]int, len(referrals))
+    for i, r := range referrals {
+        ids[i] = r.RefereeID
+    }
+    if err := database.PostponeReferrals(ids, referralCheckInterval/2); err != nil {
+        log.Printf("referrals: %v", err)
+        return
+    }
+    
+    for _, r := range referrals {
+        if !referralMilestoneReached(accountProgress(r.RefereeID)) {
+            continue
+        }
+        if err := rewardReferral(r); err != nil {
+            log.Printf("referrals: account %d: %v", r.RefereeID, err)
+        }
+    }
+}
+
+// rewardReferral перед выдачей еще раз сверяет IP: за время игры у приглашенного
+// могла накопиться история входов с адресов пригласившего
+func rewardReferral(r database.Referral) error {
+    refereeIPs, err := database.GetAccountIPs(r.RefereeID)
+    if err != nil {
+        return err
+    }
+    if r.IP != "" {
+        refereeIPs[r.IP] = true
+    }
+    refereeEmail, err := database.GetAccountEmail(r.RefereeID)
+    if err != nil {
+        return err
+    }
+    
+    reason, err := referralAbuse(r.ReferrerID, refereeEmail, refereeIPs)
+    if err != nil {
+        return err
+    }
+    if reason != "" {
+        _, err := database.FinishReferral(r.RefereeID, database.ReferralRejected, reason, nil)
+        return err
+    }
+    
+    var points *database.PointsEntry
+    if reward := referralReward(); reward > 0 {
+        points = &database.PointsEntry{
+            AccountID: r.ReferrerID,
+            Amount:    reward,
+            Kind:      database.PointsReferral,
+            Reference: fmt.Sprintf("referral:%d", r.RefereeID),
+        }
+    }
+    _, err = database.FinishReferral(r.RefereeID, database.ReferralRewarded, "", points)
+    return err
+}
+
+func GetReferralDashboard(accountID int) (*ReferralDashboard, error) {
+    code, err := ReferralCode(accountID)
+    if err != nil {
+        return nil, err
+    }
+    referrals, err := database.GetReferralsByReferrer(accountID)
+    if err != nil {
+        return nil, err
+    }
+    
+    cfg := config.AppConfig.Custom
+    dashboard := &ReferralDashboard{
+        Code:           code,
+        Link:           strings.TrimRight(config.AppConfig.Server.BaseURL, "/") + "/?ref=" + code,
+        Reward:         referralReward(),
+        MilestoneLevel: cfg.ReferralMilestoneLevel,
+        MilestoneHours: cfg.ReferralMilestoneHours,
+        MaxReferrals:   cfg.MaxReferralsPerAccount,
+        Referrals:      []ReferralEntry{},
+    }
+    for _, r := range referrals {
+        entry := ReferralEntry{
+            Username: r.RefereeName,
+            Status:   r.Status,
+            Reason:   r.Reason,
+            JoinedAt: r.CreatedAt,
+        }
+        if r.Status == database.ReferralPending {
+            entry.Level, entry.PlayedSecs = accountProgress(r.RefereeID)
+        }
+        if r.RewardedAt.Valid {
+            entry.RewardedAt = &r.RewardedAt.Time
+        }
+        dashboard.Referrals = append(dashboard.Referrals, entry)
+    }
+    return dashboard, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "github.com/redis/go-redis/v9"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type Session struct {
+    Token     string    `json:"token"`
+    AccountID int       `json:"account_id"`
+    Username  string    `json:"username"`
+    IP        string    `json:"ip"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+func SessionLifetime() time.Duration {
+    hours := config.AppConfig.Server.SessionLifetime
+    if hours <= 0 {
+        hours = 168
+    }
+    return time.Duration(hours) * time.Hour
+}
+
+func sessionKey(token string) string {
+    return "session:" + token
+}
+
+func accountSessionsKey(accountID int) string {
+    return fmt.Sprintf("account_sessions:%d", accountID)
+}
+
+// CreateSession заводит веб-сессию в Redis; токены аккаунта собираются в set,
+// чтобы при смене пароля можно было разлогинить все устройства
+func CreateSession(ctx context.Context, account *database.Account, ip string) (*Session, error) {
+    session := &Session{
+        Token:     GenerateSessionToken(),
+        AccountID: account.ID,
+        Username:  account.Username,
+        IP:        ip,
+        CreatedAt: time.Now(),
+    }
+    
+    data, err := json.Marshal(session)
+    if err != nil {
+        return nil, err
+    }
+    
+    lifetime := SessionLifetime()
+    pipe := database.Redis.TxPipeline()
+    pipe.Set(ctx, sessionKey(session.Token), data, lifetime)
+    pipe.SAdd(ctx, accountSessionsKey(account.ID), session.Token)
+    pipe.Expire(ctx, accountSessionsKey(account.ID), lifetime)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return nil, err
+    }
+    
+    return session, nil
+}
+
+func GetSession(ctx context.Context, token string) (*Session, error) {
+    if token == "" {
+        return nil, ErrSessionNotFound
+    }
+    
+    data, err := database.Redis.Get(ctx, sessionKey(token)).Bytes()
+    if errors.Is(err, redis.Nil) {
+        return nil, ErrSessionNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    
+    var session Session
+    if err := json.Unmarshal(data, &session); err != nil {
+        return nil, err
+    }
+    return &session, nil
+}
+
+func DeleteSession(ctx context.Context, session *Session) error {
+    pipe := database.Redis.TxPipeline()
+    pipe.Del(ctx, sessionKey(session.Token))
+    pipe.SRem(ctx, accountSessionsKey(session.AccountID), session.Token)
+    _, err := pipe.Exec(ctx)
+    return err
+}
+
+// DeleteAccountSessions завершает все веб-сессии аккаунта
+func DeleteAccountSessions(ctx context.Context, accountID int) error {
+    tokens, err := database.Redis.SMembers(ctx, accountSessionsKey(accountID)).Result()
+    if err != nil {
+        return err
+    }
+    
+    keys := []string{accountSessionsKey(accountID)}
+    for _, token := range tokens {
+        keys = append(keys, sessionKey(token))
+    }
+    return database.Redis.Del(ctx, keys...).Err()
+}
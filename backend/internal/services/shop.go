@@ -0,0 +1,434 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/gamedata"
+    "github.com/google/uuid"
+)
+
+const (
+    shopInterval     = time.Minute
+    shopBatchSize    = 50
+    shopMaxAttempts  = 5
+    shopStaleAfter   = 10 * time.Minute
+    shopRecentOrders = 20
+)
+
+var (
+    ErrShopDisabled     = errors.New("the shop is disabled")
+    ErrUnknownProduct   = errors.New("unknown product")
+    ErrInvalidProduct   = errors.New("invalid product")
+    ErrLevelTooHigh     = errors.New("this character is already at or above that level")
+    ErrOrderKeyRequired = errors.New("missing purchase key, reload the page")
+)
+
+// Услуги, которые можно продавать: ставят флаг на следующий вход в игру
+var shopServices = []string{"rename", "customize", "faction", "race"}
+
+// PurchaseResult — итог покупки. Duplicate — запрос с уже использованным
+// ключом, вернулся существующий заказ
+type PurchaseResult struct {
+    Order     *database.ShopOrder `json:"order"`
+    Duplicate bool                `json:"duplicate"`
+}
+
+// MaxBoostLevel — потолок уровня для товаров level
+func MaxBoostLevel() int {
+    limit := gamedata.MaxLevel(config.AppConfig.Game.Expansion)
+    if boost := config.AppConfig.Custom.ShopMaxBoostLevel; boost > 0 && boost < limit {
+        return boost
+    }
+    return limit
+}
+
+// ValidateProduct проверяет товар перед сохранением из админки; для предметов
+// заодно сверяется с world.item_template
+func ValidateProduct(p *database.ShopProduct) error {
+    p.Name = strings.TrimSpace(p.Name)
+    p.Payload = strings.TrimSpace(p.Payload)
+    if p.Name == "" || len(p.Name) > 64 || len(p.Description) > 255 {
+        return fmt.Errorf("%w: name is required (up to 64 characters), description up to 255", ErrInvalidProduct)
+    }
+    if p.Price <= 0 {
+        return fmt.Errorf("%w: price must be positive", ErrInvalidProduct)
+    }
+    
+    switch p.Kind {
+    case database.ProductItem:
+        items, err := ParseItemList(p.Payload)
+        if err != nil {
+            return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+        }
+        if len(items) == 0 || len(items) > database.MailMaxItems {
+            return fmt.Errorf("%w: between 1 and %d item stacks", ErrInvalidProduct, database.MailMaxItems)
+        }
+        if err := ValidateItems(items); err != nil {
+            return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+        }
+    case database.ProductGold:
+        if gold, err := strconv.Atoi(p.Payload); err != nil || gold <= 0 {
+            return fmt.Errorf("%w: gold amount must be a positive number", ErrInvalidProduct)
+        }
+    case database.ProductService:
+        if _, err := shopService(p.Payload); err != nil {
+            return fmt.Errorf("%w: service must be one of %s", ErrInvalidProduct, strings.Join(shopServices, ", "))
+        }
+    case database.ProductLevel:
+        if level, err := strconv.Atoi(p.Payload); err != nil || level < 2 || level > MaxBoostLevel() {
+            return fmt.Errorf("%w: level must be between 2 and %d", ErrInvalidProduct, MaxBoostLevel())
+        }
+    default:
+        return fmt.Errorf("%w: unknown kind %q", ErrInvalidProduct, p.Kind)
+    }
+    return nil
+}
+
+func shopService(slug string) (CharacterTool, error) {
+    for _, allowed := range shopServices {
+        if allowed != slug {
+            continue
+        }
+        for _, tool := range characterTools {
+            if tool.Slug == slug && config.AppConfig.Game.Expansion >= tool.MinExpansion {
+                return tool, nil
+            }
+        }
+    }
+    return CharacterTool{}, ErrUnknownTool
+}
+
+// SaveProduct — создание или правка товара из админки
+func SaveProduct(p *database.ShopProduct) error {
+    if err := ValidateProduct(p); err != nil {
+        return err
+    }
+    err := database.SaveShopProduct(p)
+    if err == sql.ErrNoRows {
+        return ErrUnknownProduct
+    }
+    return err
+}
+
+// ShopOverview — витрина и последние заказы аккаунта
+func ShopOverview(accountID int) ([]database.ShopProduct, []database.ShopOrder, error) {
+    products, err := database.GetShopProducts(true)
+    if err != nil {
+        return nil, nil, err
+    }
+    orders, err := database.GetAccountShopOrders(accountID, shopRecentOrders)
+    if err != nil {
+        return nil, nil, err
+    }
+    return products, orders, nil
+}
+
+// NewPurchaseKey — ключ идемпотентности для формы покупки; меняется после
+// каждой завершенной попытки
+func NewPurchaseKey() string {
+    return uuid.New().String()
+}
+
+// Purchase списывает поинты и сразу пробует доставить товар. Ключ делает
+// покупку идемпотентной: повтор с тем же ключом вернет уже созданный заказ.
+// Если worldserver недоступен, заказ остается в очереди и доставляется позже,
+// а после shopMaxAttempts неудачных попыток поинты возвращаются
+func Purchase(ctx context.Context, accountID, productID, realmID, guid int, key string) (*PurchaseResult, error) {
+    if !config.AppConfig.Custom.ShopEnabled {
+        return nil, ErrShopDisabled
+    }
+    key = strings.TrimSpace(key)
+    if key == "" || len(key) > 64 {
+        return nil, ErrOrderKeyRequired
+    }
+    
+    if existing, err := database.GetShopOrderByKey(accountID, key); err == nil {
+        return &PurchaseResult{Order: existing, Duplicate: true}, nil
+    } else if err != sql.ErrNoRows {
+        return nil, err
+    }
+    
+    product, err := database.GetShopProduct(productID)
+    if err == sql.ErrNoRows || (err == nil && !product.Enabled) {
+        return nil, ErrUnknownProduct
+    }
+    if err != nil {
+        return nil, err
+    }
+    if _, err := realmSOAP(realmID); err != nil {
+        return nil, err
+    }
+    
+    owner, name, err := database.GetCharacterOwner(realmID, guid)
+    if err == sql.ErrNoRows || (err == nil && owner != accountID) {
+        return nil, ErrNotYourCharacter
+    }
+    if err != nil {
+        return nil, err
+    }
+    if product.Kind == database.ProductLevel {
+        if err := checkBoostLevel(realmID, guid, product.Payload); err != nil {
+            return nil, err
+        }
+    }
+    
+    order := &database.ShopOrder{
+        AccountID:      accountID,
+        IdempotencyKey: key,
+        ProductID:      product.ID,
+        Kind:           product.Kind,
+        Name:           product.Name,
+        Payload:        product.Payload,
+        Price:          product.Price,
+        RealmID:        realmID,
+        GUID:           guid,
+        CharacterName:  name,
+    }
+    created, err := database.CreateShopOrder(order)
+    if err != nil {
+        return nil, err
+    }
+    if !created {
+        // Параллельный запрос с тем же ключом успел раньше
+        existing, err := database.GetShopOrderByKey(accountID, key)
+        if err != nil {
+            return nil, err
+        }
+        return &PurchaseResult{Order: existing, Duplicate: true}, nil
+    }
+    
+    paid, err := database.PayShopOrder(order.ID, &database.PointsEntry{
+        AccountID: accountID,
+        Amount:    -order.Price,
+        Kind:      database.PointsPurchase,
+        Reference: orderReference(order),
+    })
+    if err != nil {
+        database.SetShopOrderStatus(order.ID, database.OrderPending, database.OrderFailed, err.Error())
+        return nil, err
+    }
+    if !paid {
+        // Заказ успел разобрать recoverShopOrders — показываем его как есть
+        existing, err := database.GetShopOrderByKey(accountID, key)
+        if err != nil {
+            return nil, err
+        }
+        return &PurchaseResult{Order: existing}, nil
+    }
+    order.Status = database.OrderPaid
+    
+    deliverOrder(ctx, order)
+    return &PurchaseResult{Order: order}, nil
+}
+
+func orderReference(o *database.ShopOrder) string {
+    return "shop:" + strconv.FormatInt(o.ID, 10)
+}
+
+func checkBoostLevel(realmID, guid int, payload string) error {
+    target, _ := strconv.Atoi(payload)
+    if target > MaxBoostLevel() {
+        return ErrInvalidProduct
+    }
+    level, err := database.GetCharacterLevel(realmID, guid)
+    if err != nil {
+        return err
+    }
+    if level >= target {
+        return ErrLevelTooHigh
+    }
+    return nil
+}
+
+func orderCommand(o *database.ShopOrder) (string, error) {
+    subject := "Shop: " + strings.ReplaceAll(o.Name, `"`, "")
+    text := "Thank you for your purchase!"
+    
+    switch o.Kind {
+    case database.ProductItem:
+        items, err := ParseItemList(o.Payload)
+        if err != nil {
+            return "", fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+        }
+        return fmt.Sprintf(`send items %s "%s" "%s" %s`, o.CharacterName, subject, text, formatItemList(items)), nil
+    case database.ProductGold:
+        gold, err := strconv.Atoi(o.Payload)
+        if err != nil {
+            return "", fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+        }
+        return fmt.Sprintf(`send money %s "%s" "%s" %d`, o.CharacterName, subject, text, gold*10000), nil
+    case database.ProductService:
+        tool, err := shopService(o.Payload)
+        if err != nil {
+            return "", err
+        }
+        return fmt.Sprintf(tool.command, o.CharacterName), nil
+    case database.ProductLevel:
+        return fmt.Sprintf("character level %s %s", o.CharacterName, o.Payload), nil
+    }
+    return "", ErrInvalidProduct
+}
+
+// deliverOrder — одна попытка доставки оплаченного заказа
+func deliverOrder(ctx context.Context, o *database.ShopOrder) {
+    claimed, err := database.ClaimShopOrder(o.ID)
+    if err != nil || !claimed {
+        return
+    }
+    o.Attempts++
+    o.Status = database.OrderSending
+    
+    // Персонаж мог за это время сменить владельца, удалиться или дорасти до уровня
+    owner, name, err := database.GetCharacterOwner(o.RealmID, o.GUID)
+    if err == sql.ErrNoRows || (err == nil && owner != o.AccountID) {
+        refundOrder(o, ErrNotYourCharacter.Error())
+        return
+    }
+    if err == nil && o.Kind == database.ProductLevel {
+        err = checkBoostLevel(o.RealmID, o.GUID, o.Payload)
+    }
+    
+    // Имя берем свежее: после переименования старое уже не сработает
+    var command string
+    if err == nil {
+        o.CharacterName = name
+        command, err = orderCommand(o)
+    }
+    switch {
+    case errors.Is(err, ErrLevelTooHigh), errors.Is(err, ErrInvalidProduct), errors.Is(err, ErrUnknownTool):
+        // Товар больше нельзя выдать этому персонажу, повтор не поможет
+        refundOrder(o, err.Error())
+        return
+    case err != nil:
+        retryOrder(o, err)
+        return
+    }
+    
+    client, err := realmSOAP(o.RealmID)
+    if err != nil {
+        retryOrder(o, err)
+        return
+    }
+    
+    if _, err := client.Execute(ctx, command); err != nil {
+        if deliveryNotAttempted(err) {
+            retryOrder(o, err)
+            return
+        }
+        // Неизвестно, выполнил ли worldserver команду: повтор может выдать
+        // товар дважды, возврат — отдать его бесплатно. Решает администратор
+        log.Printf("shop: order %d needs review: %v", o.ID, err)
+        database.SetShopOrderStatus(o.ID, database.OrderSending, database.OrderReview, err.Error())
+        o.Status = database.OrderReview
+        return
+    }
+    
+    if done, _ := database.SetShopOrderStatus(o.ID, database.OrderSending, database.OrderDone, ""); done {
+        EmitWebhook(WebhookPurchaseCompleted, map[string]interface{}{
+            "order_id":   o.ID,
+            "account_id": o.AccountID,
+            "product_id": o.ProductID,
+            "kind":       o.Kind,
+            "name":       o.Name,
+            "price":      o.Price,
+            "realm_id":   o.RealmID,
+            "character":  o.CharacterName,
+        })
+    }
+    o.Status = database.OrderDone
+}
+
+func retryOrder(o *database.ShopOrder, cause error) {
+    if o.Attempts >= shopMaxAttempts {
+        refundOrder(o, cause.Error())
+        return
+    }
+    
+    // 1, 2, 4, 8… минут между попытками
+    delay := time.Minute << (o.Attempts - 1)
+    if err := database.RetryShopOrder(o.ID, delay, cause.Error()); err != nil {
+        log.Printf("shop: order %d: %v", o.ID, err)
+        return
+    }
+    o.Status = database.OrderPaid
+}
+
+// refundOrder возвращает поинты вместе со сменой статуса. Если возврат не
+// прошел, заказ остается в sending и recoverShopOrders отдаст его на разбор
+func refundOrder(o *database.ShopOrder, reason string) {
+    points := &database.PointsEntry{AccountID: o.AccountID, Amount: o.Price, Kind: database.PointsRefund, Reference: orderReference(o)}
+    refunded, err := database.RefundShopOrder(o.ID, reason, points)
+    if err != nil {
+        log.Printf("shop: order %d: refund of %d points failed: %v", o.ID, o.Price, err)
+        return
+    }
+    if refunded {
+        o.Status = database.OrderRefunded
+    }
+}
+
+// StartShopDelivery повторяет доставку заказов, которые не удалось выдать сразу,
+// и разбирает заказы, брошенные на полпути упавшим процессом
+func StartShopDelivery(ctx context.Context) {
+    if !config.AppConfig.Custom.ShopEnabled {
+        return
+    }
+    
+    ticker := time.NewTicker(shopInterval)
+    defer ticker.Stop()
+    
+    for {
+        recoverShopOrders()
+        
+        orders, err := database.GetDueShopOrders(shopBatchSize)
+        if err != nil {
+            log.Printf("shop: %v", err)
+        }
+        for i := range orders {
+            deliverOrder(ctx, &orders[i])
+        }
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func recoverShopOrders() {
+    // pending: упали между созданием заказа и списанием — смотрим по журналу
+    pending, err := database.GetStaleShopOrders(database.OrderPending, shopStaleAfter, shopBatchSize)
+    if err != nil {
+        log.Printf("shop: %v", err)
+    }
+    for _, o := range pending {
+        charged, err := database.HasPointsEntry(database.PointsPurchase, orderReference(&o))
+        if err != nil {
+            continue
+        }
+        if charged {
+            database.MarkShopOrderPaid(o.ID)
+        } else {
+            database.SetShopOrderStatus(o.ID, database.OrderPending, database.OrderFailed, "interrupted before payment")
+        }
+    }
+    
+    // sending: упали во время отправки команды, результат неизвестен
+    sending, err := database.GetStaleShopOrders(database.OrderSending, shopStaleAfter, shopBatchSize)
+    if err != nil {
+        log.Printf("shop: %v", err)
+    }
+    for _, o := range sending {
+        log.Printf("shop: order %d needs review: interrupted during delivery", o.ID)
+        database.SetShopOrderStatus(o.ID, database.OrderSending, database.OrderReview, "interrupted during delivery")
+    }
+}
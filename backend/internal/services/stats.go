@@ -0,0 +1,39 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "time"
+    "wow-registration/internal/cache"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+// Кэшированные обертки над запросами статистики: страница обновляется
+// каждые 15–30 секунд у каждого посетителя, а COUNT(*) по account недешев.
+
+func statsTTL() time.Duration {
+    return time.Duration(config.AppConfig.Cache.StatsDuration) * time.Second
+}
+
+func playersTTL() time.Duration {
+    return time.Duration(config.AppConfig.Cache.PlayersDuration) * time.Second
+}
+
+func GetServerStats(ctx context.Context, realmID int) (map[string]interface{}, error) {
+    return cache.GetOrLoad(ctx, fmt.Sprintf("stats:%d", realmID), statsTTL(), func(ctx context.Context) (map[string]interface{}, error) {
+        return database.GetServerStats(realmID)
+    })
+}
+
+func GetOnlinePlayers(ctx context.Context, realmID int) ([]database.Character, error) {
+    return cache.GetOrLoad(ctx, fmt.Sprintf("players:%d", realmID), playersTTL(), func(ctx context.Context) ([]database.Character, error) {
+        return database.GetOnlinePlayers(realmID)
+    })
+}
+
+func CountOnlinePlayers(ctx context.Context, realmID int) (int, error) {
+    return cache.GetOrLoad(ctx, fmt.Sprintf("online:%d", realmID), playersTTL(), func(ctx context.Context) (int, error) {
+        return database.CountOnlinePlayers(realmID)
+    })
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "html"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/telegram"
+)
+
+const telegramCommandTimeout = 15 * time.Second
+
+const telegramHelp = `<b>Commands</b>
+/status — authserver and realm state
+/online — players online per realm
+/stats — accounts and registrations
+/lookup &lt;account&gt; — account details
+/ban &lt;account&gt; &lt;30m|12h|7d|perm&gt; &lt;reason&gt; — GM only
+/unban &lt;account&gt; — GM only`
+
+// startTelegram подключает бота: алерты в чат персонала и команды. Команды
+// принимаются только из этого чата и из личных сообщений от GM из
+// TELEGRAM_ADMIN_IDS; /ban и /unban — только от GM
+func startTelegram(ctx context.Context) {
+    cfg := config.AppConfig.Integrations
+    
+    staffChat, err := strconv.ParseInt(cfg.TelegramChatID, 10, 64)
+    if err != nil {
+        log.Printf("telegram disabled: invalid TELEGRAM_CHAT_ID %q", cfg.TelegramChatID)
+        return
+    }
+    
+    bot := telegram.NewBot(cfg.TelegramBotToken, cfg.TelegramAPIBase, cfg.TelegramQueueSize)
+    go bot.RunSender(ctx)
+    addNotifySink(telegramSink(bot, staffChat))
+    
+    if cfg.TelegramCommands {
+        go bot.Poll(ctx, func(update telegram.Update) {
+            handleTelegramUpdate(ctx, bot, staffChat, update)
+        })
+    }
+}
+
+func telegramSink(bot *telegram.Bot, chatID int64) func(Notification) {
+    return func(n Notification) {
+        if !telegramAlertEnabled(n.Kind) {
+            return
+        }
+        
+        var text strings.Builder
+        fmt.Fprintf(&text, "<b>%s</b>", html.EscapeString(n.Title))
+        if n.Text != "" {
+            text.WriteString("\n" + html.EscapeString(n.Text))
+        }
+        for _, f := range n.Fields {
+            fmt.Fprintf(&text, "\n%s: %s", html.EscapeString(f.Name), html.EscapeString(f.Value))
+        }
+        
+        if err := bot.Enqueue(telegram.OutgoingMessage{ChatID: chatID, Text: text.String(), ParseMode: "HTML"}); err != nil {
+            log.Printf("telegram: dropped %s notification: %v", n.Kind, err)
+        }
+    }
+}
+
+func telegramAlertEnabled(kind string) bool {
+    for _, k := range config.AppConfig.Integrations.TelegramAlerts {
+        if strings.TrimSpace(k) == kind {
+            return true
+        }
+    }
+    return false
+}
+
+func isTelegramAdmin(userID int64) bool {
+    for _, id := range config.AppConfig.Integrations.TelegramAdminIDs {
+        if id == userID {
+            return true
+        }
+    }
+    return false
+}
+
+func handleTelegramUpdate(ctx context.Context, bot *telegram.Bot, staffChat int64, update telegram.Update) {
+    msg := update.Message
+    if msg == nil || msg.From == nil {
+        return
+    }
+    command, args := telegram.Command(msg.Text)
+    if command == "" {
+        return
+    }
+    
+    admin := isTelegramAdmin(msg.From.ID)
+    if msg.Chat.ID != staffChat && !(admin && msg.Chat.Type == "private") {
+        return
+    }
+    
+    ctx, cancel := context.WithTimeout(ctx, telegramCommandTimeout)
+    defer cancel()
+    
+    actor := AdminActor{Name: "telegram:" + telegram.ChatIDString(msg.From.ID)}
+    if msg.From.Username != "" {
+        actor.Name += " @" + msg.From.Username
+    }
+    
+    var reply string
+    var err error
+    switch command {
+    case "start", "help":
+        reply = telegramHelp
+    case "status":
+        reply, err = telegramStatus(ctx)
+    case "online":
+        reply, err = telegramOnline(ctx)
+    case "stats":
+        reply, err = telegramStats(ctx)
+    case "lookup":
+        reply, err = telegramLookup(args)
+    case "ban", "unban":
+        if !admin {
+            reply = "This command is restricted to GMs."
+            break
+        }
+        if command == "ban" {
+            reply, err = telegramBan(ctx, actor, args)
+        } else {
+            reply, err = telegramUnban(actor, args)
+        }
+    default:
+        return
+    }
+    
+    if err != nil {
+        reply = telegramError(command, err)
+    }
+    if err := bot.Enqueue(telegram.OutgoingMessage{ChatID: msg.Chat.ID, Text: reply, ParseMode: "HTML"}); err != nil {
+        log.Printf("telegram: dropped /%s reply: %v", command, err)
+    }
+}
+
+// Ожидаемые ошибки показываем как есть, остальные только в лог
+func telegramError(command string, err error) string {
+    switch {
+    case errors.Is(err, ErrAccountNotFound), errors.Is(err, ErrNotBanned), errors.Is(err, ErrProtectedAccount),
+        errors.Is(err, ErrInvalidDuration), errors.Is(err, ErrReasonRequired):
+        return html.EscapeString(err.Error())
+    }
+    log.Printf("telegram: /%s: %v", command, err)
+    return "Something went wrong, see the server log."
+}
+
+func telegramStatus(ctx context.Context) (string, error) {
+    status, err := GetServerStatus(ctx)
+    if err != nil {
+        return "", err
+    }
+    
+    lines := []string{"<b>" + html.EscapeString(config.AppConfig.Game.ServerName) + "</b>"}
+    lines = append(lines, "Authserver: "+probeText(status.AuthServer))
+    for _, realm := range status.Realms {
+        line := html.EscapeString(realm.Name) + ": " + probeText(realm.Status)
+        if online, err := CountOnlinePlayers(ctx, realm.RealmID); err == nil {
+            line += fmt.Sprintf(", %d online", online)
+        }
+        lines = append(lines, line)
+    }
+    if MaintenanceActive(time.Now()) {
+        lines = append(lines, "Maintenance in progress")
+    }
+    return strings.Join(lines, "\n"), nil
+}
+
+func probeText(p *ProbeStatus) string {
+    switch {
+    case p == nil:
+        return "unknown"
+    case p.Up:
+        return fmt.Sprintf("up (%d ms)", p.LatencyMs)
+    default:
+        return "down since " + p.Since.UTC().Format("15:04 UTC")
+    }
+}
+
+func telegramOnline(ctx context.Context) (string, error) {
+    var lines []string
+    total := 0
+    for _, realm := range database.GetRealms() {
+        online, err := CountOnlinePlayers(ctx, realm.ID)
+        if err != nil {
+            lines = append(lines, html.EscapeString(realm.Name)+": unavailable")
+            continue
+        }
+        total += online
+        lines = append(lines, fmt.Sprintf("%s: %d", html.EscapeString(realm.Name), online))
+    }
+    lines = append(lines, fmt.Sprintf("<b>Total: %d</b>", total))
+    return strings.Join(lines, "\n"), nil
+}
+
+func telegramStats(ctx context.Context) (string, error) {
+    realms := database.GetRealms()
+    if len(realms) == 0 {
+        return "No realms configured.", nil
+    }
+    stats, err := GetServerStats(ctx, realms[0].ID)
+    if err != nil {
+        return "", err
+    }
+    
+    online := 0
+    for _, realm := range realms {
+        if n, err := CountOnlinePlayers(ctx, realm.ID); err == nil {
+            online += n
+        }
+    }
+    return fmt.Sprintf("Accounts: %d\nRegistered today: %d\nOnline: %d",
+        statInt(stats["total_accounts"]), statInt(stats["today_registrations"]), online), nil
+}
+
+// После кэша в Redis числа приходят как float64
+func statInt(v interface{}) int {
+    switch n := v.(type) {
+    case int:
+        return n
+    case float64:
+        return int(n)
+    }
+    return 0
+}
+
+func telegramLookup(args []string) (string, error) {
+    if len(args) != 1 {
+        return "Usage: /lookup &lt;account&gt;", nil
+    }
+    account, err := LookupAccount(args[0])
+    if err != nil {
+        return "", err
+    }
+    
+    lines := []string{
+        fmt.Sprintf("<b>%s</b> (#%d)", html.EscapeString(account.Username), account.ID),
+        "Email: " + html.EscapeString(account.Email),
+        "Expansion: " + html.EscapeString(account.ExpansionName),
+        "Joined: " + account.JoinDate.UTC().Format("2006-01-02"),
+    }
+    if account.LastLogin != nil {
+        lines = append(lines, "Last login: "+account.LastLogin.UTC().Format("2006-01-02 15:04 UTC"))
+    }
+    if account.GMLevel > 0 {
+        lines = append(lines, fmt.Sprintf("GM level: %d", account.GMLevel))
+    }
+    if account.Locked {
+        lines = append(lines, "IP-locked")
+    }
+    if account.Ban != nil {
+        lines = append(lines, fmt.Sprintf("<b>Banned</b> %s by %s: %s", banUntilText(account.Ban),
+            html.EscapeString(account.Ban.BannedBy), html.EscapeString(account.Ban.Reason)))
+    }
+    
+    for _, c := range account.Characters {
+        line := fmt.Sprintf("• %s — %d %s %s, %s", html.EscapeString(c.Name), c.Level, c.RaceName, c.ClassName, html.EscapeString(c.RealmName))
+        if c.Online {
+            line += " (online)"
+        }
+        lines = append(lines, line)
+    }
+    return strings.Join(lines, "\n"), nil
+}
+
+func telegramBan(ctx context.Context, actor AdminActor, args []string) (string, error) {
+    if len(args) < 3 {
+        return "Usage: /ban &lt;account&gt; &lt;30m|12h|7d|perm&gt; &lt;reason&gt;", nil
+    }
+    duration, err := ParseBanDuration(args[1])
+    if err != nil {
+        return "", err
+    }
+    
+    ban, err := BanAccount(ctx, actor, args[0], duration, strings.Join(args[2:], " "))
+    if err != nil {
+        return "", err
+    }
+    return fmt.Sprintf("%s banned %s.", html.EscapeString(strings.ToUpper(args[0])), banUntilText(ban)), nil
+}
+
+func telegramUnban(actor AdminActor, args []string) (string, error) {
+    if len(args) != 1 {
+        return "Usage: /unban &lt;account&gt;", nil
+    }
+    if err := UnbanAccount(actor, args[0]); err != nil {
+        return "", err
+    }
+    return html.EscapeString(strings.ToUpper(args[0])) + " unbanned.", nil
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+)
+
+var (
+    ErrVotingDisabled   = errors.New("voting is disabled")
+    ErrUnknownVoteSite  = errors.New("unknown vote site")
+    ErrVoteNotFound     = errors.New("vote not found")
+    ErrVoteUnverified   = errors.New("postback verification failed")
+    ErrVoteAlreadyFinal = errors.New("vote was already processed")
+)
+
+// VoteSite — топ из VOTE_SITES (JSON-массив). В URL подставляются {token} и
+// {account}; топ возвращает token в postback вместе с подписью
+// hex(HMAC-SHA256(secret, token)) либо шлет его с одного из адресов IPs
+type VoteSite struct {
+    Slug          string   `json:"slug"`
+    Name          string   `json:"name"`
+    URL           string   `json:"url"`
+    Image         string   `json:"image,omitempty"`
+    CooldownHours int      `json:"cooldown_hours"`
+    Points        int      `json:"points"`
+    Secret        string   `json:"secret,omitempty"`
+    IPs           []string `json:"ips,omitempty"`
+}
+
+func (s VoteSite) Cooldown() time.Duration {
+    if s.CooldownHours <= 0 {
+        return 12 * time.Hour
+    }
+    return time.Duration(s.CooldownHours) * time.Hour
+}
+
+// VoteSiteStatus — топ для страницы голосования с учетом кулдауна
+type VoteSiteStatus struct {
+    Slug      string     `json:"slug"`
+    Name      string     `json:"name"`
+    Image     string     `json:"image,omitempty"`
+    Points    int        `json:"points"`
+    NextVote  *time.Time `json:"next_vote,omitempty"`
+    Available bool       `json:"available"`
+}
+
+// VoteSites — топы из конфига; ошибка разбора уходит в лог, голосование выключается
+func VoteSites() []VoteSite {
+    raw := strings.TrimSpace(config.AppConfig.Custom.VoteSites)
+    if !config.AppConfig.Custom.VoteSystemEnabled || raw == "" {
+        return nil
+    }
+    
+    var sites []VoteSite
+    if err := json.Unmarshal([]byte(raw), &sites); err != nil {
+        log.Printf("VOTE_SITES: %v", err)
+        return nil
+    }
+    return sites
+}
+
+func FindVoteSite(slug string) (VoteSite, error) {
+    if !config.AppConfig.Custom.VoteSystemEnabled {
+        return VoteSite{}, ErrVotingDisabled
+    }
+    for _, site := range VoteSites() {
+        if site.Slug == slug {
+            return site, nil
+        }
+    }
+    return VoteSite{}, ErrUnknownVoteSite
+}
+
+func GetVoteStatus(accountID int, ip string) ([]VoteSiteStatus, error) {
+    var statuses []VoteSiteStatus
+    for _, site := range VoteSites() {
+        status := VoteSiteStatus{Slug: site.Slug, Name: site.Name, Image: site.Image, Points: site.Points, Available: true}
+        
+        next, err := nextVote(site, accountID, ip)
+        if err != nil {
+            return nil, err
+        }
+        if time.Now().Before(next) {
+            status.NextVote = &next
+            status.Available = false
+        }
+        statuses = append(statuses, status)
+    }
+    return statuses, nil
+}
+
+func nextVote(site VoteSite, accountID int, ip string) (time.Time, error) {
+    remaining, err := database.VoteCooldown(site.Slug, accountID, ip, site.Cooldown())
+    if err != nil || remaining <= 0 {
+        return time.Time{}, err
+    }
+    return time.Now().Add(remaining), nil
+}
+
+// StartVote заводит pending-голос и возвращает адрес топа для редиректа.
+// realmID/guid — персонаж для предметной награды, может быть пустым
+func StartVote(accountID int, ip, slug string, realmID, guid int) (string, error) {
+    site, err := FindVoteSite(slug)
+    if err != nil {
+        return "", err
+    }
+    
+    next, err := nextVote(site, accountID, ip)
+    if err != nil {
+        return "", err
+    }
+    if time.Now().Before(next) {
+        return "", &CooldownError{Until: next}
+    }
+    
+    if guid > 0 {
+        owner, _, err := database.GetCharacterOwner(realmID, guid)
+        if err != nil || owner != accountID {
+            return "", ErrNotYourCharacter
+        }
+    }
+    
+    vote := &database.Vote{
+        Site:      site.Slug,
+        AccountID: accountID,
+        IP:        ip,
+        Token:     GenerateRandomString(40),
+        RealmID:   realmID,
+        GUID:      guid,
+    }
+    if err := database.CreateVote(vote); err != nil {
+        return "", err
+    }
+    
+    target := strings.NewReplacer(
+        "{token}", url.QueryEscape(vote.Token),
+        "{account}", strconv.Itoa(accountID),
+    ).Replace(site.URL)
+    return target, nil
+}
+
+// VoteSignature — подпись postback для топа с общим секретом
+func VoteSignature(secret, token string) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte(token))
+    return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifyPostback(site VoteSite, token, signature, ip string) bool {
+    if site.Secret != "" {
+        return hmac.Equal([]byte(strings.ToLower(signature)), []byte(VoteSignature(site.Secret, token)))
+    }
+    
+    remote := net.ParseIP(ip)
+    for _, allowed := range site.IPs {
+        if _, network, err := net.ParseCIDR(allowed); err == nil {
+            if remote != nil && network.Contains(remote) {
+                return true
+            }
+        } else if remote != nil && remote.Equal(net.ParseIP(allowed)) {
+            return true
+        }
+    }
+    // Топ без секрета и без списка адресов не проверить — такой голос не засчитывается
+    return false
+}
+
+// HandleVotePostback проверяет обратный вызов топа и начисляет награду. Кулдаун
+// проверяется еще раз: pending-голосов может быть несколько
+func HandleVotePostback(ctx context.Context, slug, token, signature, ip string) error {
+    site, err := FindVoteSite(slug)
+    if err != nil {
+        return err
+    }
+    if !verifyPostback(site, token, signature, ip) {
+        return ErrVoteUnverified
+    }
+    
+    vote, err := database.GetVoteByToken(site.Slug, token)
+    if err == sql.ErrNoRows {
+        return ErrVoteNotFound
+    }
+    if err != nil {
+        return err
+    }
+    if vote.Status != database.VotePending {
+        return ErrVoteAlreadyFinal
+    }
+    
+    var points *database.PointsEntry
+    if site.Points > 0 {
+        points = &database.PointsEntry{AccountID: vote.AccountID, Amount: site.Points, Kind: database.PointsVote, Reference: fmt.Sprintf("vote:%d", vote.ID)}
+    }
+    reward := config.AppConfig.Custom.VoteRewardItem != "" && vote.GUID > 0
+    remaining, ok, err := database.FinishVote(vote, site.Cooldown(), points, reward)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return ErrVoteAlreadyFinal
+    }
+    if remaining > 0 {
+        return &CooldownError{Until: time.Now().Add(remaining)}
+    }
+    
+    // Голос уже засчитан; письмо, которое не ушло сейчас, повторит очередь
+    if reward {
+        deliverVoteReward(ctx, site, vote)
+    }
+    return nil
+}
+
+const (
+    voteRewardInterval    = time.Minute
+    voteRewardBatchSize   = 50
+    voteRewardMaxAttempts = 5
+    voteRewardStaleAfter  = 10 * time.Minute
+)
+
+// StartVoteRewardDelivery повторяет письма с наградой за голос, которые не
+// удалось отправить сразу, и отдает на разбор прерванные на полпути
+func StartVoteRewardDelivery(ctx context.Context) {
+    if !config.AppConfig.Custom.VoteSystemEnabled {
+        return
+    }
+    
+    ticker := time.NewTicker(voteRewardInterval)
+    defer ticker.Stop()
+    
+    for {
+        stale, err := database.GetStaleVoteRewards(voteRewardStaleAfter, voteRewardBatchSize)
+        if err != nil {
+            log.Printf("votes: %v", err)
+        }
+        for _, v := range stale {
+            log.Printf("votes: reward for vote %d needs review: interrupted during delivery", v.ID)
+            database.SetVoteRewardState(v.ID, database.RewardReview, "interrupted during delivery")
+        }
+        
+        due, err := database.GetDueVoteRewards(voteRewardBatchSize)
+        if err != nil {
+            log.Printf("votes: %v", err)
+        }
+        for i := range due {
+            site, err := FindVoteSite(due[i].Site)
+            if err != nil {
+                // Топ убрали из VOTE_SITES, но голос на нем уже засчитан
+                site = VoteSite{Slug: due[i].Site, Name: due[i].Site}
+            }
+            deliverVoteReward(ctx, site, &due[i])
+        }
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// deliverVoteReward отправляет предмет за голос письмом персонажу, выбранному
+// при голосовании
+func deliverVoteReward(ctx context.Context, site VoteSite, vote *database.Vote) {
+    claimed, err := database.ClaimVoteReward(vote.ID)
+    if err != nil || !claimed {
+        return
+    }
+    vote.RewardAttempts++
+    
+    err = sendVoteReward(ctx, site, vote)
+    switch {
+    case err == nil:
+        database.SetVoteRewardState(vote.ID, database.RewardSent, "")
+    case errors.Is(err, ErrNotYourCharacter):
+        database.SetVoteRewardState(vote.ID, database.RewardFailed, err.Error())
+    case errors.Is(err, errVoteCommandSent):
+        // Неизвестно, дошло ли письмо: повтор может выдать предмет дважды
+        log.Printf("votes: reward for vote %d needs review: %v", vote.ID, err)
+        database.SetVoteRewardState(vote.ID, database.RewardReview, err.Error())
+    case vote.RewardAttempts >= voteRewardMaxAttempts:
+        log.Printf("votes: reward for vote %d failed: %v", vote.ID, err)
+        database.SetVoteRewardState(vote.ID, database.RewardFailed, err.Error())
+    default:
+        // 1, 2, 4, 8… минут между попытками
+        database.RetryVoteReward(vote.ID, time.Minute<<(vote.RewardAttempts-1), err.Error())
+    }
+}
+
+// errVoteCommandSent — команда ушла на worldserver, но ответа нет
+var errVoteCommandSent = errors.New("the game server did not confirm the mail")
+
+func sendVoteReward(ctx context.Context, site VoteSite, vote *database.Vote) error {
+    cfg := config.AppConfig.Custom
+    item, err := strconv.Atoi(cfg.VoteRewardItem)
+    if err != nil {
+        return fmt.Errorf("VOTE_REWARD_ITEM: %w", err)
+    }
+    
+    // Персонаж мог за это время сменить владельца или удалиться
+    owner, name, err := database.GetCharacterOwner(vote.RealmID, vote.GUID)
+    if err == sql.ErrNoRows || (err == nil && owner != vote.AccountID) {
+        return ErrNotYourCharacter
+    }
+    if err != nil {
+        return err
+    }
+    client, err := realmSOAP(vote.RealmID)
+    if err != nil {
+        return err
+    }
+    
+    count := cfg.VoteRewardCount
+    if count <= 0 {
+        count = 1
+    }
+    _, err = client.Execute(ctx, fmt.Sprintf(`send items %s "Thank you for voting" "Your reward for voting on %s." %d:%d`,
+        name, strings.ReplaceAll(site.Name, `"`, ""), item, count))
+    if err != nil && !deliveryNotAttempted(err) {
+        return fmt.Errorf("%w: %v", errVoteCommandSent, err)
+    }
+    return err
+}
@@ -0,0 +1,96 @@
+// Package votetest — фейковый топ для проверки полного цикла голосования:
+// игрок уходит на топ по ссылке из StartVote, топ вызывает наш postback
+package votetest
+
+import (
+    "fmt"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "sync"
+    "wow-registration/internal/services"
+)
+
+type TopSite struct {
+    *httptest.Server
+    
+    // PostbackURL — адрес VotePostbackHandler, например base + "/api/vote/postback/test"
+    PostbackURL string
+    Secret      string
+    
+    mu     sync.Mutex
+    tokens []string
+    errs   []error
+}
+
+// NewTopSite запускает топ, который на каждый заход с ?token= сразу шлет подписанный postback
+func NewTopSite(postbackURL, secret string) *TopSite {
+    t := &TopSite{PostbackURL: postbackURL, Secret: secret}
+    t.Server = httptest.NewServer(http.HandlerFunc(t.serve))
+    return t
+}
+
+// Site — запись для VOTE_SITES, указывающая на этот топ
+func (t *TopSite) Site(slug string, points int) services.VoteSite {
+    return services.VoteSite{
+        Slug:          slug,
+        Name:          "Test Top " + slug,
+        URL:           t.URL + "/vote?token={token}&account={account}",
+        CooldownHours: 12,
+        Points:        points,
+        Secret:        t.Secret,
+    }
+}
+
+// Tokens — токены, с которыми на топ приходили игроки
+func (t *TopSite) Tokens() []string {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return append([]string(nil), t.tokens...)
+}
+
+// Errors — неуспешные ответы нашего postback
+func (t *TopSite) Errors() []error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return append([]error(nil), t.errs...)
+}
+
+func (t *TopSite) serve(w http.ResponseWriter, r *http.Request) {
+    token := r.URL.Query().Get("token")
+    if token == "" {
+        http.Error(w, "missing token", http.StatusBadRequest)
+        return
+    }
+    
+    err := t.Postback(token)
+    
+    t.mu.Lock()
+    t.tokens = append(t.tokens, token)
+    if err != nil {
+        t.errs = append(t.errs, err)
+    }
+    t.mu.Unlock()
+    
+    fmt.Fprintln(w, "Thanks for voting!")
+}
+
+// Postback вызывает наш обработчик так, как это сделал бы настоящий топ
+func (t *TopSite) Postback(token string) error {
+    form := url.Values{
+        "token":     {token},
+        "signature": {services.VoteSignature(t.Secret, token)},
+    }
+    resp, err := http.PostForm(t.PostbackURL, form)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(resp.Body)
+        return fmt.Errorf("postback: %d %s", resp.StatusCode, body)
+    }
+    return nil
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "database/sql"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+    "wow-registration/internal/config"
+    "wow-registration/internal/database"
+    "wow-registration/internal/webhooks"
+    "github.com/google/uuid"
+)
+
+// События исходящих webhook'ов
+const (
+    WebhookAccountCreated    = "account.created"
+    WebhookAccountVerified   = "account.verified"
+    WebhookAccountBanned     = "account.banned"
+    WebhookPurchaseCompleted = "purchase.completed"
+    WebhookRealmDown         = "realm.down"
+)
+
+// WebhookEvents — события, на которые можно подписаться
+var WebhookEvents = []string{
+    WebhookAccountCreated,
+    WebhookAccountVerified,
+    WebhookAccountBanned,
+    WebhookPurchaseCompleted,
+    WebhookRealmDown,
+}
+
+const (
+    webhookInterval    = 15 * time.Second
+    webhookBatchSize   = 50
+    webhookStaleAfter  = 5 * time.Minute
+    webhookMaxBackoff  = 6 * time.Hour
+    webhookLogPageSize = 100
+)
+
+var (
+    ErrInvalidWebhook   = errors.New("invalid webhook")
+    ErrUnknownWebhook   = errors.New("unknown webhook")
+    ErrNotRedeliverable = errors.New("this delivery is still in the queue")
+)
+
+// WebhookEvent — тело запроса к получателю
+type WebhookEvent struct {
+    ID        string      `json:"id"`
+    Event     string      `json:"event"`
+    CreatedAt time.Time   `json:"created_at"`
+    Data      interface{} `json:"data"`
+}
+
+// Будит воркер, чтобы новое событие ушло сразу, а не на следующем тике
+var webhookWake = make(chan struct{}, 1)
+
+// EmitWebhook ставит событие в очередь каждого включенного webhook'а,
+// подписанного на него. Ошибки только логируются: событие не должно ломать
+// действие, которое его вызвало
+func EmitWebhook(event string, data interface{}) {
+    if !config.AppConfig.Integrations.WebhooksEnabled {
+        return
+    }
+    hooks, err := database.GetWebhooks(true)
+    if err != nil {
+        log.Printf("webhooks: %s: %v", event, err)
+        return
+    }
+    
+    payload := WebhookEvent{
+        ID:        strings.ReplaceAll(uuid.New().String(), "-", ""),
+        Event:     event,
+        CreatedAt: time.Now().UTC(),
+        Data:      data,
+    }
+    body, err := json.Marshal(payload)
+    if err != nil {
+        log.Printf("webhooks: %s: %v", event, err)
+        return
+    }
+    
+    queued := false
+    for _, hook := range hooks {
+        if !hook.Subscribed(event) {
+            continue
+        }
+        delivery := &database.WebhookDelivery{WebhookID: hook.ID, EventID: payload.ID, Event: event, Payload: string(body)}
+        if err := database.CreateWebhookDelivery(delivery); err != nil {
+            log.Printf("webhooks: %s for webhook %d: %v", event, hook.ID, err)
+            continue
+        }
+        queued = true
+    }
+    
+    if queued {
+        select {
+        case webhookWake <- struct{}{}:
+        default:
+        }
+    }
+}
+
+// StartWebhookDelivery отправляет события из очереди, повторяя неудачные
+// попытки с растущей паузой, и чистит старый журнал
+func StartWebhookDelivery(ctx context.Context) {
+    if !config.AppConfig.Integrations.WebhooksEnabled {
+        return
+    }
+    
+    cfg := config.AppConfig.Integrations
+    client := &http.Client{
+        Timeout: time.Duration(cfg.WebhookTimeout) * time.Second,
+        // Редирект увел бы подписанное событие на адрес, который никто не проверял
+        CheckRedirect: func(*http.Request, []*http.Request) error {
+            return http.ErrUseLastResponse
+        },
+    }
+    
+    ticker := time.NewTicker(webhookInterval)
+    defer ticker.Stop()
+    
+    for {
+        if n, err := database.RequeueStaleWebhookDeliveries(webhookStaleAfter); err != nil {
+            log.Printf("webhooks: %v", err)
+        } else if n > 0 {
+            log.Printf("webhooks: requeued %d interrupted deliveries", n)
+        }
+        if cfg.WebhookLogDays > 0 {
+            database.PruneWebhookDeliveries(time.Duration(cfg.WebhookLogDays) * 24 * time.Hour)
+        }
+        
+        deliveries, err := database.GetDueWebhookDeliveries(webhookBatchSize)
+        if err != nil {
+            log.Printf("webhooks: %v", err)
+        }
+        for i := range deliveries {
+            deliverWebhook(ctx, client, &deliveries[i])
+        }
+        
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        case <-webhookWake:
+        }
+    }
+}
+
+func deliverWebhook(ctx context.Context, client *http.Client, d *database.WebhookDelivery) {
+    claimed, err := database.ClaimWebhookDelivery(d.ID)
+    if err != nil || !claimed {
+        return
+    }
+    d.Attempts++
+    
+    hook, err := database.GetWebhook(d.WebhookID)
+    if err != nil {
+        // Webhook удалили, пока событие ждало в очереди
+        database.FinishWebhookDelivery(d.ID, database.DeliveryFailed, 0, "webhook no longer exists")
+        return
+    }
+    if !hook.Enabled {
+        database.FinishWebhookDelivery(d.ID, database.DeliveryFailed, 0, "webhook is disabled")
+        return
+    }
+    
+    result, err := webhooks.Send(ctx, client, webhooks.Delivery{
+        URL:        hook.URL,
+        Secret:     hook.Secret,
+        Event:      d.Event,
+        DeliveryID: fmt.Sprint(d.ID),
+        Body:       []byte(d.Payload),
+    })
+    if err == nil && result.OK() {
+        database.FinishWebhookDelivery(d.ID, database.DeliveryDone, result.Status, "")
+        return
+    }
+    
+    message := result.Body
+    if err != nil {
+        message = err.Error()
+    }
+    if d.Attempts >= config.AppConfig.Integrations.WebhookMaxAttempts {
+        log.Printf("webhooks: delivery %d to webhook %d failed after %d attempts: %s", d.ID, d.WebhookID, d.Attempts, message)
+        database.FinishWebhookDelivery(d.ID, database.DeliveryFailed, result.Status, message)
+        return
+    }
+    
+    // 30 секунд, минута, 2, 4… до webhookMaxBackoff
+    delay := webhookMaxBackoff
+    if d.Attempts < 16 {
+        if backoff := 30 * time.Second << (d.Attempts - 1); backoff < delay {
+            delay = backoff
+        }
+    }
+    if err := database.RetryWebhookDelivery(d.ID, delay, result.Status, message); err != nil {
+        log.Printf("webhooks: delivery %d: %v", d.ID, err)
+    }
+}
+
+// RedeliverWebhook ставит в очередь копию завершенной доставки с тем же
+// телом и event_id; прежняя запись остается в журнале как есть
+func RedeliverWebhook(deliveryID int64) (*database.WebhookDelivery, error) {
+    d, err := database.GetWebhookDelivery(deliveryID)
+    if err == sql.ErrNoRows {
+        return nil, ErrUnknownWebhook
+    }
+    if err != nil {
+        return nil, err
+    }
+    if d.Status != database.DeliveryDone && d.Status != database.DeliveryFailed {
+        return nil, ErrNotRedeliverable
+    }
+    
+    again := &database.WebhookDelivery{WebhookID: d.WebhookID, EventID: d.EventID, Event: d.Event, Payload: d.Payload}
+    if err := database.CreateWebhookDelivery(again); err != nil {
+        return nil, err
+    }
+    select {
+    case webhookWake <- struct{}{}:
+    default:
+    }
+    return again, nil
+}
+
+// WebhookLog — последние доставки; webhookID 0 — по всем webhook'ам
+func WebhookLog(webhookID int) ([]database.WebhookDelivery, error) {
+    return database.GetWebhookDeliveries(webhookID, webhookLogPageSize)
+}
+
+func newWebhookSecret() (string, error) {
+    buf := make([]byte, 32)
+    if _, err := rand.Read(buf); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(buf), nil
+}
+
+// ValidateWebhook нормализует и проверяет адрес и список событий
+func ValidateWebhook(w *database.Webhook) error {
+    w.URL = strings.TrimSpace(w.URL)
+    w.Description = strings.TrimSpace(w.Description)
+    
+    u, err := url.Parse(w.URL)
+    if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || len(w.URL) > 512 {
+        return fmt.Errorf("%w: the URL must be an absolute http(s) address", ErrInvalidWebhook)
+    }
+    if u.Scheme == "http" && config.AppConfig.Server.Environment == "production" {
+        return fmt.Errorf("%w: use https in production", ErrInvalidWebhook)
+    }
+    if len(w.Description) > 255 {
+        return fmt.Errorf("%w: description is too long", ErrInvalidWebhook)
+    }
+    
+    for _, event := range w.Events {
+        if !knownWebhookEvent(event) {
+            return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+        }
+    }
+    if len(w.Events) == 0 {
+        return fmt.Errorf("%w: subscribe to at least one event", ErrInvalidWebhook)
+    }
+    return nil
+}
+
+func knownWebhookEvent(event string) bool {
+    for _, e := range WebhookEvents {
+        if e == event {
+            return true
+        }
+    }
+    return false
+}
+
+// SaveWebhook создает или обновляет webhook; новому генерируется секрет
+func SaveWebhook(w *database.Webhook) error {
+    if err := ValidateWebhook(w); err != nil {
+        return err
+    }
+    if w.ID == 0 {
+        secret, err := newWebhookSecret()
+        if err != nil {
+            return err
+        }
+        w.Secret = secret
+    }
+    
+    err := database.SaveWebhook(w)
+    if err == sql.ErrNoRows {
+        return ErrUnknownWebhook
+    }
+    return err
+}
+
+// RotateWebhookSecret выдает webhook'у новый секрет
+func RotateWebhookSecret(id int) (string, error) {
+    if _, err := database.GetWebhook(id); err == sql.ErrNoRows {
+        return "", ErrUnknownWebhook
+    } else if err != nil {
+        return "", err
+    }
+    secret, err := newWebhookSecret()
+    if err != nil {
+        return "", err
+    }
+    return secret, database.SetWebhookSecret(id, secret)
+}
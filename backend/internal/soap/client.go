@@ -0,0 +1,207 @@
+// Package soap — клиент SOAP-интерфейса worldserver (executeCommand) для
+// TrinityCore, AzerothCore и CMangos. Отправляются только команды из allowlist
+package soap
+
+import (
+    "bytes"
+    "context"
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+    "unicode"
+    "wow-registration/internal/config"
+)
+
+var (
+    ErrDisabled          = errors.New("soap: remote commands are disabled")
+    ErrCommandNotAllowed = errors.New("soap: command is not allowed")
+    ErrUnauthorized      = errors.New("soap: worldserver rejected the credentials")
+)
+
+// Fault — ответ worldserver с ошибкой выполнения (нет такой команды, неверные
+// аргументы, игрок не найден и т.п.)
+type Fault struct {
+    Code    string
+    Message string
+}
+
+func (f *Fault) Error() string {
+    return "soap fault " + f.Code + ": " + f.Message
+}
+
+type Client struct {
+    Endpoint  string
+    Namespace string
+    Username  string
+    Password  string
+    // Разрешенные команды: совпадение по первым словам, "character rename"
+    // пропускает "character rename Arthas"
+    Allowlist []string
+    
+    httpClient *http.Client
+}
+
+func NewClient(endpoint, namespace, username, password string, timeout time.Duration, allowlist []string) *Client {
+    return &Client{
+        Endpoint:   endpoint,
+        Namespace:  namespace,
+        Username:   username,
+        Password:   password,
+        Allowlist:  allowlist,
+        httpClient: &http.Client{Timeout: timeout},
+    }
+}
+
+var (
+    clientsMu sync.Mutex
+    clients   = make(map[int]*Client)
+)
+
+// ForRealm — клиент worldserver реалма по настройкам SOAP_* из конфига
+func ForRealm(realmID int) (*Client, error) {
+    cfg := config.AppConfig.Integrations
+    if !cfg.SOAPEnabled {
+        return nil, ErrDisabled
+    }
+    endpoint, ok := cfg.SOAPEndpoints[realmID]
+    if !ok {
+        return nil, fmt.Errorf("soap: realm %d has no endpoint configured", realmID)
+    }
+    
+    clientsMu.Lock()
+    defer clientsMu.Unlock()
+    
+    if client, ok := clients[realmID]; ok {
+        return client, nil
+    }
+    
+    timeout := time.Duration(cfg.SOAPTimeout) * time.Second
+    if timeout <= 0 {
+        timeout = 10 * time.Second
+    }
+    client := NewClient("http://"+net.JoinHostPort(endpoint.Host, endpoint.Port)+"/", Namespace(), cfg.SOAPUser, cfg.SOAPPassword, timeout, cfg.SOAPCommands)
+    clients[realmID] = client
+    return client, nil
+}
+
+// Namespace — SOAP_URN либо пространство имен по ядру
+func Namespace() string {
+    if urn := config.AppConfig.Integrations.SOAPURN; urn != "" {
+        return urn
+    }
+    if config.AppConfig.Game.ServerCore == 5 { // CMangos
+        return "urn:MaNGOS"
+    }
+    return "urn:TC"
+}
+
+// Allowed проверяет команду по allowlist; управляющие символы запрещены всегда,
+// иначе через аргумент можно дописать вторую команду
+func (c *Client) Allowed(command string) bool {
+    for _, r := range command {
+        if unicode.IsControl(r) {
+            return false
+        }
+    }
+    
+    words := strings.Fields(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(command), ".")))
+    for _, entry := range c.Allowlist {
+        prefix := strings.Fields(strings.ToLower(entry))
+        if len(prefix) == 0 || len(prefix) > len(words) {
+            continue
+        }
+        match := true
+        for i := range prefix {
+            if words[i] != prefix[i] {
+                match = false
+                break
+            }
+        }
+        if match {
+            return true
+        }
+    }
+    return false
+}
+
+// Execute выполняет GM-команду и возвращает текст ответа worldserver
+func (c *Client) Execute(ctx context.Context, command string) (string, error) {
+    command = strings.TrimPrefix(strings.TrimSpace(command), ".")
+    if !c.Allowed(command) {
+        return "", fmt.Errorf("%w: %q", ErrCommandNotAllowed, command)
+    }
+    
+    var escaped bytes.Buffer
+    xml.EscapeText(&escaped, []byte(command))
+    body := `<?xml version="1.0" encoding="utf-8"?>` +
+        `<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ns1="` + c.Namespace + `">` +
+        `<SOAP-ENV:Body><ns1:executeCommand><command>` + escaped.String() + `</command></ns1:executeCommand></SOAP-ENV:Body>` +
+        `</SOAP-ENV:Envelope>`
+    
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, strings.NewReader(body))
+    if err != nil {
+        return "", err
+    }
+    req.SetBasicAuth(c.Username, c.Password)
+    req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+    req.Header.Set("SOAPAction", "executeCommand")
+    
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+    
+    if resp.StatusCode == http.StatusUnauthorized {
+        return "", ErrUnauthorized
+    }
+    
+    data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if err != nil {
+        return "", err
+    }
+    return parseResponse(resp.StatusCode, data)
+}
+
+type envelope struct {
+    Body struct {
+        Response *struct {
+            Result string `xml:"result"`
+        } `xml:"executeCommandResponse"`
+        Fault *struct {
+            Code   string `xml:"faultcode"`
+            String string `xml:"faultstring"`
+            Detail string `xml:"detail"`
+        } `xml:"Fault"`
+    } `xml:"Body"`
+}
+
+// Ошибки gSOAP приходят с кодом 500, поэтому тело разбирается при любом статусе
+func parseResponse(status int, data []byte) (string, error) {
+    var env envelope
+    if err := xml.Unmarshal(data, &env); err != nil {
+        if status != http.StatusOK {
+            return "", fmt.Errorf("soap: unexpected status %d", status)
+        }
+        return "", fmt.Errorf("soap: malformed response: %w", err)
+    }
+    
+    if f := env.Body.Fault; f != nil {
+        // TrinityCore кладет вывод команды в detail, а faultstring — общий текст
+        message := strings.TrimSpace(f.Detail)
+        if message == "" {
+            message = strings.TrimSpace(f.String)
+        }
+        return "", &Fault{Code: f.Code, Message: message}
+    }
+    if env.Body.Response == nil {
+        return "", fmt.Errorf("soap: unexpected status %d", status)
+    }
+    return strings.TrimSpace(env.Body.Response.Result), nil
+}
@@ -0,0 +1,67 @@
+package soap_test
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+    "wow-registration/internal/soap"
+    "wow-registration/internal/soap/soaptest"
+)
+
+func TestExecute(t *testing.T) {
+    server := soaptest.NewServer(func(command string) (string, error) {
+        if command == "revive Nobody" {
+            return "", errors.New("Player not found")
+        }
+        return "Done: " + command, nil
+    })
+    defer server.Close()
+    client := server.Client("revive", "character rename")
+    
+    result, err := client.Execute(context.Background(), ".character rename Arthas")
+    if err != nil || result != "Done: character rename Arthas" {
+        t.Fatalf("Execute: got %q, %v", result, err)
+    }
+    
+    // Ошибка команды приходит как Fault с выводом worldserver
+    var fault *soap.Fault
+    if _, err := client.Execute(context.Background(), "revive Nobody"); !errors.As(err, &fault) || fault.Message != "Player not found" {
+        t.Fatalf("Execute: got %v, want fault with the command output", err)
+    }
+    
+    // Команды вне allowlist и с переводом строки до сервера не доходят
+    for _, command := range []string{"account set gmlevel Arthas 3", "revive Arthas\nserver shutdown 1", "character"} {
+        if _, err := client.Execute(context.Background(), command); !errors.Is(err, soap.ErrCommandNotAllowed) {
+            t.Errorf("Execute(%q): got %v, want ErrCommandNotAllowed", command, err)
+        }
+    }
+    if commands := server.Commands(); len(commands) != 2 {
+        t.Fatalf("server got %d commands, want 2: %q", len(commands), commands)
+    }
+}
+
+func TestExecuteUnauthorized(t *testing.T) {
+    server := soaptest.NewServer(nil)
+    defer server.Close()
+    
+    client := soap.NewClient(server.URL+"/", "urn:TC", soaptest.Username, "wrong", time.Second, []string{"server info"})
+    if _, err := client.Execute(context.Background(), "server info"); !errors.Is(err, soap.ErrUnauthorized) {
+        t.Fatalf("Execute: got %v, want ErrUnauthorized", err)
+    }
+}
+
+func TestExecuteTimeout(t *testing.T) {
+    server := soaptest.NewServer(func(command string) (string, error) {
+        time.Sleep(200 * time.Millisecond)
+        return "", nil
+    })
+    defer server.Close()
+    
+    client := soap.NewClient(server.URL+"/", "urn:TC", soaptest.Username, soaptest.Password, 50*time.Millisecond, []string{"server info"})
+    _, err := client.Execute(context.Background(), "server info")
+    var fault *soap.Fault
+    if err == nil || errors.As(err, &fault) {
+        t.Fatalf("Execute: got %v, want a transport error", err)
+    }
+}
@@ -0,0 +1,107 @@
+// Package soaptest — локальный SOAP-сервер, отвечающий как worldserver, для
+// проверки клиента и функций, которые выполняют GM-команды
+package soaptest
+
+import (
+    "encoding/xml"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "time"
+    "wow-registration/internal/soap"
+)
+
+const (
+    Username = "soap"
+    Password = "soap"
+)
+
+// Handler отвечает на команду; ошибка превращается в SOAP Fault с ее текстом
+type Handler func(command string) (string, error)
+
+type Server struct {
+    *httptest.Server
+    
+    mu       sync.Mutex
+    handler  Handler
+    commands []string
+}
+
+// NewServer запускает фейковый worldserver. Без handler на любую команду
+// отвечает пустым успешным результатом
+func NewServer(handler Handler) *Server {
+    s := &Server{handler: handler}
+    s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+    return s
+}
+
+// Client — клиент, настроенный на этот сервер, с переданным allowlist
+func (s *Server) Client(allowlist ...string) *soap.Client {
+    return soap.NewClient(s.URL+"/", "urn:TC", Username, Password, 5*time.Second, allowlist)
+}
+
+// Commands — команды, полученные сервером, в порядке поступления
+func (s *Server) Commands() []string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]string(nil), s.commands...)
+}
+
+func (s *Server) SetHandler(handler Handler) {
+    s.mu.Lock()
+    s.handler = handler
+    s.mu.Unlock()
+}
+
+type request struct {
+    Command string `xml:"Body>executeCommand>command"`
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+    user, password, ok := r.BasicAuth()
+    if !ok || user != Username || password != Password {
+        w.WriteHeader(http.StatusUnauthorized)
+        return
+    }
+    
+    var req request
+    if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+        writeEnvelope(w, http.StatusInternalServerError, fault("SOAP-ENV:Client", "Malformed request", ""))
+        return
+    }
+    
+    s.mu.Lock()
+    s.commands = append(s.commands, req.Command)
+    handler := s.handler
+    s.mu.Unlock()
+    
+    result := ""
+    if handler != nil {
+        var err error
+        if result, err = handler(req.Command); err != nil {
+            writeEnvelope(w, http.StatusInternalServerError, fault("SOAP-ENV:Client", "Command failed", err.Error()))
+            return
+        }
+    }
+    writeEnvelope(w, http.StatusOK, "<ns1:executeCommandResponse><result>"+escape(result)+"</result></ns1:executeCommandResponse>")
+}
+
+func fault(code, message, detail string) string {
+    return "<SOAP-ENV:Fault><faultcode>" + code + "</faultcode><faultstring>" + escape(message) +
+        "</faultstring><detail>" + escape(detail) + "</detail></SOAP-ENV:Fault>"
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, body string) {
+    w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+    w.WriteHeader(status)
+    w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` +
+        `<SOAP-ENV:Envelope xmlns:SOAP-ENV="http://schemas.xmlsoap.org/soap/envelope/" xmlns:ns1="urn:TC">` +
+        `<SOAP-ENV:Body>` + body + `</SOAP-ENV:Body></SOAP-ENV:Envelope>`))
+}
+
+func escape(s string) string {
+    var b strings.Builder
+    xml.EscapeText(&b, []byte(s))
+    return b.String()
+}
@@ -0,0 +1,229 @@
+// Package telegram — минимальный клиент Bot API: long polling входящих
+// сообщений и отправка ответов из очереди с учетом 429
+package telegram
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    DefaultAPIBase = "https://api.telegram.org"
+    
+    pollTimeout   = 30 * time.Second
+    pollBackoff   = 5 * time.Second
+    maxAttempts   = 3
+    maxRetryAfter = time.Minute
+)
+
+var ErrQueueFull = errors.New("telegram: queue is full")
+
+type User struct {
+    ID       int64  `json:"id"`
+    Username string `json:"username,omitempty"`
+}
+
+type Chat struct {
+    ID   int64  `json:"id"`
+    Type string `json:"type"`
+}
+
+type Message struct {
+    MessageID int64  `json:"message_id"`
+    From      *User  `json:"from,omitempty"`
+    Chat      Chat   `json:"chat"`
+    Text      string `json:"text"`
+}
+
+type Update struct {
+    UpdateID int64    `json:"update_id"`
+    Message  *Message `json:"message,omitempty"`
+}
+
+// OutgoingMessage — параметры sendMessage; текст в HTML-разметке Telegram
+type OutgoingMessage struct {
+    ChatID    int64  `json:"chat_id"`
+    Text      string `json:"text"`
+    ParseMode string `json:"parse_mode,omitempty"`
+}
+
+type apiResponse struct {
+    OK          bool            `json:"ok"`
+    Result      json.RawMessage `json:"result"`
+    Description string          `json:"description"`
+    Parameters  struct {
+        RetryAfter int `json:"retry_after"`
+    } `json:"parameters"`
+}
+
+// APIError — ответ Bot API с ok=false
+type APIError struct {
+    Status      int
+    Description string
+    RetryAfter  time.Duration
+}
+
+func (e *APIError) Error() string {
+    return fmt.Sprintf("telegram: %d %s", e.Status, e.Description)
+}
+
+type Bot struct {
+    apiURL     string
+    queue      chan OutgoingMessage
+    httpClient *http.Client
+}
+
+// NewBot — клиент бота; apiBase позволяет направить запросы на локальный фейк
+func NewBot(token, apiBase string, queueSize int) *Bot {
+    if apiBase == "" {
+        apiBase = DefaultAPIBase
+    }
+    if queueSize <= 0 {
+        queueSize = 100
+    }
+    return &Bot{
+        apiURL:     strings.TrimRight(apiBase, "/") + "/bot" + token + "/",
+        queue:      make(chan OutgoingMessage, queueSize),
+        httpClient: &http.Client{Timeout: pollTimeout + 10*time.Second},
+    }
+}
+
+func (b *Bot) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+    body, err := json.Marshal(params)
+    if err != nil {
+        return err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.apiURL+method, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    
+    resp, err := b.httpClient.Do(req)
+    if err != nil {
+        // В ошибке net/http есть URL, а в нем токен бота
+        var urlErr *url.Error
+        if errors.As(err, &urlErr) {
+            return fmt.Errorf("telegram: %s: %w", method, urlErr.Err)
+        }
+        return err
+    }
+    defer resp.Body.Close()
+    
+    var payload apiResponse
+    if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+        return fmt.Errorf("telegram: %s: %d %w", method, resp.StatusCode, err)
+    }
+    if !payload.OK {
+        return &APIError{
+            Status:      resp.StatusCode,
+            Description: payload.Description,
+            RetryAfter:  time.Duration(payload.Parameters.RetryAfter) * time.Second,
+        }
+    }
+    if result != nil {
+        return json.Unmarshal(payload.Result, result)
+    }
+    return nil
+}
+
+// Poll получает новые сообщения long polling'ом и передает их handle, пока не
+// отменен ctx. Обработанные обновления подтверждаются смещением offset
+func (b *Bot) Poll(ctx context.Context, handle func(Update)) {
+    var offset int64
+    for {
+        var updates []Update
+        params := map[string]interface{}{
+            "offset":          offset,
+            "timeout":         int(pollTimeout / time.Second),
+            "allowed_updates": []string{"message"},
+        }
+        err := b.call(ctx, "getUpdates", params, &updates)
+        if ctx.Err() != nil {
+            return
+        }
+        if err != nil {
+            log.Print(err)
+            select {
+            case <-ctx.Done():
+                return
+            case <-time.After(pollBackoff):
+            }
+            continue
+        }
+        
+        for _, update := range updates {
+            offset = update.UpdateID + 1
+            handle(update)
+        }
+    }
+}
+
+// Enqueue ставит сообщение в очередь отправки без ожидания
+func (b *Bot) Enqueue(msg OutgoingMessage) error {
+    select {
+    case b.queue <- msg:
+        return nil
+    default:
+        return ErrQueueFull
+    }
+}
+
+// RunSender отправляет сообщения из очереди, пока не отменен ctx
+func (b *Bot) RunSender(ctx context.Context) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case msg := <-b.queue:
+            if err := b.send(ctx, msg); err != nil {
+                log.Print(err)
+            }
+        }
+    }
+}
+
+func (b *Bot) send(ctx context.Context, msg OutgoingMessage) error {
+    for attempt := 1; ; attempt++ {
+        err := b.call(ctx, "sendMessage", msg, nil)
+        
+        var apiErr *APIError
+        if err == nil || !errors.As(err, &apiErr) || apiErr.RetryAfter == 0 || attempt == maxAttempts {
+            return err
+        }
+        
+        wait := apiErr.RetryAfter
+        if wait > maxRetryAfter {
+            wait = maxRetryAfter
+        }
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case <-time.After(wait):
+        }
+    }
+}
+
+// Command разбирает "/ban@MyBot name 7d reason" в ("ban", ["name", "7d", "reason"])
+func Command(text string) (string, []string) {
+    fields := strings.Fields(text)
+    if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+        return "", nil
+    }
+    name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+    return strings.ToLower(name), fields[1:]
+}
+
+// ChatIDString — для логов
+func ChatIDString(id int64) string {
+    return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,63 @@
+package telegram_test
+
+import (
+    "context"
+    "reflect"
+    "sync"
+    "testing"
+    "time"
+    "wow-registration/internal/telegram"
+    "wow-registration/internal/telegram/telegramtest"
+)
+
+// Бот получает сообщения long polling'ом по одному разу и отвечает через
+// очередь, выдерживая 429
+func TestBotPollAndReply(t *testing.T) {
+    server := telegramtest.NewServer()
+    defer server.Close()
+    server.RateLimit(1, 1)
+    
+    bot := telegram.NewBot(server.Token, server.URL, 10)
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    
+    var mu sync.Mutex
+    var received []string
+    go bot.RunSender(ctx)
+    go bot.Poll(ctx, func(u telegram.Update) {
+        mu.Lock()
+        received = append(received, u.Message.Text)
+        mu.Unlock()
+        if name, _ := telegram.Command(u.Message.Text); name != "" {
+            bot.Enqueue(telegram.OutgoingMessage{ChatID: u.Message.Chat.ID, Text: "pong: " + name})
+        }
+    })
+    
+    server.Send(42, 7, "private", "/status@TestBot")
+    server.Send(42, 7, "private", "hello")
+    
+    deadline := time.Now().Add(5 * time.Second)
+    for len(server.Sent()) == 0 && time.Now().Before(deadline) {
+        time.Sleep(20 * time.Millisecond)
+    }
+    sent := server.Sent()
+    if len(sent) != 1 || sent[0].ChatID != 42 || sent[0].Text != "pong: status" {
+        t.Fatalf("sent: %+v", sent)
+    }
+    
+    mu.Lock()
+    defer mu.Unlock()
+    if !reflect.DeepEqual(received, []string{"/status@TestBot", "hello"}) {
+        t.Fatalf("received: %q", received)
+    }
+}
+
+func TestCommand(t *testing.T) {
+    name, args := telegram.Command("/Ban@TestBot Arthas 7d gold selling")
+    if name != "ban" || !reflect.DeepEqual(args, []string{"Arthas", "7d", "gold", "selling"}) {
+        t.Fatalf("Command: %q %q", name, args)
+    }
+    if name, _ := telegram.Command("ban Arthas"); name != "" {
+        t.Fatalf("Command without slash: %q", name)
+    }
+}
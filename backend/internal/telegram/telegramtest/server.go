@@ -0,0 +1,152 @@
+// Package telegramtest — фейковый Bot API для проверки бота: отдает
+// подготовленные сообщения через getUpdates и запоминает ответы sendMessage
+package telegramtest
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "time"
+    "wow-registration/internal/telegram"
+)
+
+// Сколько getUpdates ждет новых сообщений; меньше настоящего, чтобы проверки не тянулись
+const pollWait = time.Second
+
+type Server struct {
+    *httptest.Server
+    Token string
+    
+    mu          sync.Mutex
+    updates     []telegram.Update
+    nextID      int64
+    sent        []telegram.OutgoingMessage
+    rateLimited int
+    retryAfter  int
+    pushed      chan struct{}
+}
+
+func NewServer() *Server {
+    s := &Server{Token: "123:test-token", nextID: 1, pushed: make(chan struct{}, 1)}
+    s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+    return s
+}
+
+// Send имитирует сообщение пользователя userID в чат chatID
+func (s *Server) Send(chatID, userID int64, chatType, text string) {
+    s.mu.Lock()
+    s.updates = append(s.updates, telegram.Update{
+        UpdateID: s.nextID,
+        Message: &telegram.Message{
+            MessageID: s.nextID,
+            From:      &telegram.User{ID: userID},
+            Chat:      telegram.Chat{ID: chatID, Type: chatType},
+            Text:      text,
+        },
+    })
+    s.nextID++
+    s.mu.Unlock()
+    
+    select {
+    case s.pushed <- struct{}{}:
+    default:
+    }
+}
+
+// RateLimit — следующие n вызовов sendMessage получат 429 с retry_after
+func (s *Server) RateLimit(n, retryAfter int) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.rateLimited, s.retryAfter = n, retryAfter
+}
+
+// Sent — отправленные ботом сообщения по порядку
+func (s *Server) Sent() []telegram.OutgoingMessage {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]telegram.OutgoingMessage(nil), s.sent...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    
+    prefix := "/bot" + s.Token + "/"
+    if !strings.HasPrefix(r.URL.Path, prefix) {
+        w.WriteHeader(http.StatusUnauthorized)
+        fmt.Fprint(w, `{"ok": false, "error_code": 401, "description": "Unauthorized"}`)
+        return
+    }
+    
+    switch strings.TrimPrefix(r.URL.Path, prefix) {
+    case "getUpdates":
+        s.getUpdates(w, r)
+    case "sendMessage":
+        s.sendMessage(w, r)
+    default:
+        w.WriteHeader(http.StatusNotFound)
+        fmt.Fprint(w, `{"ok": false, "error_code": 404, "description": "Not Found"}`)
+    }
+}
+
+func (s *Server) getUpdates(w http.ResponseWriter, r *http.Request) {
+    var params struct {
+        Offset int64 `json:"offset"`
+    }
+    json.NewDecoder(r.Body).Decode(&params)
+    
+    deadline := time.After(pollWait)
+    for {
+        s.mu.Lock()
+        // Как настоящий API: offset подтверждает все, что до него
+        kept := s.updates[:0]
+        for _, u := range s.updates {
+            if u.UpdateID >= params.Offset {
+                kept = append(kept, u)
+            }
+        }
+        s.updates = kept
+        pending := append([]telegram.Update(nil), s.updates...)
+        s.mu.Unlock()
+        
+        if len(pending) > 0 {
+            writeResult(w, pending)
+            return
+        }
+        select {
+        case <-s.pushed:
+        case <-deadline:
+            writeResult(w, []telegram.Update{})
+            return
+        case <-r.Context().Done():
+            return
+        }
+    }
+}
+
+func (s *Server) sendMessage(w http.ResponseWriter, r *http.Request) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    
+    if s.rateLimited > 0 {
+        s.rateLimited--
+        w.WriteHeader(http.StatusTooManyRequests)
+        fmt.Fprintf(w, `{"ok": false, "error_code": 429, "description": "Too Many Requests: retry after %d", "parameters": {"retry_after": %d}}`, s.retryAfter, s.retryAfter)
+        return
+    }
+    
+    var msg telegram.OutgoingMessage
+    if err := json.NewDecoder(r.Body).Decode(&msg); err != nil || msg.ChatID == 0 || msg.Text == "" {
+        w.WriteHeader(http.StatusBadRequest)
+        fmt.Fprint(w, `{"ok": false, "error_code": 400, "description": "Bad Request: message text is empty"}`)
+        return
+    }
+    s.sent = append(s.sent, msg)
+    writeResult(w, map[string]interface{}{"message_id": len(s.sent), "chat": map[string]int64{"id": msg.ChatID}})
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+    json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+}
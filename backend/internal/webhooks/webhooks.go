@@ -0,0 +1,118 @@
+// Package webhooks — подпись и отправка исходящих webhook'ов. Получатель
+// проверяет подпись функцией Verify или по описанию формата у SignatureHeader
+package webhooks
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Заголовки запроса. Подпись: "t=<unix>,v1=<hex>", где hex —
+// HMAC-SHA256(secret, "<unix>.<тело>"). Метка времени в подписи не дает
+// повторить перехваченный запрос позже
+const (
+    SignatureHeader = "X-Webhook-Signature"
+    EventHeader     = "X-Webhook-Event"
+    DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var (
+    ErrBadSignature = errors.New("webhooks: invalid signature")
+    ErrExpired      = errors.New("webhooks: signature timestamp is too old")
+)
+
+func signature(secret string, timestamp int64, body []byte) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+    mac.Write([]byte("."))
+    mac.Write(body)
+    return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign — значение SignatureHeader для тела body, отправленного в момент t
+func Sign(secret string, t time.Time, body []byte) string {
+    return fmt.Sprintf("t=%d,v1=%s", t.Unix(), signature(secret, t.Unix(), body))
+}
+
+// Verify проверяет подпись и что она не старше tolerance
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+    var timestamp int64
+    var signatures []string
+    for _, part := range strings.Split(header, ",") {
+        key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+        switch key {
+        case "t":
+            timestamp, _ = strconv.ParseInt(value, 10, 64)
+        case "v1":
+            signatures = append(signatures, value)
+        }
+    }
+    if timestamp == 0 || len(signatures) == 0 {
+        return ErrBadSignature
+    }
+    if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+        return ErrExpired
+    }
+    
+    expected := []byte(signature(secret, timestamp, body))
+    for _, s := range signatures {
+        if hmac.Equal([]byte(s), expected) {
+            return nil
+        }
+    }
+    return ErrBadSignature
+}
+
+// Delivery — одна попытка отправки
+type Delivery struct {
+    URL        string
+    Secret     string
+    Event      string
+    DeliveryID string
+    Body       []byte
+}
+
+// Result — ответ получателя; Status 0 — ответа не было
+type Result struct {
+    Status int
+    Body   string
+}
+
+// OK — получатель принял событие (любой 2xx)
+func (r Result) OK() bool {
+    return r.Status >= 200 && r.Status < 300
+}
+
+// Send отправляет событие POST-запросом. Ошибка — только если ответа не было;
+// код ответа вызывающий проверяет сам
+func Send(ctx context.Context, client *http.Client, d Delivery) (Result, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+    if err != nil {
+        return Result{}, err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("User-Agent", "wow-registration-webhooks/1.0")
+    req.Header.Set(EventHeader, d.Event)
+    req.Header.Set(DeliveryHeader, d.DeliveryID)
+    req.Header.Set(SignatureHeader, Sign(d.Secret, time.Now(), d.Body))
+    
+    resp, err := client.Do(req)
+    if err != nil {
+        return Result{}, err
+    }
+    defer resp.Body.Close()
+    
+    // Для журнала хватит начала ответа
+    body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+    return Result{Status: resp.StatusCode, Body: string(body)}, nil
+}
@@ -0,0 +1,60 @@
+package webhooks_test
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "testing"
+    "time"
+    "wow-registration/internal/webhooks"
+    "wow-registration/internal/webhooks/webhookstest"
+)
+
+func TestSend(t *testing.T) {
+    receiver := webhookstest.NewReceiver("hook-secret")
+    defer receiver.Close()
+    receiver.Fail(1)
+    
+    delivery := webhooks.Delivery{
+        URL:        receiver.URL,
+        Secret:     "hook-secret",
+        Event:      "account.created",
+        DeliveryID: "d-1",
+        Body:       []byte(`{"account_id":1}`),
+    }
+    
+    // Первая попытка получает 500 — это ответ, а не ошибка отправки
+    result, err := webhooks.Send(context.Background(), http.DefaultClient, delivery)
+    if err != nil || result.OK() || result.Status != http.StatusInternalServerError {
+        t.Fatalf("first attempt: %+v, %v", result, err)
+    }
+    
+    result, err = webhooks.Send(context.Background(), http.DefaultClient, delivery)
+    if err != nil || !result.OK() {
+        t.Fatalf("retry: %+v, %v", result, err)
+    }
+    requests := receiver.Requests()
+    if len(requests) != 1 || requests[0].Event != "account.created" || requests[0].DeliveryID != "d-1" || string(requests[0].Body) != `{"account_id":1}` {
+        t.Fatalf("requests: %+v", requests)
+    }
+    
+    // Чужой секрет получатель отвергает
+    delivery.Secret = "other-secret"
+    if result, _ := webhooks.Send(context.Background(), http.DefaultClient, delivery); result.OK() || receiver.Rejected() != 1 {
+        t.Fatalf("forged delivery: %+v, rejected %d", result, receiver.Rejected())
+    }
+}
+
+func TestVerify(t *testing.T) {
+    body := []byte(`{"ok":true}`)
+    
+    if err := webhooks.Verify("s", webhooks.Sign("s", time.Now(), body), body, time.Minute); err != nil {
+        t.Fatalf("fresh signature: %v", err)
+    }
+    if err := webhooks.Verify("s", webhooks.Sign("s", time.Now().Add(-time.Hour), body), body, time.Minute); !errors.Is(err, webhooks.ErrExpired) {
+        t.Fatalf("old signature: got %v, want ErrExpired", err)
+    }
+    if err := webhooks.Verify("s", webhooks.Sign("s", time.Now(), body), []byte(`{"ok":false}`), time.Minute); !errors.Is(err, webhooks.ErrBadSignature) {
+        t.Fatalf("changed body: got %v, want ErrBadSignature", err)
+    }
+}
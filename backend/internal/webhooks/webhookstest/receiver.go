@@ -0,0 +1,81 @@
+// Package webhookstest — фейковый получатель webhook'ов: проверяет подпись,
+// запоминает принятые события и умеет отвечать ошибкой, чтобы проверить повторы
+package webhookstest
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "time"
+    "wow-registration/internal/webhooks"
+)
+
+// Request — принятое событие
+type Request struct {
+    Event      string
+    DeliveryID string
+    Body       []byte
+}
+
+type Receiver struct {
+    *httptest.Server
+    Secret string
+    
+    mu       sync.Mutex
+    requests []Request
+    failures int
+    rejected int
+}
+
+func NewReceiver(secret string) *Receiver {
+    r := &Receiver{Secret: secret}
+    r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+    return r
+}
+
+// Fail — следующие n запросов получат 500
+func (r *Receiver) Fail(n int) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.failures = n
+}
+
+// Requests — принятые события с верной подписью по порядку
+func (r *Receiver) Requests() []Request {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return append([]Request(nil), r.requests...)
+}
+
+// Rejected — сколько запросов пришло с неверной подписью
+func (r *Receiver) Rejected() int {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return r.rejected
+}
+
+func (r *Receiver) serve(w http.ResponseWriter, req *http.Request) {
+    body, _ := io.ReadAll(req.Body)
+    
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    
+    if err := webhooks.Verify(r.Secret, req.Header.Get(webhooks.SignatureHeader), body, 5*time.Minute); err != nil {
+        r.rejected++
+        http.Error(w, err.Error(), http.StatusUnauthorized)
+        return
+    }
+    if r.failures > 0 {
+        r.failures--
+        http.Error(w, "temporary failure", http.StatusInternalServerError)
+        return
+    }
+    
+    r.requests = append(r.requests, Request{
+        Event:      req.Header.Get(webhooks.EventHeader),
+        DeliveryID: req.Header.Get(webhooks.DeliveryHeader),
+        Body:       body,
+    })
+    w.WriteHeader(http.StatusNoContent)
+}